@@ -1,8 +1,228 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"go.uber.org/zap"
+
+	"asic-control/internal/automation"
+	"asic-control/internal/bus/natsjs"
+	"asic-control/internal/config"
+	"asic-control/internal/events"
+	"asic-control/internal/logging"
+	"asic-control/internal/settings"
+)
+
+// automation is the rules service: device.state_updated -> settings.rules -> alert.raised
+// (+ optional command.request, executed and audited by core). Rules are edited through the
+// core API (/api/rules) and re-read from the shared data/ dir. Run one instance.
 func main() {
-	fmt.Println("asic-control: automation")
+	log, err := logging.New(logging.Config{Level: envStr("LOG_LEVEL", "info")})
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = log.Sync() }()
+
+	dataDir := envStr("MONA_DATA_DIR", "data")
+	cfgStore, err := settings.Open(dataDir)
+	if err != nil {
+		log.Fatal("settings open", zap.Error(err))
+	}
+	schema, err := events.LoadSchema()
+	if err != nil {
+		log.Fatal("load proto schema", zap.Error(err))
+	}
+
+	acfg := config.Automation{
+		ConsumerName: envStr("AUTOMATION_CONSUMER", "automation"),
+		Tick:         envDur("AUTOMATION_TICK", 15*time.Second),
+	}
+
+	rootCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	engine := automation.NewEngine()
+	guard := automation.NewGuard()
+	loadRules := func() {
+		var rs []automation.Rule
+		for _, r := range cfgStore.Get().Rules {
+			cr, err := automation.Compile(r)
+			if err != nil {
+				log.Warn("rule skipped", zap.String("rule", r.ID), zap.String("name", r.Name), zap.Error(err))
+				continue
+			}
+			rs = append(rs, cr)
+		}
+		engine.SetRules(rs)
+	}
+	loadRules()
+
+	// rules are edited in core: re-read settings.json periodically
+	go func() {
+		t := time.NewTicker(30 * time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-t.C:
+				if err := cfgStore.Reload(); err != nil {
+					log.Warn("settings reload", zap.Error(err))
+					continue
+				}
+				loadRules()
+			}
+		}
+	}()
+
+	log.Info("automation starting",
+		zap.String("consumer", acfg.ConsumerName),
+		zap.Int("rules", len(cfgStore.Get().Rules)),
+	)
+
+	for rootCtx.Err() == nil {
+		cfg := cfgStore.Get()
+		c, err := natsjs.Connect(natsjs.Config{
+			URL:     envStr("NATS_URL", cfg.NATSURL),
+			Prefix:  envStr("NATS_PREFIX", cfg.NATSPrefix),
+			Timeout: 2 * time.Second,
+		})
+		if err == nil {
+			err = c.EnsureStreams()
+		}
+		if err != nil {
+			if c != nil {
+				_ = c.Close()
+			}
+			log.Warn("nats connect", zap.Error(err))
+			select {
+			case <-rootCtx.Done():
+			case <-time.After(2 * time.Second):
+			}
+			continue
+		}
+		log.Info("nats connected")
+
+		if err := run(rootCtx, c, schema, engine, guard, cfgStore.Get, acfg, log); err != nil {
+			log.Warn("rules consumer stopped", zap.Error(err))
+			select {
+			case <-rootCtx.Done():
+			case <-time.After(2 * time.Second):
+			}
+		}
+		_ = c.Close()
+	}
+	log.Info("automation stopped")
+}
+
+// run feeds device.state_updated to the engine. Hold times are checked on a tick, but only
+// once the consumer has caught up, so a backlog replays at event time first. Reboots go
+// through the remediation guard (settings.remediation), also on the tick.
+func run(ctx context.Context, c *natsjs.Client, schema *events.Schema, engine *automation.Engine, guard *automation.Guard, cfg func() settings.Settings, acfg config.Automation, log *zap.Logger) error {
+	const batch = 256
+	consumer, err := c.NewPullConsumer(acfg.ConsumerName, events.DeviceStateUpdated, 4096)
+	if err != nil {
+		return err
+	}
+	publish := func(subject, ip string, b []byte, err error) {
+		if err == nil {
+			err = c.Publish(ctx, subject, b)
+		}
+		if err != nil {
+			log.Warn("publish", zap.String("subject", subject), zap.String("ip", ip), zap.Error(err))
+		}
+	}
+	escalate := func(e automation.Escalation) {
+		log.Warn("remediation", zap.String("rule", e.Rule.ID), zap.String("ip", e.State.IP),
+			zap.Bool("exhausted", e.Firing), zap.Int("reboots_1h", e.Used))
+		b, err := automation.EncodeEscalation(schema, e)
+		publish(events.AlertRaised, e.State.IP, b, err)
+	}
+	command := func(t automation.Transition) {
+		b, err := automation.EncodeCommand(schema, t)
+		publish(events.CommandRequest, t.State.IP, b, err)
+	}
+	emit := func(ts []automation.Transition) {
+		for _, t := range ts {
+			log.Info("rule",
+				zap.String("rule", t.Rule.ID), zap.String("name", t.Rule.Name),
+				zap.String("ip", t.State.IP), zap.Bool("firing", t.Firing))
+			b, err := automation.EncodeAlert(schema, t)
+			publish(events.AlertRaised, t.State.IP, b, err)
+			switch t.Rule.Command {
+			case "":
+			case automation.CommandReboot:
+				if e, ok := guard.Submit(t); ok {
+					escalate(e)
+				}
+			default:
+				if t.Firing {
+					command(t)
+				}
+			}
+		}
+	}
+	// rearm hands finished reboots back to the engine: a device that still fails fires again
+	// and gets the next reboot or, out of budget, the escalation
+	rearm := func(now time.Time) {
+		for _, t := range guard.Finished() {
+			engine.Rearm(t, now)
+		}
+	}
+	remediate := func(now time.Time) {
+		s := cfg()
+		reboot, esc := guard.Due(s.Remediation, s.Subnets, now)
+		for _, e := range esc {
+			escalate(e)
+		}
+		for _, t := range reboot {
+			log.Info("remediation reboot", zap.String("rule", t.Rule.ID), zap.String("ip", t.State.IP))
+			command(t)
+		}
+	}
+
+	lastTick := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		msgs, err := consumer.Fetch(ctx, batch, 2*time.Second)
+		for _, m := range msgs {
+			s, err := automation.DecodeState(schema, m.Data())
+			if err != nil {
+				_ = m.Term()
+				continue
+			}
+			guard.Observe(s)
+			rearm(s.At)
+			emit(engine.Observe(s))
+			_ = m.Ack()
+		}
+		if (err != nil || len(msgs) < batch) && time.Since(lastTick) >= acfg.Tick {
+			lastTick = time.Now()
+			emit(engine.Tick(lastTick.UTC()))
+			remediate(lastTick)
+			rearm(lastTick.UTC())
+		}
+	}
+}
+
+func envStr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
 }
 
+func envDur(key string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
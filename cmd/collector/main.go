@@ -1,10 +1,150 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"asic-control/internal/bus/natsjs"
+	_ "asic-control/internal/collectors/drivers"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/config"
+	"asic-control/internal/events"
+	"asic-control/internal/logging"
+	"asic-control/internal/secrets"
+	"asic-control/internal/settings"
 )
 
+// collector is a stateless polling worker: poll.request -> vendor driver -> poll.result
+// (core turns those into device.state_updated). Run as many as needed next to core (same
+// data/ dir for credentials).
 func main() {
-	fmt.Println("collector is not used in the single-program mode (core includes discovery UI).")
+	log, err := logging.New(logging.Config{Level: envStr("LOG_LEVEL", "info")})
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = log.Sync() }()
+
+	dataDir := envStr("MONA_DATA_DIR", "data")
+	cfgStore, err := settings.Open(dataDir)
+	if err != nil {
+		log.Fatal("settings open", zap.Error(err))
+	}
+	sec, err := secrets.Open(dataDir)
+	if err != nil {
+		log.Fatal("secrets open", zap.Error(err))
+	}
+	schema, err := events.LoadSchema()
+	if err != nil {
+		log.Fatal("load proto schema", zap.Error(err))
+	}
+
+	ccfg := config.Collector{
+		ShardID:        envStr("COLLECTOR_SHARD_ID", "shard-1"),
+		Concurrency:    envInt("COLLECTOR_CONCURRENCY", 256),
+		PollTimeout:    envDur("COLLECTOR_POLL_TIMEOUT", 5*time.Second),
+		ConsumerName:   envStr("COLLECTOR_CONSUMER", "collector"),
+		QueueBatchSize: envInt("COLLECTOR_BATCH", 64),
+	}
+
+	rootCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// credentials are edited in core UI: re-read settings.json periodically
+	go func() {
+		t := time.NewTicker(30 * time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-t.C:
+				if err := cfgStore.Reload(); err != nil {
+					log.Warn("settings reload", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	// vendor drivers come from the registry (see internal/collectors/drivers)
+	dispatcher := sdk.NewDispatcher()
+	log.Info("collector starting",
+		zap.String("shard", ccfg.ShardID),
+		zap.Int("concurrency", ccfg.Concurrency),
+		zap.String("consumer", ccfg.ConsumerName),
+	)
+
+	for rootCtx.Err() == nil {
+		cfg := cfgStore.Get()
+		c, err := natsjs.Connect(natsjs.Config{
+			URL:     envStr("NATS_URL", cfg.NATSURL),
+			Prefix:  envStr("NATS_PREFIX", cfg.NATSPrefix),
+			Timeout: 2 * time.Second,
+		})
+		if err == nil {
+			err = c.EnsureStreams()
+		}
+		if err != nil {
+			if c != nil {
+				_ = c.Close()
+			}
+			log.Warn("nats connect", zap.Error(err))
+			select {
+			case <-rootCtx.Done():
+			case <-time.After(2 * time.Second):
+			}
+			continue
+		}
+		log.Info("nats connected")
+
+		w := &sdk.Worker{
+			Bus:        c,
+			Schema:     schema,
+			Dispatcher: dispatcher,
+			Creds: func(t sdk.Target) []sdk.Cred {
+				return sdk.BuildCreds(cfgStore.Get(), sec, t.Vendor, t.Firmware)
+			},
+			Durable:     ccfg.ConsumerName,
+			ShardID:     ccfg.ShardID,
+			Concurrency: ccfg.Concurrency,
+			Batch:       ccfg.QueueBatchSize,
+			PollTimeout: ccfg.PollTimeout,
+			Log:         log,
+		}
+		if err := w.Run(rootCtx); err != nil {
+			log.Warn("worker stopped", zap.Error(err))
+			select {
+			case <-rootCtx.Done():
+			case <-time.After(2 * time.Second):
+			}
+		}
+		_ = c.Close()
+	}
+	log.Info("collector stopped")
+}
+
+func envStr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
 }
 
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+func envDur(key string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
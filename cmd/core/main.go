@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,22 +23,30 @@ import (
 	"github.com/jhump/protoreflect/dynamic"
 	"go.uber.org/zap"
 
-	"asic-control/internal/antminer/httpapi"
+	"asic-control/internal/automation"
+	"asic-control/internal/bus"
 	"asic-control/internal/bus/embeddednats"
 	"asic-control/internal/bus/natsjs"
+	_ "asic-control/internal/collectors/drivers"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/core/alerts"
+	"asic-control/internal/core/commands"
+	"asic-control/internal/core/poolguard"
+	"asic-control/internal/core/reboots"
 	"asic-control/internal/core/registry"
+	"asic-control/internal/core/statechange"
 	"asic-control/internal/core/webui"
 	"asic-control/internal/defaultcreds"
 	"asic-control/internal/discovery/scanner"
 	"asic-control/internal/discovery/subnets"
 	"asic-control/internal/events"
 	"asic-control/internal/logging"
+	"asic-control/internal/minerlog"
 	"asic-control/internal/modelnorm"
 	"asic-control/internal/netutil"
 	"asic-control/internal/secrets"
 	"asic-control/internal/settings"
-	whhttp "asic-control/internal/whatsminer/httpapi"
-	vnishhttp "asic-control/internal/vnish/httpapi"
+	"asic-control/internal/syslogd"
 	"asic-control/internal/version"
 )
 
@@ -107,9 +116,178 @@ func main() {
 		log.Fatal("load proto schema", zap.Error(err))
 	}
 
-	store := registry.NewStore()
+	// Device registry: restored from data/registry (snapshot + WAL) so restarts don't wipe the fleet.
+	regBackend, err := registry.OpenFileBackend("data/registry")
+	if err != nil {
+		log.Fatal("registry backend open", zap.Error(err))
+	}
+	store, err := registry.Open(regBackend)
+	if err != nil {
+		log.Fatal("registry restore", zap.Error(err))
+	}
+	log.Info("registry restored", zap.Int("devices", len(store.List())))
+
+	// Command audit (who did what to which device): data/commands/audit.jsonl
+	cmdAudit, err := commands.OpenAudit("data/commands")
+	if err != nil {
+		log.Fatal("command audit open", zap.Error(err))
+	}
+	// Reboot history (uptime resets and commanded reboots): data/reboots/reboots.jsonl
+	rebootLog, err := reboots.Open("data/reboots")
+	if err != nil {
+		log.Fatal("reboot log open", zap.Error(err))
+	}
+	// Alert store (deduplicated per device+code, ack/resolve, silences): data/alerts/alerts.jsonl
+	alertStore, err := alerts.Open("data/alerts")
+	if err != nil {
+		log.Fatal("alert store open", zap.Error(err))
+	}
+	setRebootCounts := func(dd *registry.Device, now time.Time) {
+		h1, h24, last := rebootLog.Counts(dd.IP, now)
+		dd.Reboots1h, dd.Reboots24h, dd.LastRebootAt = h1, h24, last.At
+	}
 	subnetsStore := subnets.NewStore()
 
+	// NATS is optional at runtime: core must start even if NATS is down.
+	var natsMu sync.RWMutex
+	var natsClient *natsjs.Client
+	var natsConnected atomic.Bool
+	var natsLastErr atomic.Value // string
+
+	// raiseAlert records the alert in the store and publishes alert.raised (best-effort:
+	// NATS may be down). The alert consumer skips these (shard_id "core").
+	raiseAlert := func(ip, severity, code, message string, tags map[string]string) {
+		log.Warn("alert", zap.String("ip", ip), zap.String("severity", severity), zap.String("code", code), zap.String("message", message))
+		deviceID := ""
+		if d, ok := store.Get(ip); ok {
+			deviceID = d.MAC
+		}
+		alertStore.Raise(alerts.Raised{IP: ip, DeviceID: deviceID, Severity: severity, Code: code, Message: message, Tags: tags, Source: "core"})
+		natsMu.RLock()
+		c := natsClient
+		natsMu.RUnlock()
+		if !natsConnected.Load() || c == nil {
+			return
+		}
+		envMsg := schema.NewEnvelope(events.AlertRaised)
+		envMsg.SetFieldByName("shard_id", "core")
+		envMsg.SetFieldByName("ip", ip)
+		envMsg.SetFieldByName("device_id", deviceID)
+		ar := dynamic.NewMessage(schema.AlertRaised)
+		ar.SetFieldByName("device_id", deviceID)
+		ar.SetFieldByName("severity", severity)
+		ar.SetFieldByName("code", code)
+		ar.SetFieldByName("message", message)
+		if len(tags) > 0 {
+			ar.SetFieldByName("tags", tags)
+		}
+		envMsg.SetFieldByName("alert_raised", ar)
+		if b, err := events.Marshal(envMsg); err == nil {
+			_ = c.Publish(rootCtx, events.AlertRaised, b)
+		}
+	}
+
+	// Pool guard: polled pool lists are checked against settings.pool_policies; findings are
+	// handled (alert, optional restore) by the consumer next to /api/pools/apply.
+	type poolEvent struct {
+		f     poolguard.Finding
+		raise bool
+	}
+	poolGuard := poolguard.NewGuard()
+	poolEvents := make(chan poolEvent, 1024)
+
+	// Syslog receiver (settings.syslog): lines from registry devices go to a per-device ring
+	// (GET /api/devices/{ip}/logs), other senders are dropped. Failure signatures and
+	// err-or-worse lines are published as device.log, at most once a minute per device and reason.
+	syslogBuf := syslogd.NewBuffer(cfg.Syslog.MaxLines)
+	var syslogDropped atomic.Uint64
+	var notableMu sync.Mutex
+	notableLast := map[string]time.Time{}
+	onSyslog := func(m syslogd.Message) {
+		d, ok := store.Get(m.IP)
+		if !ok {
+			syslogDropped.Add(1)
+			return
+		}
+		syslogBuf.Add(m)
+		match := ""
+		if rule, ok := minerlog.Match(m.Text); ok {
+			match = rule.Code
+		} else if m.Severity <= 3 {
+			match = syslogd.SeverityName(m.Severity)
+		}
+		if match == "" {
+			return
+		}
+		key := m.IP + "|" + match
+		notableMu.Lock()
+		due := m.Received.Sub(notableLast[key]) >= time.Minute
+		if due {
+			notableLast[key] = m.Received
+		}
+		notableMu.Unlock()
+		natsMu.RLock()
+		c := natsClient
+		natsMu.RUnlock()
+		if !due || !natsConnected.Load() || c == nil {
+			return
+		}
+		ts := m.Time
+		if ts.IsZero() {
+			ts = m.Received
+		}
+		envMsg := schema.NewEnvelope(events.DeviceLog)
+		envMsg.SetFieldByName("ip", m.IP)
+		envMsg.SetFieldByName("mac", d.MAC)
+		envMsg.SetFieldByName("device_id", d.MAC)
+		dl := dynamic.NewMessage(schema.DeviceLog)
+		dl.SetFieldByName("device_id", d.MAC)
+		dl.SetFieldByName("ip", m.IP)
+		dl.SetFieldByName("host", m.Host)
+		dl.SetFieldByName("app", m.App)
+		dl.SetFieldByName("severity", uint32(m.Severity))
+		dl.SetFieldByName("text", m.Text)
+		dl.SetFieldByName("match", match)
+		dl.SetFieldByName("ts_unix_ms", ts.UnixMilli())
+		envMsg.SetFieldByName("device_log", dl)
+		if b, err := events.Marshal(envMsg); err == nil {
+			_ = c.Publish(rootCtx, events.DeviceLog, b)
+		}
+	}
+	// startSyslog (re)binds the listener when the syslog settings change.
+	var syslogMu sync.Mutex
+	var syslogSrv *syslogd.Server
+	var syslogCur settings.Syslog
+	var syslogErr atomic.Value // string
+	syslogErr.Store("")
+	startSyslog := func(s settings.Settings) {
+		syslogMu.Lock()
+		defer syslogMu.Unlock()
+		syslogBuf.SetMax(s.Syslog.MaxLines)
+		if syslogSrv != nil && s.Syslog == syslogCur {
+			return
+		}
+		if syslogSrv != nil {
+			syslogSrv.Close()
+			syslogSrv = nil
+		}
+		syslogCur = s.Syslog
+		syslogErr.Store("")
+		if !s.Syslog.Enabled {
+			return
+		}
+		srv, err := syslogd.Listen(syslogd.Config{UDPAddr: s.Syslog.UDPAddr, TCPAddr: s.Syslog.TCPAddr}, onSyslog)
+		if err != nil {
+			log.Warn("syslog listen failed", zap.Error(err))
+			syslogErr.Store(err.Error())
+			return
+		}
+		syslogSrv = srv
+		udp, tcp := srv.Addrs()
+		log.Info("syslog listening", zap.String("udp", udp), zap.String("tcp", tcp))
+	}
+	startSyslog(cfg)
+
 	// Auto enrichment (HTTP deep probe) worker pool.
 	// Goal: devices should populate details automatically without manual clicks.
 	type probeReq struct {
@@ -117,6 +295,34 @@ func main() {
 		Reason string
 	}
 	probeCh := make(chan probeReq, 8192)
+
+	// pollSched decides when each online ASIC is polled next (see the polling loop below).
+	pollSched := sdk.NewScheduler(sdk.SchedulerConfig{})
+	targetOf := func(d *registry.Device) sdk.Target {
+		return sdk.Target{
+			DeviceID:  d.MAC,
+			IP:        d.IP,
+			Vendor:    d.Vendor,
+			Firmware:  d.Firmware,
+			OpenPorts: d.OpenPorts,
+		}
+	}
+	// pollable: devices some registered driver would try (see sdk.Candidates).
+	pollable := func(d *registry.Device) bool {
+		if d == nil || !d.Online {
+			return false
+		}
+		return sdk.Supported(targetOf(d))
+	}
+	// inAlert: devices worth watching closely (hot boards, or up for a while with no hashrate).
+	inAlert := func(d *registry.Device) bool {
+		for _, t := range d.TempsC {
+			if t >= 85 {
+				return true
+			}
+		}
+		return strings.ToLower(d.AuthStatus) == "ok" && d.HashrateTHS == 0 && d.UptimeS > 900
+	}
 	var probeMu sync.Mutex
 	probeNext := map[string]time.Time{} // ip -> next allowed probe time (backoff)
 
@@ -162,117 +368,112 @@ func main() {
 		return 6 * time.Second
 	}
 
-	// Build credential candidates for a device. Tries all enabled creds first (priority desc),
-	// then optionally defaults (Settings.TryDefaultCreds).
-	buildCreds := func(d *registry.Device) []httpapi.Cred {
-		cfg := cfgStore.Get()
-		type cand struct {
-			pri  int
-			fw   string
-			cred httpapi.Cred
-		}
-		var cands []cand
-		dv := strings.ToLower(strings.TrimSpace(d.Vendor))
-		devFW := strings.ToLower(strings.TrimSpace(d.Firmware))
-		devClass := "stock"
-		if strings.Contains(devFW, "vnish") || strings.Contains(devFW, "anthill") || strings.Contains(devFW, "brains") {
-			devClass = "vnish"
+	// Build credential candidates for a device (shared ordering with cmd/collector, see sdk.BuildCreds).
+	buildCreds := func(d *registry.Device) []sdk.Cred {
+		return sdk.BuildCreds(cfgStore.Get(), sec, d.Vendor, d.Firmware)
+	}
+
+	// mergePoll applies a poll (in-process or from a collector) to the registry.
+	mergePoll := func(p sdk.Poll) {
+		ip := p.Target.IP
+		if !p.OK {
+			// Avoid auth flapping: do not downgrade OK->FAIL on transient errors.
+			store.UpdateEnrichment(ip, func(dd *registry.Device) {
+				if strings.ToLower(dd.AuthStatus) == "ok" && time.Since(dd.AuthUpdated) < 10*time.Minute {
+					dd.AuthError = p.Error
+					return
+				}
+				dd.AuthStatus = "fail"
+				dd.AuthUpdated = time.Now().UTC()
+				dd.AuthCredName = p.UsedCred
+				dd.AuthError = p.Error
+			})
+			return
 		}
-		for _, c := range cfg.Credentials {
-			if !c.Enabled {
-				continue
+		f := p.Facts
+		var reboot *reboots.Event
+		store.UpdateEnrichment(ip, func(dd *registry.Device) {
+			dd.AuthStatus = "ok"
+			dd.AuthUpdated = time.Now().UTC()
+			dd.AuthCredName = p.UsedCred
+			dd.AuthError = ""
+			if f.Vendor != "" && (dd.Vendor == "" || dd.Vendor == "unknown" || dd.Vendor == "asic") {
+				dd.Vendor = f.Vendor
 			}
-			cv := strings.ToLower(strings.TrimSpace(c.Vendor))
-			if dv != "" && dv != "unknown" && dv != "asic" && cv != "" && cv != dv {
-				continue
+			if dd.MAC == "" && f.MAC != "" {
+				dd.MAC = f.MAC
 			}
-			user, err := sec.DecryptString(c.UsernameEnc)
-			if err != nil {
-				continue
+			if f.Model != "" {
+				dd.Model = f.Model
 			}
-			pass, err := sec.DecryptString(c.PasswordEnc)
-			if err != nil {
-				continue
+			if f.Firmware != "" {
+				dd.Firmware = f.Firmware
 			}
-			cands = append(cands, cand{
-				pri: c.Priority,
-				fw:  strings.ToLower(strings.TrimSpace(c.Firmware)),
-				cred: httpapi.Cred{
-					Name:     c.Name,
-					Username: user,
-					Password: pass,
-				},
-			})
-		}
-		// Sort by (firmware match) then priority.
-		for i := 0; i < len(cands); i++ {
-			for j := i + 1; j < len(cands); j++ {
-				si := 0
-				sj := 0
-				if cands[i].fw != "" && cands[i].fw == devClass {
-					si += 1000
-				}
-				if cands[j].fw != "" && cands[j].fw == devClass {
-					sj += 1000
+			if f.Worker != "" {
+				dd.Worker = f.Worker
+			}
+			if f.UptimeS > 0 {
+				now := time.Now().UTC()
+				if ev, ok := rebootLog.Observe(ip, dd.UptimeS, f.UptimeS, now, "poll"); ok {
+					reboot = &ev
+					setRebootCounts(dd, now)
 				}
-				si += cands[i].pri
-				sj += cands[j].pri
-				if sj > si {
-					cands[i], cands[j] = cands[j], cands[i]
+				dd.UptimeS = f.UptimeS
+			}
+			// a running miner reporting 0 (curtailed, all boards down) is data, not a missing field
+			if f.HashrateTHS > 0 || f.UptimeS > 0 {
+				dd.HashrateTHS = f.HashrateTHS
+			}
+			if len(f.FansRPM) > 0 {
+				dd.FansRPM = f.FansRPM
+			}
+			if len(f.TempsC) > 0 {
+				dd.TempsC = f.TempsC
+			}
+			// stock firmware reports no power: estimate from the model catalog
+			switch {
+			case f.PowerW > 0:
+				dd.PowerW, dd.PowerEstimated = f.PowerW, false
+			case dd.PowerW == 0 || dd.PowerEstimated:
+				dd.PowerW, dd.PowerEstimated = modelnorm.EstimatePowerW(dd.Model, dd.HashrateTHS)
+			}
+			dd.EfficiencyJTH = registry.JTH(dd.PowerW, dd.HashrateTHS)
+			if f.WorkMode != "" {
+				dd.WorkMode = f.WorkMode
+			}
+			if len(f.Chains) > 0 {
+				dd.Chains = f.Chains
+			}
+			if len(f.Pools) > 0 {
+				dd.Pools = f.Pools
+				dd.ActivePool = ""
+				if ap, ok := sdk.ActivePool(f.Pools); ok {
+					dd.ActivePool = ap.URL
+					if ap.User != "" {
+						dd.Worker = ap.User
+					}
 				}
 			}
+		})
+		if reboot != nil {
+			log.Info("reboot detected", zap.String("ip", ip), zap.Time("boot", reboot.At), zap.Uint64("uptime_before_s", reboot.UptimeBeforeS))
 		}
-
-		out := make([]httpapi.Cred, 0, len(cands)+10)
-
-		// btcTools-like: for stock antminer, try known stock pairs first (before custom)
-		// to reduce operator friction. Only when device does NOT look like vnish.
-		if devClass == "stock" && (dv == "" || dv == "antminer" || dv == "asic" || dv == "unknown") {
-			out = append(out,
-				httpapi.Cred{Name: "stock:root/root", Username: "root", Password: "root"},
-				httpapi.Cred{Name: "stock:root/admin", Username: "root", Password: "admin"},
-			)
-		}
-
-		for _, x := range cands {
-			out = append(out, x.cred)
-		}
-
-		// Optionally, built-in defaults list (currently empty unless you re-add it later)
-		if cfg.TryDefaultCreds {
-			for _, dc := range defaultcreds.Defaults() {
-				if dc.Vendor != "generic" && dc.Vendor != dv {
-					continue
+		if len(f.Pools) > 0 {
+			if pf, raise := poolGuard.Observe(ip, cfgStore.Get().PoolPolicies, f.Pools, time.Now().UTC()); raise || pf.Restore {
+				select {
+				case poolEvents <- poolEvent{f: pf, raise: raise}:
+				default:
 				}
-				out = append(out, httpapi.Cred{Name: "default:" + dc.Vendor, Username: dc.Username, Password: dc.Password})
 			}
 		}
-
-		if len(out) == 0 {
-			out = append(out, httpapi.Cred{Name: "no-auth"})
-		}
-		return out
-	}
-
-	toVnishCreds := func(in []httpapi.Cred) []vnishhttp.Cred {
-		out := make([]vnishhttp.Cred, 0, len(in))
-		for _, c := range in {
-			out = append(out, vnishhttp.Cred{Name: c.Name, Username: c.Username, Password: c.Password})
-		}
-		return out
-	}
-	toWhatsCreds := func(in []httpapi.Cred) []whhttp.Cred {
-		out := make([]whhttp.Cred, 0, len(in))
-		for _, c := range in {
-			out = append(out, whhttp.Cred{Name: c.Name, Username: c.Username, Password: c.Password})
-		}
-		return out
 	}
 
-	runProbe := func(ctx context.Context, ip string) httpapi.ProbeResult {
+	// In-process polling uses the same driver registry as cmd/collector (see internal/collectors/drivers).
+	dispatcher := sdk.NewDispatcher()
+	runProbe := func(ctx context.Context, ip string) sdk.ProbeResult {
 		d, ok := store.Get(ip)
 		if !ok {
-			return httpapi.ProbeResult{OK: false, Error: "not found"}
+			return sdk.ProbeResult{OK: false, Error: "not found"}
 		}
 		if !d.Online {
 			store.UpdateEnrichment(ip, func(dd *registry.Device) {
@@ -280,7 +481,7 @@ func main() {
 				dd.AuthUpdated = time.Now().UTC()
 				dd.AuthError = "offline"
 			})
-			return httpapi.ProbeResult{OK: false, Error: "offline"}
+			return sdk.ProbeResult{OK: false, Error: "offline"}
 		}
 		// mark trying (minimal UI indicator)
 		store.UpdateEnrichment(ip, func(dd *registry.Device) {
@@ -288,194 +489,58 @@ func main() {
 			dd.AuthUpdated = time.Now().UTC()
 			dd.AuthError = ""
 		})
-		v := strings.ToLower(strings.TrimSpace(d.Vendor))
-		// whatsminer probe
-		if v == "whatsminer" {
-			creds := buildCreds(d)
-			schemes := []string{"http"}
-			wres := whhttp.Probe(ctx, ip, toWhatsCreds(creds), schemes)
-			if wres.OK {
-				f := whhttp.ExtractFacts(wres)
-				store.UpdateEnrichment(ip, func(dd *registry.Device) {
-					dd.AuthStatus = "ok"
-					dd.AuthUpdated = time.Now().UTC()
-					dd.AuthCredName = wres.UsedCred
-					dd.AuthError = ""
-					dd.Vendor = "whatsminer"
-					if f.Model != "" {
-						dd.Model = f.Model
-					}
-					if f.UptimeS > 0 {
-						dd.UptimeS = f.UptimeS
-					}
-					if f.HashrateTHS > 0 {
-						dd.HashrateTHS = f.HashrateTHS
-					}
-					if len(f.FansRPM) > 0 {
-						dd.FansRPM = f.FansRPM
-					}
-					if len(f.TempsC) > 0 {
-						dd.TempsC = f.TempsC
-					}
-				})
-				return httpapi.ProbeResult{OK: true, Scheme: wres.Scheme, UsedCred: wres.UsedCred, Error: "", Responses: map[string]any{"whatsminer": wres.Responses}}
-			}
-			store.UpdateEnrichment(ip, func(dd *registry.Device) {
-				dd.AuthStatus = "fail"
-				dd.AuthUpdated = time.Now().UTC()
-				dd.AuthCredName = wres.UsedCred
-				dd.AuthError = wres.Error
-			})
-			return httpapi.ProbeResult{OK: false, Error: wres.Error}
-		}
+		p, res := dispatcher.Probe(ctx, targetOf(d), buildCreds(d))
+		mergePoll(p)
+		return res
+	}
 
-		// antminer first (stock JSON CGI) but if device looks like vnish/anthill, try vnish probe.
-		if v != "antminer" && v != "asic" && v != "" && v != "unknown" {
-			store.UpdateEnrichment(ip, func(dd *registry.Device) {
-				dd.AuthStatus = "fail"
-				dd.AuthUpdated = time.Now().UTC()
-				dd.AuthError = "unsupported vendor"
-			})
-			return httpapi.ProbeResult{OK: false, Error: "unsupported vendor (antminer first)"}
-		}
+	// Command targets reuse the probe credential list, but the credential that last
+	// logged in successfully goes first (control calls are not retried cheaply).
+	commandTarget := func(d *registry.Device) commands.Target {
 		creds := buildCreds(d)
-		// btcTools-like: only try https if 443 is actually open (avoid hanging TLS on ASICs).
-		schemes := []string{}
-		for _, p := range d.OpenPorts {
-			if p == 80 {
-				schemes = append(schemes, "http")
-				break
+		if strings.ToLower(d.AuthStatus) == "ok" && d.AuthCredName != "" {
+			for i, c := range creds {
+				if c.Name == d.AuthCredName && i > 0 {
+					creds = append([]sdk.Cred{c}, append(creds[:i:i], creds[i+1:]...)...)
+					break
+				}
 			}
 		}
-		has443 := false
-		for _, p := range d.OpenPorts {
-			if p == 443 {
-				has443 = true
-				break
-			}
+		return commands.Target{
+			IP:        d.IP,
+			Vendor:    d.Vendor,
+			Firmware:  d.Firmware,
+			OpenPorts: d.OpenPorts,
+			Creds:     creds,
 		}
-		if len(schemes) == 0 {
-			// If we didn't probe ports (or only https farms), default to http first (fast fail).
-			schemes = []string{"http"}
+	}
+
+	// Remote polling: hand background polls to cmd/collector workers via poll.request.
+	// Returns false when NATS is unavailable (caller polls in-process instead).
+	publishPollRequest := func(d *registry.Device) bool {
+		if !cfgStore.Get().Polling.Remote || !natsConnected.Load() {
+			return false
 		}
-		if has443 {
-			schemes = append(schemes, "https")
+		natsMu.RLock()
+		c := natsClient
+		natsMu.RUnlock()
+		if c == nil {
+			return false
 		}
+		b, err := sdk.EncodePollRequest(schema, targetOf(d))
+		if err != nil {
+			return false
+		}
+		return c.Publish(rootCtx, events.PollRequest, b) == nil
+	}
 
-		isAnthill := strings.Contains(strings.ToLower(d.Firmware), "anthill")
-
-		res := httpapi.ProbeAntminerSchemes(ctx, ip, creds, schemes)
-		if res.OK {
-			facts := httpapi.ExtractFacts(res)
-			// If we authenticated but still extracted nothing useful, mark FAIL (so operator can click in).
-			if facts.MAC == "" && facts.Worker == "" && facts.Firmware == "" && facts.Model == "" && facts.HashrateTHS == 0 && facts.UptimeS == 0 && len(facts.FansRPM) == 0 && len(facts.TempsC) == 0 {
-				store.UpdateEnrichment(ip, func(dd *registry.Device) {
-					dd.AuthStatus = "fail"
-					dd.AuthUpdated = time.Now().UTC()
-					dd.AuthCredName = res.UsedCred
-					dd.AuthError = "ok but no parsable data"
-				})
-				return res
-			}
-
-			store.UpdateEnrichment(ip, func(dd *registry.Device) {
-				dd.AuthStatus = "ok"
-				dd.AuthUpdated = time.Now().UTC()
-				dd.AuthCredName = res.UsedCred
-				dd.AuthError = ""
-				if dd.Vendor == "" || dd.Vendor == "unknown" || dd.Vendor == "asic" {
-					dd.Vendor = "antminer"
-				}
-				if dd.MAC == "" && facts.MAC != "" {
-					dd.MAC = facts.MAC
-				}
-				if facts.Model != "" {
-					n := modelnorm.Normalize(facts.Model)
-					if n.Model != "" {
-						dd.Model = n.Model
-						if (dd.Vendor == "" || dd.Vendor == "unknown" || dd.Vendor == "asic") && n.Vendor != "unknown" {
-							dd.Vendor = n.Vendor
-						}
-					} else {
-						dd.Model = facts.Model
-					}
-				}
-				if facts.Firmware != "" {
-					dd.Firmware = facts.Firmware
-				}
-				if facts.Worker != "" {
-					dd.Worker = facts.Worker
-				}
-				if facts.UptimeS > 0 {
-					dd.UptimeS = facts.UptimeS
-				}
-				if facts.HashrateTHS > 0 {
-					dd.HashrateTHS = facts.HashrateTHS
-				}
-				if len(facts.FansRPM) > 0 {
-					dd.FansRPM = facts.FansRPM
-				}
-				if len(facts.TempsC) > 0 {
-					dd.TempsC = facts.TempsC
-				}
-			})
-		} else {
-			// If CGI is not JSON API (e.g. Anthill/Vnish SPA), try vnish/anthill API probe.
-			if isAnthill || strings.Contains(strings.ToLower(res.Error), "html response") || strings.Contains(strings.ToLower(d.Firmware), "vnish") {
-				vres := vnishhttp.Probe(ctx, ip, toVnishCreds(creds), schemes)
-				if vres.OK {
-					f := vnishhttp.ExtractFacts(vres)
-					store.UpdateEnrichment(ip, func(dd *registry.Device) {
-						dd.AuthStatus = "ok"
-						dd.AuthUpdated = time.Now().UTC()
-						dd.AuthCredName = vres.UsedCred
-						dd.AuthError = ""
-						if dd.Vendor == "" || dd.Vendor == "unknown" || dd.Vendor == "asic" {
-							dd.Vendor = "antminer"
-						}
-						if f.Model != "" {
-							n := modelnorm.Normalize(f.Model)
-							if n.Model != "" {
-								dd.Model = n.Model
-							} else {
-								dd.Model = f.Model
-							}
-						}
-						if f.Firmware != "" {
-							dd.Firmware = f.Firmware
-						}
-						if f.Worker != "" {
-							dd.Worker = f.Worker
-						}
-						if f.UptimeS > 0 {
-							dd.UptimeS = f.UptimeS
-						}
-						if f.HashrateTHS > 0 {
-							dd.HashrateTHS = f.HashrateTHS
-						}
-						if len(f.FansRPM) > 0 {
-							dd.FansRPM = f.FansRPM
-						}
-						if len(f.TempsC) > 0 {
-							dd.TempsC = f.TempsC
-						}
-					})
-					return httpapi.ProbeResult{OK: true, Scheme: vres.Scheme, UsedCred: vres.UsedCred, Error: "", Responses: map[string]any{"vnish": vres.Responses}}
-				}
-			}
-			// Avoid auth flapping: do not downgrade OK->FAIL on transient errors.
-			store.UpdateEnrichment(ip, func(dd *registry.Device) {
-				if strings.ToLower(dd.AuthStatus) == "ok" && time.Since(dd.AuthUpdated) < 10*time.Minute {
-					dd.AuthError = res.Error
-					return
-				}
-				dd.AuthStatus = "fail"
-				dd.AuthUpdated = time.Now().UTC()
-				dd.AuthCredName = res.UsedCred
-				dd.AuthError = res.Error
-			})
+	// applyPoll merges a collector poll.result into the registry (same rules as runProbe).
+	applyPoll := func(p sdk.Poll) {
+		if _, ok := store.Get(p.Target.IP); !ok {
+			return
 		}
-		return res
+		pollSched.Done(p.Target.IP, p.OK, time.Now())
+		mergePoll(p)
 	}
 
 	// workers (faster enrichment for large fleets; bounded by per-IP backoff)
@@ -491,36 +556,51 @@ func main() {
 					if dd, ok := store.Get(req.IP); ok {
 						d = dd
 					}
+					if d != nil && d.Online && publishPollRequest(d) {
+						continue
+					}
 					ctx, cancel := context.WithTimeout(rootCtx, probeTimeoutFor(d))
-					_ = runProbe(ctx, req.IP)
+					res := runProbe(ctx, req.IP)
 					cancel()
+					pollSched.Done(req.IP, res.OK, time.Now())
 				}
 			}
 		}()
 	}
 
-	// periodic retry: pick devices that are online and missing key fields.
+	// periodic telemetry polling: every online ASIC on settings.polling.interval, spread over
+	// the interval, backed off while unreachable and faster while in an alert state.
 	go func() {
-		t := time.NewTicker(12 * time.Second)
+		t := time.NewTicker(1 * time.Second)
 		defer t.Stop()
 		for {
 			select {
 			case <-rootCtx.Done():
 				return
 			case <-t.C:
-				for _, d := range store.List() {
-					// only re-probe if likely needed
-					if !d.Online {
+				now := time.Now()
+				pc := cfgStore.Get().Polling
+				pollSched.SetConfig(sdk.SchedulerConfig{Interval: pc.Interval, AlertInterval: pc.AlertInterval, MaxBackoff: pc.MaxBackoff})
+				devs := store.List()
+				keys := make([]string, 0, len(devs))
+				for _, d := range devs {
+					if !pollable(d) {
 						continue
 					}
-					if strings.ToLower(d.Vendor) != "antminer" && strings.ToLower(d.Vendor) != "asic" && d.Vendor != "" {
-						continue
-					}
-					need := d.MAC == "" || d.Worker == "" || d.Firmware == "" || len(d.FansRPM) == 0
-					if !need {
-						continue
+					keys = append(keys, d.IP)
+					pollSched.SetAlert(d.IP, inAlert(d), now)
+				}
+				pollSched.Sync(keys, now)
+				// never queue more than the workers can drain; the rest stays due for the next tick
+				free := cap(probeCh) - len(probeCh)
+				if free <= 0 {
+					continue
+				}
+				for _, ip := range pollSched.Due(now, free) {
+					select {
+					case probeCh <- probeReq{IP: ip, Reason: "poll"}:
+					default:
 					}
-					enqueueProbe(d.IP, "tick", 60*time.Second)
 				}
 			}
 		}
@@ -539,12 +619,6 @@ func main() {
 	scanMu := sync.Mutex{}
 	scans := map[int64]scanJob{}
 
-	// NATS is optional at runtime: core must start even if NATS is down.
-	var natsMu sync.RWMutex
-	var natsClient *natsjs.Client
-	var natsConnected atomic.Bool
-	var natsLastErr atomic.Value // string
-
 	runScan := func(scanCtx context.Context, subnetID int64, spec string) {
 		defer func() {
 			scanMu.Lock()
@@ -580,6 +654,7 @@ func main() {
 
 				_ = store.UpsertDiscovery("scanner", ip, "", now)
 				_ = store.UpsertObserved("scanner", ip, "", res.Online, now)
+				var reboot *reboots.Event
 				store.UpdateEnrichment(ip, func(d *registry.Device) {
 					d.OpenPorts = res.Open
 					d.Confidence = res.Confidence
@@ -596,6 +671,11 @@ func main() {
 						d.Worker = res.Worker
 					}
 					if res.UptimeS > 0 {
+						// same reboot detection as polls: a scan may be first to see the restart
+						if ev, ok := rebootLog.Observe(ip, d.UptimeS, res.UptimeS, now, "scan"); ok {
+							reboot = &ev
+							setRebootCounts(d, now)
+						}
 						d.UptimeS = res.UptimeS
 					}
 					if res.HashrateTHS > 0 {
@@ -608,6 +688,9 @@ func main() {
 						d.TempsC = res.TempsC
 					}
 				})
+				if reboot != nil {
+					log.Info("reboot detected", zap.String("ip", ip), zap.Time("boot", reboot.At), zap.Uint64("uptime_before_s", reboot.UptimeBeforeS))
+				}
 
 				if !res.IsASIC {
 					return
@@ -664,6 +747,230 @@ func main() {
 		)
 	}
 
+	// Commands are queued through JetStream: command.request -> executor (pull consumer, nak+delay
+	// retries) -> command.result -> audit. When NATS is down they run in-process (same audit).
+	execCommand := func(ctx context.Context, req commands.Request) commands.Result {
+		d, ok := store.Get(req.IP)
+		if !ok {
+			return commands.Result{CommandID: req.CommandID, IP: req.IP, Kind: req.Kind, Error: "device not found", StartedAt: time.Now().UTC()}
+		}
+		res := commands.Execute(ctx, commandTarget(d), req.Kind, req.Args)
+		res.CommandID = req.CommandID
+		return res
+	}
+	recordCommand := func(rec commands.Record) {
+		cmdAudit.Add(rec)
+		if rec.Final && rec.OK && rec.Kind == commands.KindReboot {
+			at := rec.StartedAt
+			if at.IsZero() {
+				at = rec.RecordedAt
+			}
+			rebootLog.Add(reboots.Event{
+				IP:        rec.IP,
+				At:        at,
+				Reason:    reboots.ReasonCommand,
+				Source:    rec.Source,
+				Operator:  rec.Operator,
+				CommandID: rec.CommandID,
+			})
+			// miner goes away for a few minutes; uptime restarts from zero
+			now := time.Now().UTC()
+			store.UpdateEnrichment(rec.IP, func(dd *registry.Device) {
+				dd.UptimeS = 0
+				setRebootCounts(dd, now)
+			})
+		}
+		log.Info("command",
+			zap.String("id", rec.CommandID),
+			zap.String("kind", rec.Kind),
+			zap.String("ip", rec.IP),
+			zap.String("operator", rec.Operator),
+			zap.Bool("ok", rec.OK),
+			zap.Int("attempt", rec.Attempt),
+			zap.Bool("final", rec.Final),
+			zap.String("error", rec.Error),
+		)
+	}
+	// Rolling reboot counters age out: refresh the devices with history once a minute.
+	go func() {
+		t := time.NewTicker(time.Minute)
+		defer t.Stop()
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-t.C:
+			}
+			now := time.Now().UTC()
+			for _, ip := range rebootLog.IPs() {
+				d, ok := store.Get(ip)
+				if !ok {
+					continue
+				}
+				h1, h24, _ := rebootLog.Counts(ip, now)
+				if d.Reboots1h != h1 || d.Reboots24h != h24 {
+					store.UpdateEnrichment(ip, func(dd *registry.Device) { setRebootCounts(dd, now) })
+				}
+			}
+		}
+	}()
+	// device.state_updated is published by core from the merged registry (in-process and remote
+	// polls, scans, auth, reboot counters) when a device changes past settings.state_events,
+	// plus a heartbeat. While NATS is down nothing is marked published, so the first pass after
+	// reconnecting catches up.
+	stateTracker := statechange.NewTracker()
+	go func() {
+		changed := store.Subscribe(rootCtx)
+		tick := time.NewTicker(30 * time.Second) // heartbeats and aging counters
+		defer tick.Stop()
+		for {
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-changed:
+			case <-tick.C:
+			}
+			natsMu.RLock()
+			c := natsClient
+			natsMu.RUnlock()
+			if !natsConnected.Load() || c == nil {
+				continue
+			}
+			se := cfgStore.Get().StateEvents
+			now := time.Now().UTC()
+			for _, d := range store.List() {
+				if !sdk.Supported(targetOf(d)) {
+					continue
+				}
+				s, why := stateTracker.Check(statechange.FromDevice(d), se, now)
+				if len(why) == 0 {
+					continue
+				}
+				if b, err := statechange.Encode(schema, s, why); err == nil {
+					_ = c.Publish(rootCtx, events.DeviceStateUpdated, b)
+				}
+			}
+			// coalesce bursts of registry updates (every poll notifies)
+			select {
+			case <-rootCtx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+		}
+	}()
+	publishCommandResult := func(c *natsjs.Client, req commands.Request, res commands.Result, attempt int, final bool) error {
+		envMsg := schema.NewEnvelope(events.CommandResult)
+		envMsg.SetFieldByName("ip", req.IP)
+		cr := dynamic.NewMessage(schema.CommandResult)
+		cr.SetFieldByName("command_id", req.CommandID)
+		cr.SetFieldByName("kind", req.Kind)
+		cr.SetFieldByName("ip", req.IP)
+		cr.SetFieldByName("ok", res.OK)
+		cr.SetFieldByName("error", res.Error)
+		cr.SetFieldByName("driver", res.Driver)
+		cr.SetFieldByName("used_cred", res.UsedCred)
+		cr.SetFieldByName("detail", res.Detail)
+		cr.SetFieldByName("operator", req.Operator)
+		cr.SetFieldByName("source", req.Source)
+		cr.SetFieldByName("batch_id", req.BatchID)
+		cr.SetFieldByName("attempt", uint32(attempt))
+		cr.SetFieldByName("final", final)
+		cr.SetFieldByName("started_unix_ms", res.StartedAt.UnixMilli())
+		cr.SetFieldByName("duration_ms", res.DurationMS)
+		envMsg.SetFieldByName("command_result", cr)
+		b, err := events.Marshal(envMsg)
+		if err != nil {
+			return err
+		}
+		return c.Publish(context.Background(), events.CommandResult, b)
+	}
+	runCommandsDirect := func(ctx context.Context, reqs []commands.Request) []commands.Result {
+		out := make([]commands.Result, len(reqs))
+		sem := make(chan struct{}, 16)
+		var wg sync.WaitGroup
+		for i, req := range reqs {
+			wg.Add(1)
+			go func(i int, req commands.Request) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				res := execCommand(ctx, req)
+				recordCommand(commands.Record{Result: res, Operator: req.Operator, Source: req.Source, BatchID: req.BatchID, Attempt: 1, Final: true})
+				out[i] = res
+			}(i, req)
+		}
+		wg.Wait()
+		return out
+	}
+	// submitCommands queues reqs and waits (until ctx ends) for their final results.
+	submitCommands := func(ctx context.Context, reqs []commands.Request) []commands.Result {
+		for i := range reqs {
+			if reqs[i].CommandID == "" {
+				reqs[i].CommandID = events.NewID()
+			}
+			if reqs[i].MaxAttempts <= 0 {
+				reqs[i].MaxAttempts = commands.DefaultMaxAttempts
+			}
+			reqs[i].Stamp(time.Now())
+		}
+		natsMu.RLock()
+		c := natsClient
+		natsMu.RUnlock()
+		if !natsConnected.Load() || c == nil {
+			return runCommandsDirect(ctx, reqs)
+		}
+
+		out := make([]commands.Result, len(reqs))
+		var direct []int
+		var wg sync.WaitGroup
+		for i, req := range reqs {
+			envMsg := schema.NewEnvelope(events.CommandRequest)
+			envMsg.SetFieldByName("ip", req.IP)
+			cr := dynamic.NewMessage(schema.CommandRequest)
+			cr.SetFieldByName("command_id", req.CommandID)
+			cr.SetFieldByName("kind", req.Kind)
+			cr.SetFieldByName("ip", req.IP)
+			cr.SetFieldByName("operator", req.Operator)
+			cr.SetFieldByName("source", req.Source)
+			cr.SetFieldByName("batch_id", req.BatchID)
+			cr.SetFieldByName("max_attempts", uint32(req.MaxAttempts))
+			cr.SetFieldByName("created_unix_ms", req.CreatedAt.UnixMilli())
+			cr.SetFieldByName("deadline_unix_ms", req.Deadline.UnixMilli())
+			if len(req.Args) > 0 {
+				cr.SetFieldByName("args", req.Args)
+			}
+			envMsg.SetFieldByName("command_request", cr)
+			b, err := events.Marshal(envMsg)
+			if err == nil {
+				err = c.Publish(ctx, events.CommandRequest, b)
+			}
+			if err != nil {
+				direct = append(direct, i)
+				continue
+			}
+			wg.Add(1)
+			go func(i int, req commands.Request) {
+				defer wg.Done()
+				if rec, ok := cmdAudit.Wait(ctx, req.CommandID); ok {
+					out[i] = rec.Result
+					return
+				}
+				out[i] = commands.Result{CommandID: req.CommandID, IP: req.IP, Kind: req.Kind, Error: "queued: no result yet (see /api/commands)"}
+			}(i, req)
+		}
+		if len(direct) > 0 {
+			sub := make([]commands.Request, 0, len(direct))
+			for _, i := range direct {
+				sub = append(sub, reqs[i])
+			}
+			for j, res := range runCommandsDirect(ctx, sub) {
+				out[direct[j]] = res
+			}
+		}
+		wg.Wait()
+		return out
+	}
+
 	reconnectCh := make(chan struct{}, 1)
 	requestReconnect := func() {
 		select {
@@ -680,172 +987,1091 @@ func main() {
 			natsLastErr.Store(err.Error())
 			return
 		}
-		go func() {
-			for natsConnected.Load() {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-				msgs, err := consumer.Fetch(ctx, 256, 2*time.Second)
-				if err != nil {
-					continue
-				}
-				for _, m := range msgs {
-					envMsg, err := events.UnmarshalEnvelope(schema, m.Data())
-					if err != nil {
-						_ = m.Term()
-						continue
-					}
-
-					subj := envMsg.GetFieldByName("subject").(string)
-					ip := envMsg.GetFieldByName("ip").(string)
-					mac := envMsg.GetFieldByName("mac").(string)
-					shard := envMsg.GetFieldByName("shard_id").(string)
+		go func() {
+			for natsConnected.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				msgs, err := consumer.Fetch(ctx, 256, 2*time.Second)
+				if err != nil {
+					continue
+				}
+				for _, m := range msgs {
+					envMsg, err := events.UnmarshalEnvelope(schema, m.Data())
+					if err != nil {
+						_ = m.Term()
+						continue
+					}
+
+					subj := envMsg.GetFieldByName("subject").(string)
+					ip := envMsg.GetFieldByName("ip").(string)
+					mac := envMsg.GetFieldByName("mac").(string)
+					shard := envMsg.GetFieldByName("shard_id").(string)
+
+					now := time.Now().UTC()
+					switch subj {
+					case events.NetworkDeviceDiscovered:
+						_ = store.UpsertDiscovery(shard, ip, mac, now)
+					case events.NetworkObserved:
+						payload := envMsg.GetFieldByName("network_observed").(*dynamic.Message)
+						online := payload.GetFieldByName("online").(bool)
+						_ = store.UpsertObserved(shard, ip, mac, online, now)
+					}
+					_ = m.Ack()
+				}
+			}
+		}()
+	}
+
+	// poll.result consumer (remote collectors)
+	startPollConsumer := func(c *natsjs.Client) {
+		ctx := rootCtx
+		consumer, err := c.NewPullConsumer("core-poll", events.PollResult, 4096)
+		if err != nil {
+			natsLastErr.Store(err.Error())
+			return
+		}
+		go func() {
+			for natsConnected.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				msgs, err := consumer.Fetch(ctx, 256, 2*time.Second)
+				if err != nil {
+					continue
+				}
+				for _, m := range msgs {
+					p, err := sdk.DecodePollResult(schema, m.Data())
+					if err != nil {
+						_ = m.Term()
+						continue
+					}
+					applyPoll(p)
+					_ = m.Ack()
+				}
+			}
+		}()
+	}
+
+	// alert.raised consumer: alerts of other services (automation) into the alert store
+	startAlertConsumer := func(c *natsjs.Client) {
+		ctx := rootCtx
+		consumer, err := c.NewPullConsumer("core-alerts", events.AlertRaised, 1024)
+		if err != nil {
+			natsLastErr.Store(err.Error())
+			return
+		}
+		go func() {
+			for natsConnected.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				msgs, err := consumer.Fetch(ctx, 256, 2*time.Second)
+				if err != nil {
+					continue
+				}
+				for _, m := range msgs {
+					envMsg, err := events.UnmarshalEnvelope(schema, m.Data())
+					if err != nil {
+						_ = m.Term()
+						continue
+					}
+					ar, ok := envMsg.GetFieldByName("alert_raised").(*dynamic.Message)
+					shard := envMsg.GetFieldByName("shard_id").(string)
+					if !ok || ar == nil || shard == "core" {
+						_ = m.Ack()
+						continue
+					}
+					alertStore.Raise(alerts.Raised{
+						IP:       envMsg.GetFieldByName("ip").(string),
+						DeviceID: ar.GetFieldByName("device_id").(string),
+						Severity: ar.GetFieldByName("severity").(string),
+						Code:     ar.GetFieldByName("code").(string),
+						Message:  ar.GetFieldByName("message").(string),
+						Tags:     sdk.StringMap(ar.GetFieldByName("tags")),
+						Source:   shard,
+						At:       time.UnixMilli(envMsg.GetFieldByName("ts_unix_ms").(int64)).UTC(),
+					})
+					_ = m.Ack()
+				}
+			}
+		}()
+	}
+
+	// command executor + result (audit) consumers
+	startCommandConsumers := func(c *natsjs.Client) {
+		ctx := rootCtx
+		reqConsumer, err := c.NewPullConsumerWith("core-commands", events.CommandRequest, natsjs.ConsumerOptions{
+			MaxAckPending: 64,
+			AckWait:       2 * time.Minute,
+			MaxDeliver:    10,
+		})
+		if err != nil {
+			natsLastErr.Store(err.Error())
+			return
+		}
+		resConsumer, err := c.NewPullConsumer("core-command-results", events.CommandResult, 1024)
+		if err != nil {
+			natsLastErr.Store(err.Error())
+			return
+		}
+
+		sem := make(chan struct{}, 32)
+		go func() {
+			for natsConnected.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				msgs, err := reqConsumer.Fetch(ctx, 16, 2*time.Second)
+				if err != nil {
+					continue
+				}
+				for _, m := range msgs {
+					envMsg, err := events.UnmarshalEnvelope(schema, m.Data())
+					if err != nil {
+						_ = m.Term()
+						continue
+					}
+					cr, ok := envMsg.GetFieldByName("command_request").(*dynamic.Message)
+					if !ok || cr == nil {
+						_ = m.Term()
+						continue
+					}
+					req := commands.Request{
+						CommandID:   cr.GetFieldByName("command_id").(string),
+						Kind:        cr.GetFieldByName("kind").(string),
+						IP:          cr.GetFieldByName("ip").(string),
+						Operator:    cr.GetFieldByName("operator").(string),
+						Source:      cr.GetFieldByName("source").(string),
+						BatchID:     cr.GetFieldByName("batch_id").(string),
+						MaxAttempts: int(cr.GetFieldByName("max_attempts").(uint32)),
+						Args:        sdk.StringMap(cr.GetFieldByName("args")),
+					}
+					if ms := cr.GetFieldByName("created_unix_ms").(int64); ms > 0 {
+						req.CreatedAt = time.UnixMilli(ms).UTC()
+					}
+					if ms := cr.GetFieldByName("deadline_unix_ms").(int64); ms > 0 {
+						req.Deadline = time.UnixMilli(ms).UTC()
+					}
+					if req.MaxAttempts <= 0 {
+						req.MaxAttempts = commands.DefaultMaxAttempts
+					}
+					if req.Expired(time.Now()) {
+						// stale redelivery (the stream keeps days of requests): audit it, never run it
+						res := commands.Result{IP: req.IP, Kind: req.Kind, Error: "expired: not run before its deadline", StartedAt: time.Now().UTC()}
+						attempt := int(m.NumDelivered())
+						if err := publishCommandResult(c, req, res, attempt, true); err != nil {
+							recordCommand(commands.Record{Result: res, Operator: req.Operator, Source: req.Source, BatchID: req.BatchID, Attempt: attempt, Final: true})
+						}
+						_ = m.Term()
+						continue
+					}
+
+					sem <- struct{}{}
+					go func(m bus.Message, req commands.Request) {
+						defer func() { <-sem }()
+						attempt := int(m.NumDelivered())
+						if attempt < 1 {
+							attempt = 1
+						}
+						exCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+						res := execCommand(exCtx, req)
+						cancel()
+						final := res.OK || !commands.Retryable(res) || attempt >= req.MaxAttempts ||
+							req.Expired(time.Now().Add(commands.RetryDelay(attempt)))
+						if err := publishCommandResult(c, req, res, attempt, final); err != nil {
+							// keep the audit complete even if the result could not be published
+							recordCommand(commands.Record{Result: res, Operator: req.Operator, Source: req.Source, BatchID: req.BatchID, Attempt: attempt, Final: final})
+						}
+						if final {
+							_ = m.Ack()
+							return
+						}
+						_ = m.NakWithDelay(commands.RetryDelay(attempt))
+					}(m, req)
+				}
+			}
+		}()
+
+		go func() {
+			for natsConnected.Load() {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				msgs, err := resConsumer.Fetch(ctx, 256, 2*time.Second)
+				if err != nil {
+					continue
+				}
+				for _, m := range msgs {
+					envMsg, err := events.UnmarshalEnvelope(schema, m.Data())
+					if err != nil {
+						_ = m.Term()
+						continue
+					}
+					cr, ok := envMsg.GetFieldByName("command_result").(*dynamic.Message)
+					if !ok || cr == nil {
+						_ = m.Term()
+						continue
+					}
+					recordCommand(commands.Record{
+						Result: commands.Result{
+							CommandID:  cr.GetFieldByName("command_id").(string),
+							IP:         cr.GetFieldByName("ip").(string),
+							Kind:       cr.GetFieldByName("kind").(string),
+							OK:         cr.GetFieldByName("ok").(bool),
+							Driver:     cr.GetFieldByName("driver").(string),
+							UsedCred:   cr.GetFieldByName("used_cred").(string),
+							Detail:     cr.GetFieldByName("detail").(string),
+							Error:      cr.GetFieldByName("error").(string),
+							StartedAt:  time.UnixMilli(cr.GetFieldByName("started_unix_ms").(int64)).UTC(),
+							DurationMS: cr.GetFieldByName("duration_ms").(int64),
+						},
+						Operator: cr.GetFieldByName("operator").(string),
+						Source:   cr.GetFieldByName("source").(string),
+						BatchID:  cr.GetFieldByName("batch_id").(string),
+						Attempt:  int(cr.GetFieldByName("attempt").(uint32)),
+						Final:    cr.GetFieldByName("final").(bool),
+					})
+					_ = m.Ack()
+				}
+			}
+		}()
+	}
+
+	// connect loop
+	go func() {
+		for {
+			select {
+			case <-rootCtx.Done():
+				natsMu.Lock()
+				if natsClient != nil {
+					_ = natsClient.Close()
+					natsClient = nil
+				}
+				natsMu.Unlock()
+				return
+			default:
+			}
+			cfg := cfgStore.Get()
+			url := cfg.NATSURL
+			prefix := cfg.NATSPrefix
+
+			c, err := natsjs.Connect(natsjs.Config{
+				URL:     url,
+				Prefix:  prefix,
+				Timeout: 2 * time.Second,
+			})
+			if err != nil {
+				natsConnected.Store(false)
+				natsLastErr.Store(err.Error())
+				select {
+				case <-rootCtx.Done():
+					return
+				case <-time.After(2 * time.Second):
+					continue
+				case <-reconnectCh:
+					continue
+				}
+			}
+			if err := c.EnsureStreams(); err != nil {
+				_ = c.Close()
+				natsConnected.Store(false)
+				natsLastErr.Store(err.Error())
+				select {
+				case <-rootCtx.Done():
+					return
+				case <-time.After(2 * time.Second):
+					continue
+				case <-reconnectCh:
+					continue
+				}
+			}
+
+			natsMu.Lock()
+			if natsClient != nil {
+				_ = natsClient.Close()
+			}
+			natsClient = c
+			natsMu.Unlock()
+
+			natsConnected.Store(true)
+			natsLastErr.Store("")
+			startConsumer(c, prefix)
+			startCommandConsumers(c)
+			startPollConsumer(c)
+			startAlertConsumer(c)
+
+			// wait for explicit reconnect request
+			select {
+			case <-rootCtx.Done():
+				natsConnected.Store(false)
+				natsMu.Lock()
+				if natsClient != nil {
+					_ = natsClient.Close()
+					natsClient = nil
+				}
+				natsMu.Unlock()
+				return
+			case <-reconnectCh:
+			}
+			natsConnected.Store(false)
+		}
+	}()
+
+	r := chi.NewRouter()
+	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	r.Get("/api/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "text/plain")
+		_, _ = w.Write([]byte(version.String()))
+	})
+	r.Get("/api/cidr/preview", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		cidr := r.URL.Query().Get("cidr")
+		_ = json.NewEncoder(w).Encode(netutil.PreviewSpec(cidr))
+	})
+	syslogStatus := func() map[string]any {
+		syslogMu.Lock()
+		on := syslogSrv != nil
+		syslogMu.Unlock()
+		devices, received := syslogBuf.Stats()
+		errStr, _ := syslogErr.Load().(string)
+		return map[string]any{
+			"listening": on,
+			"error":     errStr,
+			"devices":   devices,
+			"received":  received,
+			"dropped":   syslogDropped.Load(),
+		}
+	}
+	r.Get("/api/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		errStr, _ := natsLastErr.Load().(string)
+		embMu.Lock()
+		embOn := emb != nil
+		embMu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"nats_connected": natsConnected.Load(),
+			"nats_error":     errStr,
+			"embedded_nats":  embOn,
+			"started_at":     startedAt.Format(time.RFC3339),
+			"uptime_s":       int64(time.Since(startedAt).Seconds()),
+			"polling":        pollSched.Stats(),
+			"syslog":         syslogStatus(),
+		})
+	})
+	// ?vendor=&model=&firmware=&worker=&pool=&q=&online=1 narrow the list (same rules as bulk selections).
+	r.Get("/api/devices", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		sel := registry.Selection{
+			Vendor:     q.Get("vendor"),
+			Model:      q.Get("model"),
+			Firmware:   q.Get("firmware"),
+			Worker:     q.Get("worker"),
+			Pool:       q.Get("pool"),
+			Query:      q.Get("q"),
+			OnlineOnly: q.Get("online") == "1" || q.Get("online") == "true",
+		}
+		w.Header().Set("content-type", "application/json")
+		if sel.Empty() {
+			_ = json.NewEncoder(w).Encode(store.List())
+			return
+		}
+		_ = json.NewEncoder(w).Encode(store.Select(sel))
+	})
+
+	// Power efficiency (J/TH) grouped by subnet (default), active mining pool, vendor or model.
+	r.Get("/api/efficiency", func(w http.ResponseWriter, r *http.Request) {
+		var key func(*registry.Device) string
+		switch by := strings.TrimSpace(r.URL.Query().Get("by")); by {
+		case "", "subnet":
+			subs := subnetsStore.List()
+			sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+			key = func(d *registry.Device) string {
+				for _, s := range subs {
+					if netutil.SpecContains(s.CIDR, d.IP) {
+						return s.CIDR
+					}
+				}
+				return "unassigned"
+			}
+		case "vendor":
+			key = func(d *registry.Device) string { return d.Vendor }
+		case "model":
+			key = func(d *registry.Device) string { return d.Model }
+		case "pool":
+			key = func(d *registry.Device) string { return d.ActivePool }
+		default:
+			http.Error(w, "bad by: want subnet, pool, vendor or model", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(registry.Efficiency(store.List(), key))
+	})
+
+	// Device details (light) + deep probe (Antminer first)
+	r.Get("/api/devices/{ip}", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		w.Header().Set("content-type", "application/json")
+		if d, ok := store.Get(ip); ok {
+			_ = json.NewEncoder(w).Encode(d)
+			return
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	r.Post("/api/devices/{ip}/probe", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		res := runProbe(ctx, ip)
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+
+	// Control: reboot (single device and bulk selection). Results are per device.
+	// Commands go through the queue (see submitCommands); operator comes from X-MonA-Operator.
+	operatorOf := func(r *http.Request) string {
+		if op := strings.TrimSpace(r.Header.Get("X-MonA-Operator")); op != "" {
+			return op
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		return "ui@" + host
+	}
+	summarize := func(results []commands.Result, batchID string) map[string]any {
+		okN := 0
+		for _, res := range results {
+			if res.OK {
+				okN++
+			}
+		}
+		return map[string]any{
+			"batch_id": batchID,
+			"results":  results,
+			"ok":       okN,
+			"failed":   len(results) - okN,
+		}
+	}
+	r.Post("/api/devices/{ip}/reboot", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		if _, ok := store.Get(ip); !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 45*time.Second)
+		defer cancel()
+		res := submitCommands(ctx, []commands.Request{{
+			Kind:     commands.KindReboot,
+			IP:       ip,
+			Operator: operatorOf(r),
+			Source:   "api",
+		}})[0]
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	r.Post("/api/devices/reboot", func(w http.ResponseWriter, r *http.Request) {
+		var sel registry.Selection
+		if err := json.NewDecoder(r.Body).Decode(&sel); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if sel.Empty() {
+			http.Error(w, "empty selection (set ips or a filter)", http.StatusBadRequest)
+			return
+		}
+		batchID := events.NewID()
+		op := operatorOf(r)
+		var reqs []commands.Request
+		for _, d := range store.Select(sel) {
+			reqs = append(reqs, commands.Request{Kind: commands.KindReboot, IP: d.IP, Operator: op, Source: "api", BatchID: batchID})
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+		defer cancel()
+		results := submitCommands(ctx, reqs)
+		out := summarize(results, batchID)
+		log.Info("bulk reboot", zap.String("batch", batchID), zap.String("operator", op), zap.Int("selected", len(reqs)), zap.Any("ok", out["ok"]))
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+
+	// Control: power/LED commands ({"kind":"power_off","args":{...}}), single device or selection.
+	r.Post("/api/devices/{ip}/commands", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		if _, ok := store.Get(ip); !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Kind string            `json:"kind"`
+			Args map[string]string `json:"args"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if !commands.Known(req.Kind) || req.Kind == commands.KindSetPools {
+			http.Error(w, "unknown command kind", http.StatusBadRequest)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 45*time.Second)
+		defer cancel()
+		res := submitCommands(ctx, []commands.Request{{
+			Kind:     req.Kind,
+			IP:       ip,
+			Operator: operatorOf(r),
+			Source:   "api",
+			Args:     req.Args,
+		}})[0]
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	r.Post("/api/devices/commands", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Selection registry.Selection `json:"selection"`
+			Kind      string             `json:"kind"`
+			Args      map[string]string  `json:"args"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if !commands.Known(req.Kind) || req.Kind == commands.KindSetPools {
+			http.Error(w, "unknown command kind", http.StatusBadRequest)
+			return
+		}
+		if req.Selection.Empty() {
+			http.Error(w, "empty selection (set ips or a filter)", http.StatusBadRequest)
+			return
+		}
+		batchID := events.NewID()
+		op := operatorOf(r)
+		var reqs []commands.Request
+		for _, d := range store.Select(req.Selection) {
+			reqs = append(reqs, commands.Request{Kind: req.Kind, IP: d.IP, Operator: op, Source: "api", BatchID: batchID, Args: req.Args})
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+		defer cancel()
+		results := submitCommands(ctx, reqs)
+		out := summarize(results, batchID)
+		log.Info("bulk command", zap.String("kind", req.Kind), zap.String("batch", batchID), zap.String("operator", op), zap.Int("selected", len(reqs)), zap.Any("ok", out["ok"]))
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+
+	// Live diagnostics through the device's driver (the diagnostics control kind; Whatsminer
+	// btminer: summary/devs/devdetails, PSU, error codes). A read: runs directly, not audited.
+	// /btminer is the older path of the same report.
+	diagnostics := func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		d, ok := store.Get(ip)
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		defer cancel()
+		res := commands.Execute(ctx, commandTarget(d), commands.KindDiagnostics, nil)
+		if !res.OK {
+			code := http.StatusBadGateway
+			if strings.HasPrefix(res.Error, "unsupported vendor") {
+				code = http.StatusNotImplemented
+			}
+			http.Error(w, res.Error, code)
+			return
+		}
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(res.Detail + "\n"))
+	}
+	r.Get("/api/devices/{ip}/diagnostics", diagnostics)
+	r.Get("/api/devices/{ip}/btminer", diagnostics)
+
+	// Miner logs: live read through the device's driver (stock Antminer CGI, Vnish, Whatsminer
+	// LuCI), classified against known failure signatures. The findings stay on the device;
+	// tail=N also returns the last N raw lines (default 200, 0 = none).
+	r.Get("/api/devices/{ip}/minerlog", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		d, ok := store.Get(ip)
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if !d.Online {
+			http.Error(w, "offline", http.StatusConflict)
+			return
+		}
+		tail := 200
+		if s := r.URL.Query().Get("tail"); s != "" {
+			if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+				tail = n
+			}
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		res := dispatcher.ReadLog(ctx, targetOf(d), commandTarget(d).Creds)
+		if !res.OK {
+			w.Header().Set("content-type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+		findings := minerlog.Classify(res.Text)
+		now := time.Now().UTC()
+		store.UpdateEnrichment(ip, func(dd *registry.Device) {
+			dd.LogFindings = findings
+			dd.LogCheckedAt = now
+		})
+		lines := strings.Split(strings.TrimRight(res.Text, "\n"), "\n")
+		out := map[string]any{
+			"ip":         ip,
+			"log":        res,
+			"lines":      len(lines),
+			"findings":   findings,
+			"checked_at": now,
+		}
+		if tail > 0 {
+			out["tail"] = lines[max(len(lines)-tail, 0):]
+		}
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	// Syslog lines received from the device (settings.syslog), newest first.
+	// Filters: q (all terms, case-insensitive), severity (max: err/warning/... or 0..7),
+	// since (RFC 3339), limit (default 200).
+	r.Get("/api/devices/{ip}/logs", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		if _, ok := store.Get(ip); !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		qv := r.URL.Query()
+		q := syslogd.Query{Text: qv.Get("q"), MaxSeverity: -1, Limit: 200}
+		if s := qv.Get("severity"); s != "" {
+			sev, ok := syslogd.ParseSeverity(s)
+			if !ok {
+				http.Error(w, "bad severity", http.StatusBadRequest)
+				return
+			}
+			q.MaxSeverity = sev
+		}
+		if s := qv.Get("since"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				http.Error(w, "bad since (RFC 3339)", http.StatusBadRequest)
+				return
+			}
+			q.Since = t
+		}
+		if n, err := strconv.Atoi(qv.Get("limit")); err == nil && n > 0 {
+			q.Limit = n
+		}
+		lines, buffered := syslogBuf.Search(ip, q)
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ip":       ip,
+			"buffered": buffered,
+			"lines":    lines,
+		})
+	})
+	// Fleet view of the stored log findings. Filters: code, severity.
+	r.Get("/api/log-findings", func(w http.ResponseWriter, r *http.Request) {
+		code := strings.TrimSpace(r.URL.Query().Get("code"))
+		sev := strings.TrimSpace(r.URL.Query().Get("severity"))
+		type row struct {
+			IP        string             `json:"ip"`
+			Model     string             `json:"model,omitempty"`
+			Worker    string             `json:"worker,omitempty"`
+			CheckedAt time.Time          `json:"checked_at"`
+			Findings  []minerlog.Finding `json:"findings"`
+		}
+		out := []row{}
+		for _, d := range store.List() {
+			var fs []minerlog.Finding
+			for _, f := range d.LogFindings {
+				if (code == "" || f.Code == code) && (sev == "" || f.Severity == sev) {
+					fs = append(fs, f)
+				}
+			}
+			if len(fs) > 0 {
+				out = append(out, row{IP: d.IP, Model: d.Model, Worker: d.Worker, CheckedAt: d.LogCheckedAt, Findings: fs})
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i].IP < out[j].IP })
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+
+	// Reboots of one device, newest first, with the rolling counters.
+	r.Get("/api/devices/{ip}/reboots", func(w http.ResponseWriter, r *http.Request) {
+		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+		if _, ok := store.Get(ip); !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		h1, h24, _ := rebootLog.Counts(ip, time.Now().UTC())
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ip":          ip,
+			"reboots_1h":  h1,
+			"reboots_24h": h24,
+			"events":      rebootLog.List(ip, limit),
+		})
+	})
+	// Frequent rebooters: at least min_1h reboots in the last hour (default 2) or min_24h in
+	// the last day (default 3), most reboots first.
+	r.Get("/api/reboots/frequent", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		min1h, min24h := 2, 3
+		if n, err := strconv.Atoi(q.Get("min_1h")); err == nil && n > 0 {
+			min1h = n
+		}
+		if n, err := strconv.Atoi(q.Get("min_24h")); err == nil && n > 0 {
+			min24h = n
+		}
+		type row struct {
+			reboots.Summary
+			Model  string `json:"model,omitempty"`
+			Worker string `json:"worker,omitempty"`
+			Online bool   `json:"online"`
+		}
+		out := []row{}
+		for _, s := range rebootLog.Frequent(time.Now().UTC(), min1h, min24h) {
+			rw := row{Summary: s}
+			if d, ok := store.Get(s.IP); ok {
+				rw.Model, rw.Worker, rw.Online = d.Model, d.Worker, d.Online
+			}
+			out = append(out, rw)
+		}
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(out)
+	})
+
+	// Command audit: newest first. Filters: ip, kind, operator, batch, limit.
+	r.Get("/api/commands", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(cmdAudit.List(commands.AuditFilter{
+			IP:       strings.TrimSpace(q.Get("ip")),
+			Kind:     strings.TrimSpace(q.Get("kind")),
+			Operator: strings.TrimSpace(q.Get("operator")),
+			BatchID:  strings.TrimSpace(q.Get("batch")),
+			Limit:    limit,
+		}))
+	})
+
+	// Control: pool config push. Pool "user" may be a template (e.g. "acc.{site}.{ip_last_octet}"),
+	// {site} defaults to the note of the address pool containing the device.
+	siteFor := func(ip string) string {
+		for _, sn := range subnetsStore.List() {
+			if netutil.SpecContains(sn.CIDR, ip) {
+				return sn.Note
+			}
+		}
+		return ""
+	}
+	r.Post("/api/pools/apply", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Selection registry.Selection `json:"selection"`
+			Pools     []commands.Pool    `json:"pools"`
+			Site      string             `json:"site"`    // overrides the per-device site
+			DryRun    bool               `json:"dry_run"` // only expand templates
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		if req.Selection.Empty() {
+			http.Error(w, "empty selection (set ips or a filter)", http.StatusBadRequest)
+			return
+		}
+		if len(req.Pools) == 0 || len(req.Pools) > 3 || strings.TrimSpace(req.Pools[0].URL) == "" {
+			http.Error(w, "pools: 1..3 entries, pool 1 url required", http.StatusBadRequest)
+			return
+		}
+		devs := store.Select(req.Selection)
+		perIP := map[string][]commands.Pool{}
+		for _, d := range devs {
+			site := strings.TrimSpace(req.Site)
+			if site == "" {
+				site = siteFor(d.IP)
+			}
+			perIP[d.IP] = commands.ExpandPools(req.Pools, commands.TemplateVars(d.IP, d.MAC, d.Model, d.Worker, site))
+		}
 
-					now := time.Now().UTC()
-					switch subj {
-					case events.NetworkDeviceDiscovered:
-						_ = store.UpsertDiscovery(shard, ip, mac, now)
-					case events.NetworkObserved:
-						payload := envMsg.GetFieldByName("network_observed").(*dynamic.Message)
-						online := payload.GetFieldByName("online").(bool)
-						_ = store.UpsertObserved(shard, ip, mac, online, now)
-					}
-					_ = m.Ack()
+		w.Header().Set("content-type", "application/json")
+		if req.DryRun {
+			type preview struct {
+				IP    string          `json:"ip"`
+				Pools []commands.Pool `json:"pools"`
+			}
+			out := make([]preview, 0, len(devs))
+			for _, d := range devs {
+				ps := perIP[d.IP]
+				masked := make([]commands.Pool, len(ps))
+				for i, p := range ps {
+					masked[i] = commands.Pool{URL: p.URL, User: p.User}
 				}
+				out = append(out, preview{IP: d.IP, Pools: masked})
 			}
-		}()
-	}
+			_ = json.NewEncoder(w).Encode(map[string]any{"preview": out})
+			return
+		}
 
-	// connect loop
+		batchID := events.NewID()
+		op := operatorOf(r)
+		reqs := make([]commands.Request, 0, len(devs))
+		for _, d := range devs {
+			reqs = append(reqs, commands.Request{
+				Kind:     commands.KindSetPools,
+				IP:       d.IP,
+				Operator: op,
+				Source:   "api",
+				BatchID:  batchID,
+				Args:     commands.PoolsArgs(perIP[d.IP]),
+			})
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Minute)
+		defer cancel()
+		out := summarize(submitCommands(ctx, reqs), batchID)
+		log.Info("pools apply", zap.String("batch", batchID), zap.String("operator", op), zap.Int("selected", len(reqs)), zap.Any("ok", out["ok"]), zap.String("pool1", req.Pools[0].URL))
+		_ = json.NewEncoder(w).Encode(out)
+	})
+
+	// Pool guard consumer: alert.raised on new/changed deviations, then the optional restore
+	// of the policy's approved pools (through the normal command queue and audit).
 	go func() {
 		for {
 			select {
 			case <-rootCtx.Done():
-				natsMu.Lock()
-				if natsClient != nil {
-					_ = natsClient.Close()
-					natsClient = nil
-				}
-				natsMu.Unlock()
 				return
-			default:
-			}
-			cfg := cfgStore.Get()
-			url := cfg.NATSURL
-			prefix := cfg.NATSPrefix
-
-			c, err := natsjs.Connect(natsjs.Config{
-				URL:     url,
-				Prefix:  prefix,
-				Timeout: 2 * time.Second,
-			})
-			if err != nil {
-				natsConnected.Store(false)
-				natsLastErr.Store(err.Error())
-				select {
-				case <-rootCtx.Done():
-					return
-				case <-time.After(2 * time.Second):
-					continue
-				case <-reconnectCh:
-					continue
+			case ev := <-poolEvents:
+				f := ev.f
+				if ev.raise {
+					parts := make([]string, 0, len(f.Violations))
+					for _, v := range f.Violations {
+						parts = append(parts, fmt.Sprintf("%s %s (%s)", v.URL, v.User, v.Reason))
+					}
+					raiseAlert(f.IP, f.Severity, "pool_unauthorized",
+						"pool outside allow-list "+f.Policy+": "+strings.Join(parts, "; "),
+						map[string]string{"policy": f.Policy, "url": f.Violations[0].URL, "user": f.Violations[0].User})
 				}
-			}
-			if err := c.EnsureStreams(); err != nil {
-				_ = c.Close()
-				natsConnected.Store(false)
-				natsLastErr.Store(err.Error())
-				select {
-				case <-rootCtx.Done():
-					return
-				case <-time.After(2 * time.Second):
+				if !f.Restore {
 					continue
-				case <-reconnectCh:
+				}
+				p, ok := poolguard.PolicyFor(cfgStore.Get().PoolPolicies, f.IP)
+				d, found := store.Get(f.IP)
+				if !ok || !found {
 					continue
 				}
-			}
-
-			natsMu.Lock()
-			if natsClient != nil {
-				_ = natsClient.Close()
-			}
-			natsClient = c
-			natsMu.Unlock()
-
-			natsConnected.Store(true)
-			natsLastErr.Store("")
-			startConsumer(c, prefix)
-
-			// wait for explicit reconnect request
-			select {
-			case <-rootCtx.Done():
-				natsConnected.Store(false)
-				natsMu.Lock()
-				if natsClient != nil {
-					_ = natsClient.Close()
-					natsClient = nil
+				approved := make([]commands.Pool, 0, len(p.Restore))
+				for _, s := range p.Restore {
+					approved = append(approved, commands.Pool{URL: s.URL, User: s.User, Pass: s.Pass})
 				}
-				natsMu.Unlock()
-				return
-			case <-reconnectCh:
+				req := commands.Request{
+					Kind:     commands.KindSetPools,
+					IP:       f.IP,
+					Operator: "poolguard",
+					Source:   "poolguard",
+					Args:     commands.PoolsArgs(commands.ExpandPools(approved, commands.TemplateVars(d.IP, d.MAC, d.Model, d.Worker, siteFor(d.IP)))),
+				}
+				go func() {
+					ctx, cancel := context.WithTimeout(rootCtx, 3*time.Minute)
+					defer cancel()
+					res := submitCommands(ctx, []commands.Request{req})
+					log.Info("pool guard restore", zap.String("ip", req.IP), zap.String("policy", f.Policy), zap.Bool("ok", res[0].OK), zap.String("error", res[0].Error))
+				}()
 			}
-			natsConnected.Store(false)
 		}
 	}()
 
-	r := chi.NewRouter()
-	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
+	// Pool allow-lists (settings.pool_policies) and the devices currently deviating.
+	r.Get("/api/pool-policies", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		ps := settings.RedactPoolPolicies(cfgStore.Get().PoolPolicies)
+		if ps == nil {
+			ps = []settings.PoolPolicy{}
+		}
+		_ = json.NewEncoder(w).Encode(ps)
 	})
-	r.Get("/api/version", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("content-type", "text/plain")
-		_, _ = w.Write([]byte(version.String()))
+	r.Put("/api/pool-policies", func(w http.ResponseWriter, r *http.Request) {
+		var ps []settings.PoolPolicy
+		if err := json.NewDecoder(r.Body).Decode(&ps); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		// GET masks restore passwords; a slot sent back masked keeps the stored one.
+		settings.KeepRedactedPasses(ps, cfgStore.Get().PoolPolicies)
+		for i := range ps {
+			p := &ps[i]
+			if strings.TrimSpace(p.ID) == "" {
+				p.ID = events.NewID()
+			}
+			if len(p.URLs) == 0 {
+				http.Error(w, "policy "+p.Name+": at least one allowed url pattern", http.StatusBadRequest)
+				return
+			}
+			if p.AutoRestore && (len(p.Restore) == 0 || len(p.Restore) > 3 || strings.TrimSpace(p.Restore[0].URL) == "") {
+				http.Error(w, "policy "+p.Name+": auto_restore needs 1..3 restore pools, pool 1 url required", http.StatusBadRequest)
+				return
+			}
+		}
+		if err := cfgStore.Patch(func(s *settings.Settings) { s.PoolPolicies = ps }); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Info("pool policies updated", zap.String("operator", operatorOf(r)), zap.Int("policies", len(ps)))
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(settings.RedactPoolPolicies(ps))
 	})
-	r.Get("/api/cidr/preview", func(w http.ResponseWriter, r *http.Request) {
+	r.Get("/api/pool-violations", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("content-type", "application/json")
-		cidr := r.URL.Query().Get("cidr")
-		_ = json.NewEncoder(w).Encode(netutil.PreviewSpec(cidr))
+		_ = json.NewEncoder(w).Encode(poolGuard.List())
 	})
-	r.Get("/api/status", func(w http.ResponseWriter, r *http.Request) {
+
+	// Automation rules (settings.rules, evaluated by cmd/automation on device.state_updated).
+	r.Get("/api/rules", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("content-type", "application/json")
-		errStr, _ := natsLastErr.Load().(string)
-		embMu.Lock()
-		embOn := emb != nil
-		embMu.Unlock()
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"nats_connected": natsConnected.Load(),
-			"nats_error":     errStr,
-			"embedded_nats":  embOn,
-			"started_at":     startedAt.Format(time.RFC3339),
-			"uptime_s":       int64(time.Since(startedAt).Seconds()),
-		})
+		rs := cfgStore.Get().Rules
+		if rs == nil {
+			rs = []settings.Rule{}
+		}
+		_ = json.NewEncoder(w).Encode(rs)
 	})
-	r.Get("/api/devices", func(w http.ResponseWriter, r *http.Request) {
+	r.Put("/api/rules", func(w http.ResponseWriter, r *http.Request) {
+		var rs []settings.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rs); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
+			return
+		}
+		ids := map[string]bool{}
+		for i := range rs {
+			rl := &rs[i]
+			if strings.TrimSpace(rl.ID) == "" {
+				rl.ID = events.NewID()
+			}
+			if ids[rl.ID] {
+				http.Error(w, "duplicate rule id "+rl.ID, http.StatusBadRequest)
+				return
+			}
+			ids[rl.ID] = true
+			if _, err := automation.Compile(*rl); err != nil {
+				http.Error(w, "rule "+rl.Name+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if rl.Command != "" && !commands.Known(rl.Command) {
+				http.Error(w, "rule "+rl.Name+": unknown command "+rl.Command, http.StatusBadRequest)
+				return
+			}
+		}
+		if err := cfgStore.Patch(func(s *settings.Settings) { s.Rules = rs }); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Info("rules updated", zap.String("operator", operatorOf(r)), zap.Int("rules", len(rs)))
 		w.Header().Set("content-type", "application/json")
-		_ = json.NewEncoder(w).Encode(store.List())
+		_ = json.NewEncoder(w).Encode(rs)
 	})
 
-	// Device details (light) + deep probe (Antminer first)
-	r.Get("/api/devices/{ip}", func(w http.ResponseWriter, r *http.Request) {
-		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
+	// Alerts: deduplicated per device+code; ack/resolve by operator; silences with expiry.
+	r.Get("/api/alerts", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f := alerts.Filter{
+			State:    strings.TrimSpace(q.Get("state")), // open/acknowledged/resolved/active
+			IP:       strings.TrimSpace(q.Get("ip")),
+			Code:     strings.TrimSpace(q.Get("code")),
+			Severity: strings.TrimSpace(q.Get("severity")),
+		}
+		if v := q.Get("silenced"); v != "" {
+			b := v == "1" || v == "true"
+			f.Silenced = &b
+		}
+		if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+			f.Limit = n
+		}
 		w.Header().Set("content-type", "application/json")
-		if d, ok := store.Get(ip); ok {
-			_ = json.NewEncoder(w).Encode(d)
+		_ = json.NewEncoder(w).Encode(alertStore.List(f, time.Now().UTC()))
+	})
+	r.Get("/api/alerts/silences", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(alertStore.Silences(time.Now().UTC()))
+	})
+	r.Post("/api/alerts/silences", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			alerts.Silence
+			Duration string `json:"duration"` // alternative to expires_at: "2h", "30m"
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad json", http.StatusBadRequest)
 			return
 		}
-		http.Error(w, "not found", http.StatusNotFound)
+		now := time.Now().UTC()
+		sl := req.Silence
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				http.Error(w, "bad duration", http.StatusBadRequest)
+				return
+			}
+			sl.ExpiresAt = now.Add(d)
+		}
+		sl.CreatedBy = operatorOf(r)
+		sl, err := alertStore.AddSilence(sl, now)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Info("alert silence added", zap.String("operator", sl.CreatedBy), zap.String("ip", sl.IP), zap.String("group", sl.Group), zap.String("code", sl.Code), zap.Time("expires_at", sl.ExpiresAt))
+		w.Header().Set("content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(sl)
 	})
-	r.Post("/api/devices/{ip}/probe", func(w http.ResponseWriter, r *http.Request) {
-		ip := strings.TrimSpace(chi.URLParam(r, "ip"))
-		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-		defer cancel()
-		res := runProbe(ctx, ip)
+	r.Delete("/api/alerts/silences/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !alertStore.DeleteSilence(chi.URLParam(r, "id")) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Get("/api/alerts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		a, ok := alertStore.Get(chi.URLParam(r, "id"), time.Now().UTC())
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
 		w.Header().Set("content-type", "application/json")
-		_ = json.NewEncoder(w).Encode(res)
+		_ = json.NewEncoder(w).Encode(a)
 	})
+	alertAction := func(fn func(id, operator string, now time.Time) (alerts.Alert, error)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			a, err := fn(chi.URLParam(r, "id"), operatorOf(r), time.Now().UTC())
+			switch {
+			case errors.Is(err, alerts.ErrNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			w.Header().Set("content-type", "application/json")
+			_ = json.NewEncoder(w).Encode(a)
+		}
+	}
+	r.Post("/api/alerts/{id}/ack", alertAction(alertStore.Ack))
+	r.Post("/api/alerts/{id}/resolve", alertAction(alertStore.Resolve))
 
 	// Open miner UI with auto-login (best-effort).
 	// Uses the last successful credential for the device (AuthStatus==ok).
@@ -907,7 +2133,7 @@ func main() {
 	// Settings
 	r.Get("/api/settings", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("content-type", "application/json")
-		_ = json.NewEncoder(w).Encode(cfgStore.Get())
+		_ = json.NewEncoder(w).Encode(cfgStore.Get().Redacted())
 	})
 	r.Put("/api/settings", func(w http.ResponseWriter, r *http.Request) {
 		var s settings.Settings
@@ -915,9 +2141,11 @@ func main() {
 			http.Error(w, "bad json", http.StatusBadRequest)
 			return
 		}
-		// Settings UI does not edit credentials; never allow wiping them.
+		// Settings UI does not edit credentials, pool policies or rules; never allow wiping them.
 		prev := cfgStore.Get()
 		s.Credentials = prev.Credentials
+		s.PoolPolicies = prev.PoolPolicies
+		s.Rules = prev.Rules
 		// basic normalization/defaults
 		if s.Version == 0 {
 			s.Version = 1
@@ -953,6 +2181,10 @@ func main() {
 		if s.Scanner.HTTPTimeout <= 0 {
 			s.Scanner.HTTPTimeout = 1 * time.Second
 		}
+		if _, err := automation.ParseQuietHours(s.Remediation.QuietHours); err != nil {
+			http.Error(w, "remediation: "+err.Error(), http.StatusBadRequest)
+			return
+		}
 		// Keep TryDefaultCreds as provided (bool).
 		if err := cfgStore.Update(s); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -960,9 +2192,10 @@ func main() {
 		}
 		// Apply embedded NATS changes immediately (best-effort).
 		startEmbedded(s)
+		startSyslog(s)
 		requestReconnect()
 		w.Header().Set("content-type", "application/json")
-		_ = json.NewEncoder(w).Encode(cfgStore.Get())
+		_ = json.NewEncoder(w).Encode(cfgStore.Get().Redacted())
 	})
 
 	// Exit (for junior ops: "two clicks": open UI -> Settings -> Exit)
@@ -1336,6 +2569,45 @@ func main() {
 		}
 	})
 
+	// Alert stream: the active alerts (with silenced_by) on every change.
+	r.Get("/api/stream/alerts", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("content-type", "text/event-stream")
+		w.Header().Set("cache-control", "no-cache")
+		w.Header().Set("connection", "keep-alive")
+
+		ctx := r.Context()
+		ch := alertStore.Subscribe(ctx)
+
+		send := func() {
+			b, _ := json.Marshal(alertStore.List(alerts.Filter{State: "active"}, time.Now().UTC()))
+			_, _ = fmt.Fprintf(w, "event: alerts\ndata: %s\n\n", b)
+			flusher.Flush()
+		}
+
+		send()
+
+		heartbeat := time.NewTicker(15 * time.Second)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				send()
+			case <-heartbeat.C:
+				_, _ = fmt.Fprint(w, "event: ping\ndata: 1\n\n")
+				flusher.Flush()
+			}
+		}
+	})
+
 	r.Get("/api/stream/subnets", func(w http.ResponseWriter, r *http.Request) {
 		flusher, ok := w.(http.Flusher)
 		if !ok {
@@ -1419,6 +2691,14 @@ func main() {
 	}
 	natsMu.Unlock()
 
+	// Stop syslog
+	syslogMu.Lock()
+	if syslogSrv != nil {
+		syslogSrv.Close()
+		syslogSrv = nil
+	}
+	syslogMu.Unlock()
+
 	// Stop embedded NATS
 	embMu.Lock()
 	if emb != nil {
@@ -1431,6 +2711,14 @@ func main() {
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	_ = srv.Shutdown(ctxTimeout)
 	cancel()
+
+	// Persist registry (compact WAL into snapshot)
+	if err := store.Close(); err != nil {
+		log.Warn("registry close", zap.Error(err))
+	}
+	_ = cmdAudit.Close()
+	_ = rebootLog.Close()
+	_ = alertStore.Close()
 }
 
 func listenWithFallback(addr string) (net.Listener, string, error) {
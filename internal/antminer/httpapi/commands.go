@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// CommandResult is the outcome of a write/control call against the stock CGI API.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	Scheme   string `json:"scheme,omitempty"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"` // truncated response body (debug)
+}
+
+// newCommandClient is the shared session client with a per-call-kind timeout (no cookies:
+// the CGI authenticates every request).
+func newCommandClient(timeout time.Duration) *http.Client {
+	c := sessionutil.NewClient(false)
+	c.Timeout = timeout
+	return c
+}
+
+var errUnauthorized = errors.New("unauthorized")
+
+// doAuthed performs a request with Basic auth and retries once with Digest on 401
+// (same logic as the probe path; lighttpd on stock firmware usually wants Digest).
+func doAuthed(ctx context.Context, client *http.Client, method, scheme, host, path string, body []byte, contentType string, cred Cred) (int, []byte, error) {
+	send := func(authz string, basic bool) (*http.Response, []byte, error) {
+		var rd io.Reader
+		if body != nil {
+			rd = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+host+path, rd)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Close = true
+		req.Header.Set("Connection", "close")
+		req.Header.Set("User-Agent", "MonA/asic-control")
+		req.Header.Set("Accept", "application/json,text/plain;q=0.9,*/*;q=0.8")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if basic && (cred.Username != "" || cred.Password != "") {
+			req.SetBasicAuth(cred.Username, cred.Password)
+		}
+		if authz != "" {
+			req.Header.Set("Authorization", authz)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 256*1024))
+		_ = resp.Body.Close()
+		return resp, b, nil
+	}
+
+	resp, b, err := send("", true)
+	if err != nil {
+		return 0, nil, err
+	}
+	if resp.StatusCode == 401 {
+		ch, ok := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+		if !ok || cred.Username == "" {
+			return resp.StatusCode, b, errUnauthorized
+		}
+		resp2, b2, err := send(buildDigestAuth(cred.Username, cred.Password, method, path, ch), false)
+		if err != nil {
+			return 0, nil, err
+		}
+		if resp2.StatusCode == 401 {
+			return resp2.StatusCode, b2, errUnauthorized
+		}
+		return resp2.StatusCode, b2, nil
+	}
+	return resp.StatusCode, b, nil
+}
+
+// Reboot triggers /cgi-bin/reboot.cgi on stock Antminer firmware. All creds/schemes are tried
+// until one is accepted.
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	client := newCommandClient(5 * time.Second)
+	last := CommandResult{OK: false, Error: "no credentials accepted"}
+	for _, c := range creds {
+		for _, scheme := range schemes {
+			scheme = strings.ToLower(strings.TrimSpace(scheme))
+			if scheme != "http" && scheme != "https" {
+				continue
+			}
+			if ctx.Err() != nil {
+				last.Error = ctx.Err().Error()
+				return last
+			}
+			code, b, err := doAuthed(ctx, client, "GET", scheme, host, "/cgi-bin/reboot.cgi", nil, "", c)
+			out := CommandResult{Scheme: scheme, UsedCred: c.Name, Body: sessionutil.TruncBody(b)}
+			switch {
+			case errors.Is(err, errUnauthorized):
+				out.Error = "unauthorized"
+			case sessionutil.IsDropAfterSend(err):
+				out.OK = true
+				return out
+			case err != nil:
+				out.Error = err.Error()
+			case code >= 200 && code <= 299:
+				out.OK = true
+				return out
+			default:
+				out.Error = "http " + http.StatusText(code)
+			}
+			last = out
+		}
+	}
+	return last
+}
@@ -1,8 +1,12 @@
 package httpapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
 )
 
 type Facts struct {
@@ -14,6 +18,8 @@ type Facts struct {
 	HashrateTHS float64
 	FansRPM     []int
 	TempsC      []float64
+	Chains      []sdk.Chain
+	Pools       []sdk.PoolStatus
 }
 
 // ExtractFacts tries to pull common fields out of Antminer /cgi-bin JSON responses.
@@ -101,6 +107,9 @@ func ExtractFacts(res ProbeResult) Facts {
 						f.HashrateTHS = x / 1000.0
 					case strings.Contains(unit, "th"):
 						f.HashrateTHS = x
+					case strings.Contains(unit, "mh"):
+						// Scrypt machines (L7, Elphapex DG1) report MH/s
+						f.HashrateTHS = x / 1e6
 					default:
 						// assume GH/s by default for this field
 						f.HashrateTHS = x / 1000.0
@@ -138,6 +147,12 @@ func ExtractFacts(res ProbeResult) Facts {
 				statMaps = []map[string]any{m}
 			}
 
+			entries := make([]cgminer.StatsEntry, 0, len(statMaps))
+			for _, sm := range statMaps {
+				entries = append(entries, cgminer.StatsEntry(sm))
+			}
+			f.Chains = cgminer.Chains(entries)
+
 			fans := map[int]int{}
 			temps := map[int]float64{}
 			for _, sm := range statMaps {
@@ -192,6 +207,12 @@ func ExtractFacts(res ProbeResult) Facts {
 		}
 	}
 
+	// pools.cgi: {"POOLS":[{"url","user","status","priority","accepted","stale","diff","lstime"...}]}
+	// (cgminer pool keys in lower case; lstime is "h:mm:ss" since the last share)
+	if anyv, ok := res.Responses["/cgi-bin/pools.cgi"].(map[string]any); ok {
+		f.Pools = poolStatuses(anyv["POOLS"])
+	}
+
 	return f
 }
 
@@ -371,3 +392,24 @@ func toF64(v any) float64 {
 	}
 }
 
+func poolStatuses(v any) []sdk.PoolStatus {
+	maps := collectMaps(v)
+	if len(maps) == 0 {
+		return nil
+	}
+	// field names match cgminer's case-insensitively, except the last share time
+	b, err := json.Marshal(maps)
+	if err != nil {
+		return nil
+	}
+	var ps []cgminer.Pool
+	if json.Unmarshal(b, &ps) != nil {
+		return nil
+	}
+	for i, m := range maps {
+		if i < len(ps) && ps[i].LastShareTime == "" {
+			ps[i].LastShareTime = cgminer.Text(pickString(m, "lstime", "last_share_time"))
+		}
+	}
+	return cgminer.PoolStatuses(ps)
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"asic-control/internal/collectors/sdk"
 )
 
 type ProbeResult struct {
@@ -20,11 +22,8 @@ type ProbeResult struct {
 	Raw       map[string]string `json:"raw,omitempty"`       // endpoint -> raw (truncated)
 }
 
-type Cred struct {
-	Name     string
-	Username string
-	Password string
-}
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
 
 // ProbeAntminerSchemes probes Antminer HTTP CGI endpoints using the given schemes (http/https)
 // and tries all credentials. It returns the "best" successful result (most parsable facts),
@@ -39,6 +38,7 @@ func ProbeAntminerSchemes(ctx context.Context, host string, creds []Cred, scheme
 		"/cgi-bin/get_system_info.cgi",
 		"/cgi-bin/summary.cgi",
 		"/cgi-bin/stats.cgi",
+		"/cgi-bin/pools.cgi",
 	}
 
 	// Disable keep-alives to avoid:
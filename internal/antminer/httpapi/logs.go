@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LogResult is the text of the stock firmware logs, each source introduced by "==> path <==".
+type LogResult struct {
+	OK       bool     `json:"ok"`
+	Scheme   string   `json:"scheme,omitempty"`
+	UsedCred string   `json:"used_cred,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Text     string   `json:"-"`
+}
+
+// logPaths are the kernel log (dmesg incl. bmminer chain init) and the miner log.
+var logPaths = []string{"/cgi-bin/get_kernel_log.cgi", "/cgi-bin/log.cgi"}
+
+// ReadLogs fetches the kernel and miner logs. The first credential/scheme that reads at
+// least one of them wins; a missing log.cgi (older builds) is not an error.
+func ReadLogs(ctx context.Context, host string, creds []Cred, schemes []string) LogResult {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	client := newCommandClient(10 * time.Second)
+	last := LogResult{Error: "no credentials accepted"}
+	for _, c := range creds {
+		for _, scheme := range schemes {
+			scheme = strings.ToLower(strings.TrimSpace(scheme))
+			if scheme != "http" && scheme != "https" {
+				continue
+			}
+			out := LogResult{Scheme: scheme, UsedCred: c.Name}
+			var sb strings.Builder
+			for _, p := range logPaths {
+				if ctx.Err() != nil {
+					last.Error = ctx.Err().Error()
+					return last
+				}
+				code, b, err := doAuthed(ctx, client, "GET", scheme, host, p, nil, "", c)
+				switch {
+				case errors.Is(err, errUnauthorized):
+					out.Error = "unauthorized"
+				case err != nil:
+					out.Error = err.Error()
+				case code >= 200 && code <= 299:
+					out.Sources = append(out.Sources, p)
+					sb.WriteString("==> " + p + " <==\n")
+					sb.WriteString(strings.TrimRight(string(b), "\n") + "\n")
+				default:
+					out.Error = "http " + http.StatusText(code)
+				}
+				if out.Error == "unauthorized" {
+					break
+				}
+			}
+			if len(out.Sources) > 0 {
+				out.OK, out.Error, out.Text = true, "", sb.String()
+				return out
+			}
+			last = out
+		}
+	}
+	return last
+}
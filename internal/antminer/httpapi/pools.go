@@ -0,0 +1,188 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Pool is one pool slot (stock firmware has exactly 3).
+type Pool = sdk.Pool
+
+// SetPools writes pools 1..3 via /cgi-bin/set_miner_conf.cgi, keeping the rest of the current
+// miner config (fan/freq/mode) as returned by get_miner_conf.cgi.
+//
+// Newer stock builds (2019+) take a JSON body; older ones take the _ant_* form. JSON is tried
+// first and the form is used when JSON is rejected.
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	if len(pools) == 0 {
+		return CommandResult{OK: false, Error: "no pools"}
+	}
+	for len(pools) < 3 {
+		pools = append(pools, Pool{})
+	}
+	pools = pools[:3]
+
+	client := newCommandClient(15 * time.Second)
+	last := CommandResult{OK: false, Error: "no credentials accepted"}
+	for _, c := range creds {
+		for _, scheme := range schemes {
+			scheme = strings.ToLower(strings.TrimSpace(scheme))
+			if scheme != "http" && scheme != "https" {
+				continue
+			}
+			if ctx.Err() != nil {
+				last.Error = ctx.Err().Error()
+				return last
+			}
+			code, b, err := doAuthed(ctx, client, "GET", scheme, host, "/cgi-bin/get_miner_conf.cgi", nil, "", c)
+			out := CommandResult{Scheme: scheme, UsedCred: c.Name, Body: sessionutil.TruncBody(b)}
+			switch {
+			case errors.Is(err, errUnauthorized):
+				out.Error = "unauthorized"
+				last = out
+				continue
+			case err != nil:
+				out.Error = err.Error()
+				last = out
+				continue
+			case code < 200 || code > 299:
+				out.Error = "get_miner_conf: http " + http.StatusText(code)
+				last = out
+				continue
+			}
+			conf := map[string]any{}
+			if err := json.Unmarshal([]byte(sanitizeConf(b)), &conf); err != nil {
+				out.Error = "get_miner_conf: not json (not stock firmware?)"
+				return out
+			}
+
+			// JSON variant
+			body, _ := json.Marshal(minerConfJSON(conf, pools))
+			code, b, err = doAuthed(ctx, client, "POST", scheme, host, "/cgi-bin/set_miner_conf.cgi", body, "application/json", c)
+			out.Body = sessionutil.TruncBody(b)
+			if err == nil && code >= 200 && code <= 299 && setConfAccepted(b) {
+				out.OK = true
+				return out
+			}
+
+			// legacy form variant
+			form := minerConfForm(conf, pools)
+			code, b, err = doAuthed(ctx, client, "POST", scheme, host, "/cgi-bin/set_miner_conf.cgi", []byte(form.Encode()), "application/x-www-form-urlencoded", c)
+			out.Body = sessionutil.TruncBody(b)
+			switch {
+			case sessionutil.IsDropAfterSend(err):
+				// cgminer restart sometimes takes lighttpd with it
+				out.OK = true
+			case err != nil:
+				out.Error = err.Error()
+			case code >= 200 && code <= 299 && setConfAccepted(b):
+				out.OK = true
+			default:
+				out.Error = "set_miner_conf: http " + http.StatusText(code)
+			}
+			return out
+		}
+	}
+	return last
+}
+
+// sanitizeConf strips junk before the JSON object (some builds prepend whitespace/HTML comments).
+func sanitizeConf(b []byte) string {
+	s := string(b)
+	if i := strings.Index(s, "{"); i >= 0 {
+		return s[i:]
+	}
+	return s
+}
+
+// setConfAccepted checks the body for an explicit failure. Success bodies differ per build
+// ({"stats":"success"}, "ok", empty), so anything that is not an error counts.
+func setConfAccepted(b []byte) bool {
+	var m map[string]any
+	if json.Unmarshal([]byte(sanitizeConf(b)), &m) == nil {
+		if st, ok := m["stats"].(string); ok {
+			return strings.EqualFold(st, "success")
+		}
+		return true
+	}
+	s := strings.ToLower(strings.TrimSpace(string(b)))
+	return !strings.Contains(s, "error") && !strings.Contains(s, "fail") && !strings.Contains(s, "<html")
+}
+
+func confString(conf map[string]any, key, def string) string {
+	switch v := conf[key].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%v", v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	}
+	return def
+}
+
+func confBool(conf map[string]any, key string) bool {
+	switch v := conf[key].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "1"
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+func minerConfJSON(conf map[string]any, pools []Pool) map[string]any {
+	mode := 0
+	if _, err := fmt.Sscanf(confString(conf, "bitmain-work-mode", "0"), "%d", &mode); err != nil {
+		mode = 0
+	}
+	ps := make([]map[string]string, 0, len(pools))
+	for _, p := range pools {
+		ps = append(ps, map[string]string{"url": p.URL, "user": p.User, "pass": p.Pass})
+	}
+	return map[string]any{
+		"bitmain-fan-ctrl": confBool(conf, "bitmain-fan-ctrl"),
+		"bitmain-fan-pwm":  confString(conf, "bitmain-fan-pwm", "100"),
+		"freq-level":       confString(conf, "bitmain-freq-level", "100"),
+		"miner-mode":       mode,
+		"pools":            ps,
+	}
+}
+
+func minerConfForm(conf map[string]any, pools []Pool) url.Values {
+	f := url.Values{}
+	for i, p := range pools {
+		n := fmt.Sprintf("%d", i+1)
+		f.Set("_ant_pool"+n+"url", p.URL)
+		f.Set("_ant_pool"+n+"user", p.User)
+		f.Set("_ant_pool"+n+"pw", p.Pass)
+	}
+	f.Set("_ant_nobeeper", "false")
+	f.Set("_ant_notempoverctrl", "false")
+	if confBool(conf, "bitmain-fan-ctrl") {
+		f.Set("_ant_fan_customize_switch", "true")
+	} else {
+		f.Set("_ant_fan_customize_switch", "false")
+	}
+	f.Set("_ant_fan_customize_value", confString(conf, "bitmain-fan-pwm", ""))
+	f.Set("_ant_freq", confString(conf, "bitmain-freq", ""))
+	f.Set("_ant_voltage", confString(conf, "bitmain-voltage", ""))
+	return f
+}
@@ -0,0 +1,155 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/events"
+)
+
+// Source marks commands requested by rules (command.request source, audit).
+const Source = "automation"
+
+// DecodeState reads a device.state_updated envelope.
+func DecodeState(schema *events.Schema, b []byte) (State, error) {
+	env, err := events.UnmarshalEnvelope(schema, b)
+	if err != nil {
+		return State{}, err
+	}
+	su, ok := env.GetFieldByName("device_state_updated").(*dynamic.Message)
+	if !ok || su == nil {
+		return State{}, errors.New("device.state_updated: missing payload")
+	}
+	meta := sdk.StringMap(su.GetFieldByName("meta"))
+	s := State{
+		At:          time.UnixMilli(env.GetFieldByName("ts_unix_ms").(int64)).UTC(),
+		IP:          su.GetFieldByName("ip").(string),
+		DeviceID:    su.GetFieldByName("device_id").(string),
+		Online:      su.GetFieldByName("online").(bool),
+		HashrateTHS: su.GetFieldByName("hashrate_ths").(float64),
+		TempMaxC:    su.GetFieldByName("temp_max_c").(float64),
+		FanMaxRPM:   float64(su.GetFieldByName("fan_rpm_max").(uint32)),
+		PowerW:      float64(su.GetFieldByName("power_w").(uint32)),
+		UptimeS:     float64(su.GetFieldByName("uptime_s").(uint64)),
+		Reboots1h:   float64(su.GetFieldByName("reboot_count_1h").(uint32)),
+		Vendor:      meta["vendor"],
+		Model:       meta["model"],
+		Firmware:    meta["firmware"],
+		Worker:      meta["worker"],
+		Auth:        meta["auth"],
+	}
+	s.Fans, _ = strconv.ParseFloat(meta["fans"], 64)
+	s.FanMinRPM, _ = strconv.ParseFloat(meta["fan_rpm_min"], 64)
+	if v, err := strconv.ParseFloat(meta["chains"], 64); err == nil {
+		s.Chains, s.HasChains = v, true
+	}
+	if s.IP == "" {
+		s.IP = env.GetFieldByName("ip").(string)
+	}
+	if s.IP == "" {
+		return State{}, errors.New("device.state_updated: empty ip")
+	}
+	return s, nil
+}
+
+// EncodeAlert builds the alert.raised of t. Tags carry the rule and whether it is firing or
+// resolved (a clear is sent with the rule's severity so consumers can match it to the alert).
+func EncodeAlert(schema *events.Schema, t Transition) ([]byte, error) {
+	r, s := t.Rule, t.State
+	state, msg := "resolved", ruleLabel(r)+": cleared"
+	if t.Firing {
+		state, msg = "firing", ruleLabel(r)+": "+r.Describe(s, t.Value)
+	}
+	tags := map[string]string{
+		"rule":  r.ID,
+		"state": state,
+		"since": t.Since.Format(time.RFC3339),
+	}
+	if r.Name != "" {
+		tags["rule_name"] = r.Name
+	}
+	if t.Firing && !r.Cond.Offline {
+		tags["metric"] = r.Cond.Metric
+		tags["value"] = fmtNum(t.Value)
+	}
+
+	env := schema.NewEnvelope(events.AlertRaised)
+	env.SetFieldByName("shard_id", Source)
+	env.SetFieldByName("device_id", s.DeviceID)
+	env.SetFieldByName("ip", s.IP)
+	ar := dynamic.NewMessage(schema.AlertRaised)
+	ar.SetFieldByName("device_id", s.DeviceID)
+	ar.SetFieldByName("severity", r.Severity)
+	ar.SetFieldByName("code", r.Code)
+	ar.SetFieldByName("message", msg)
+	ar.SetFieldByName("tags", tags)
+	env.SetFieldByName("alert_raised", ar)
+	return events.Marshal(env)
+}
+
+// EncodeEscalation builds the remediation_exhausted alert of a reboot the guard refused (or
+// its clear, when e.Firing is false).
+func EncodeEscalation(schema *events.Schema, e Escalation) ([]byte, error) {
+	r, s := e.Rule, e.State
+	state, sev := "resolved", "crit"
+	msg := ruleLabel(r) + ": cleared, reboot escalation closed"
+	if e.Firing {
+		state = "firing"
+		msg = fmt.Sprintf("%s: %d reboots in the last hour (max %d), not rebooting; needs hands-on", ruleLabel(r), e.Used, e.Max)
+	}
+	tags := map[string]string{
+		"rule":  r.ID,
+		"state": state,
+		"since": e.Since.Format(time.RFC3339),
+	}
+	if e.Firing {
+		tags["reboots_1h"] = strconv.Itoa(e.Used)
+		tags["max_reboots_per_hour"] = strconv.Itoa(e.Max)
+	}
+
+	env := schema.NewEnvelope(events.AlertRaised)
+	env.SetFieldByName("shard_id", Source)
+	env.SetFieldByName("device_id", s.DeviceID)
+	env.SetFieldByName("ip", s.IP)
+	ar := dynamic.NewMessage(schema.AlertRaised)
+	ar.SetFieldByName("device_id", s.DeviceID)
+	ar.SetFieldByName("severity", sev)
+	ar.SetFieldByName("code", CodeRemediationExhausted)
+	ar.SetFieldByName("message", msg)
+	ar.SetFieldByName("tags", tags)
+	env.SetFieldByName("alert_raised", ar)
+	return events.Marshal(env)
+}
+
+// EncodeCommand builds the command.request of a firing rule; core executes and audits it.
+func EncodeCommand(schema *events.Schema, t Transition) ([]byte, error) {
+	r, s := t.Rule, t.State
+	env := schema.NewEnvelope(events.CommandRequest)
+	env.SetFieldByName("shard_id", Source)
+	env.SetFieldByName("device_id", s.DeviceID)
+	env.SetFieldByName("ip", s.IP)
+	cr := dynamic.NewMessage(schema.CommandRequest)
+	cr.SetFieldByName("command_id", events.NewID())
+	cr.SetFieldByName("kind", r.Command)
+	cr.SetFieldByName("ip", s.IP)
+	cr.SetFieldByName("operator", "rule:"+ruleLabel(r))
+	cr.SetFieldByName("source", Source)
+	cr.SetFieldByName("created_unix_ms", time.Now().UnixMilli()) // core applies its default deadline
+	if len(r.CommandArgs) > 0 {
+		cr.SetFieldByName("args", r.CommandArgs)
+	}
+	env.SetFieldByName("command_request", cr)
+	return events.Marshal(env)
+}
+
+func ruleLabel(r Rule) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.ID
+}
@@ -0,0 +1,140 @@
+package automation
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Transition is a rule starting or stopping to fire for one device.
+type Transition struct {
+	Rule   Rule
+	State  State
+	Value  float64   // compared metric when firing
+	Since  time.Time // when the condition started to hold
+	Firing bool      // false: the condition cleared (or the rule/scope no longer applies)
+}
+
+type episode struct {
+	since   time.Time
+	hold    time.Time // hold time counts from here (since, or the re-arm)
+	fired   bool
+	rearmed bool // fired, then re-armed: fires again once the hold time elapses anew
+	rule    Rule // as it fired (the clear message uses it even if the rule is gone)
+}
+
+// Engine keeps the last state of every device and the open episodes (condition holding,
+// maybe not yet for the rule's hold time) of every rule and device.
+type Engine struct {
+	mu      sync.Mutex
+	rules   []Rule
+	devices map[string]State
+	open    map[string]map[string]*episode // ip -> rule id -> episode
+}
+
+func NewEngine() *Engine {
+	return &Engine{devices: map[string]State{}, open: map[string]map[string]*episode{}}
+}
+
+// SetRules replaces the rule set (disabled rules are ignored). Episodes of rules that are
+// gone clear on the next evaluation.
+func (e *Engine) SetRules(rs []Rule) {
+	out := make([]Rule, 0, len(rs))
+	for _, r := range rs {
+		if r.Enabled {
+			out = append(out, r)
+		}
+	}
+	e.mu.Lock()
+	e.rules = out
+	e.mu.Unlock()
+}
+
+// Observe records a new device state and evaluates it at its event time (so a replayed
+// backlog does not fire rules that held only in the past).
+func (e *Engine) Observe(s State) []Transition {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if prev, ok := e.devices[s.IP]; ok && s.At.Before(prev.At) {
+		return nil
+	}
+	e.devices[s.IP] = s
+	return e.evalLocked(s, s.At)
+}
+
+// Tick evaluates every device at now: hold times elapse without new events (a device that
+// went offline sends nothing more).
+func (e *Engine) Tick(now time.Time) []Transition {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ips := make([]string, 0, len(e.devices))
+	for ip := range e.devices {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	var out []Transition
+	for _, ip := range ips {
+		out = append(out, e.evalLocked(e.devices[ip], now)...)
+	}
+	return out
+}
+
+// Rearm restarts the hold time of the fired episode of t (its reboot is over). If the
+// condition still holds once the hold time elapses again, the rule fires again and its command
+// repeats; if it clears meanwhile, the usual clear follows.
+func (e *Engine) Rearm(t Transition, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ep := e.open[t.State.IP][t.Rule.ID]
+	if ep == nil || !ep.fired || !ep.since.Equal(t.Since) {
+		return // cleared (or a new episode) meanwhile
+	}
+	ep.rearmed, ep.hold = true, now
+}
+
+func (e *Engine) evalLocked(s State, now time.Time) []Transition {
+	var out []Transition
+	eps := e.open[s.IP]
+	seen := map[string]bool{}
+	for _, r := range e.rules {
+		seen[r.ID] = true
+		ep := eps[r.ID]
+		ok, v := false, 0.0
+		if r.Covers(s) {
+			ok, v = r.Cond.Eval(s)
+		}
+		if !ok {
+			if ep != nil {
+				delete(eps, r.ID)
+				if ep.fired {
+					out = append(out, Transition{Rule: ep.rule, State: s, Since: ep.since})
+				}
+			}
+			continue
+		}
+		if ep == nil {
+			if eps == nil {
+				eps = map[string]*episode{}
+				e.open[s.IP] = eps
+			}
+			ep = &episode{since: s.At, hold: s.At}
+			eps[r.ID] = ep
+		}
+		if (!ep.fired || ep.rearmed) && now.Sub(ep.hold) >= r.Cond.For {
+			ep.fired, ep.rearmed, ep.rule = true, false, r
+			out = append(out, Transition{Rule: r, State: s, Value: v, Since: ep.since, Firing: true})
+		}
+	}
+	for id, ep := range eps {
+		if !seen[id] {
+			delete(eps, id)
+			if ep.fired {
+				out = append(out, Transition{Rule: ep.rule, State: s, Since: ep.since})
+			}
+		}
+	}
+	if len(eps) == 0 {
+		delete(e.open, s.IP)
+	}
+	return out
+}
@@ -0,0 +1,255 @@
+package automation
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asic-control/internal/netutil"
+	"asic-control/internal/settings"
+)
+
+// Remediation defaults for zero settings.Remediation fields.
+const (
+	DefaultMaxRebootsPerHour = 2
+	DefaultMaxConcurrent     = 3
+)
+
+// CodeRemediationExhausted is the alert raised instead of a reboot once a device used up its
+// reboot budget.
+const CodeRemediationExhausted = "remediation_exhausted"
+
+// CommandReboot is the command kind the guard holds back (commands.KindReboot).
+const CommandReboot = "reboot"
+
+// rebootTimeout frees the concurrency slot of a reboot whose device never came back.
+const rebootTimeout = 15 * time.Minute
+
+// QuietHours is a daily local-time window without automatic reboots; it may wrap midnight.
+type QuietHours struct {
+	From, To int // minutes after midnight
+}
+
+// ParseQuietHours reads "HH:MM-HH:MM" ("22:00-06:00"); empty means none.
+func ParseQuietHours(s string) (*QuietHours, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	a, b, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("quiet hours %q: want HH:MM-HH:MM", s)
+	}
+	from, err := clock(a)
+	if err != nil {
+		return nil, err
+	}
+	to, err := clock(b)
+	if err != nil {
+		return nil, err
+	}
+	return &QuietHours{From: from, To: to}, nil
+}
+
+func clock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hh < 0 || hh > 24 || mm < 0 || mm > 59 || hh*60+mm > 24*60 {
+		return 0, fmt.Errorf("bad time %q (HH:MM)", s)
+	}
+	return hh*60 + mm, nil
+}
+
+// Contains reports whether t (in its own location) falls inside the window.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil || q.From == q.To {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if q.From < q.To {
+		return m >= q.From && m < q.To
+	}
+	return m >= q.From || m < q.To
+}
+
+// PoolOf names the address pool a device belongs to for the concurrency limit: the first
+// saved address pool (settings subnets, scanned or not) containing ip, else its /24.
+func PoolOf(subnets []settings.Subnet, ip string) string {
+	for _, sn := range subnets {
+		if netutil.SpecContains(sn.CIDR, ip) {
+			return sn.CIDR
+		}
+	}
+	if v4 := net.ParseIP(ip).To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.0/24", v4[0], v4[1], v4[2])
+	}
+	return ip
+}
+
+// Escalation is a reboot the guard refused for good: the device is out of budget.
+type Escalation struct {
+	Transition
+	Used, Max int // reboots in the last hour and the budget
+}
+
+// Guard holds back the reboots requested by firing rules: at most MaxRebootsPerHour per
+// device (any cause, from reboot_count_1h, plus the ones it issued itself), at most
+// MaxConcurrent in progress per address pool, none in quiet hours. A reboot that may not run
+// now waits for the next Due while its rule keeps firing; one that exceeds the budget is
+// escalated instead. A reboot stays tracked until the device is back (or rebootTimeout):
+// Finished then hands its transition back so the engine re-arms the rule, and a device that
+// still fails gets the next reboot or the escalation.
+type Guard struct {
+	mu        sync.Mutex
+	last      map[string]State
+	pending   map[string]Transition   // ip|rule -> firing reboot rule
+	escalated map[string]Transition   // ip|rule -> escalation still open
+	issued    map[string][]time.Time  // ip -> reboots sent in the last hour
+	inflight  map[string]inflightBoot // ip -> reboot in progress
+	finished  []Transition            // reboots over, not yet taken by Finished
+}
+
+type inflightBoot struct {
+	pool string
+	at   time.Time
+	t    Transition
+}
+
+func NewGuard() *Guard {
+	return &Guard{
+		last:      map[string]State{},
+		pending:   map[string]Transition{},
+		escalated: map[string]Transition{},
+		issued:    map[string][]time.Time{},
+		inflight:  map[string]inflightBoot{},
+	}
+}
+
+func pendingKey(t Transition) string { return t.State.IP + "|" + t.Rule.ID }
+
+// Observe tracks the latest state of a device; one that is back online and booted after the
+// reboot was sent (uptime shorter than the time since) has finished it and frees its slot.
+func (g *Guard) Observe(s State) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.last[s.IP] = s
+	if b, ok := g.inflight[s.IP]; ok && s.Online && s.At.After(b.at) && s.UptimeS > 0 && s.UptimeS < s.At.Sub(b.at).Seconds() {
+		delete(g.inflight, s.IP)
+		g.finished = append(g.finished, b.t)
+	}
+}
+
+// Finished returns the reboots that are over (device back, or gone past rebootTimeout)
+// since the last call. Their rules are to be re-armed (Engine.Rearm).
+func (g *Guard) Finished() []Transition {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := g.finished
+	g.finished = nil
+	return out
+}
+
+// Submit queues the reboot of a firing rule, or drops it when the rule cleared. The cleared
+// escalation, if one was raised for this episode, is returned so it can be resolved.
+func (g *Guard) Submit(t Transition) (Escalation, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	k := pendingKey(t)
+	if t.Firing {
+		g.pending[k] = t
+		return Escalation{}, false
+	}
+	delete(g.pending, k)
+	if e, ok := g.escalated[k]; ok {
+		delete(g.escalated, k)
+		e.Firing = false
+		e.State = t.State
+		return Escalation{Transition: e}, true
+	}
+	return Escalation{}, false
+}
+
+// Due decides the pending reboots at now: the ones to send (marked as in progress) and the
+// ones to escalate. The rest keep waiting.
+func (g *Guard) Due(cfg settings.Remediation, subnets []settings.Subnet, now time.Time) (reboot []Transition, escalate []Escalation) {
+	maxPerHour, maxConc := cfg.MaxRebootsPerHour, cfg.MaxConcurrent
+	if maxPerHour <= 0 {
+		maxPerHour = DefaultMaxRebootsPerHour
+	}
+	if maxConc <= 0 {
+		maxConc = DefaultMaxConcurrent
+	}
+	quiet, _ := ParseQuietHours(cfg.QuietHours)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	busy := map[string]int{}
+	for ip, b := range g.inflight {
+		if now.Sub(b.at) >= rebootTimeout {
+			delete(g.inflight, ip)
+			g.finished = append(g.finished, b.t)
+			continue
+		}
+		busy[b.pool]++
+	}
+	for ip, ts := range g.issued {
+		for len(ts) > 0 && now.Sub(ts[0]) >= time.Hour {
+			ts = ts[1:]
+		}
+		if len(ts) == 0 {
+			delete(g.issued, ip)
+		} else {
+			g.issued[ip] = ts
+		}
+	}
+
+	// oldest episode first, so a busy pool works through its queue in order
+	keys := make([]string, 0, len(g.pending))
+	for k := range g.pending {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := g.pending[keys[i]], g.pending[keys[j]]
+		if !a.Since.Equal(b.Since) {
+			return a.Since.Before(b.Since)
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		t := g.pending[k]
+		ip := t.State.IP
+		if _, ok := g.inflight[ip]; ok {
+			continue // another rule already rebooted it; wait for the result
+		}
+		s, ok := g.last[ip]
+		if !ok {
+			s = t.State
+		}
+		used := max(int(s.Reboots1h), len(g.issued[ip]))
+		if used >= maxPerHour {
+			delete(g.pending, k)
+			g.escalated[k] = t
+			escalate = append(escalate, Escalation{Transition: t, Used: used, Max: maxPerHour})
+			continue
+		}
+		if quiet.Contains(now.Local()) {
+			continue
+		}
+		pool := PoolOf(subnets, ip)
+		if busy[pool] >= maxConc {
+			continue
+		}
+		busy[pool]++
+		delete(g.pending, k)
+		g.issued[ip] = append(g.issued[ip], now)
+		t.State = s
+		g.inflight[ip] = inflightBoot{pool: pool, at: now, t: t}
+		reboot = append(reboot, t)
+	}
+	return reboot, escalate
+}
@@ -0,0 +1,267 @@
+// Package automation evaluates the user rules of settings.Rules against device.state_updated
+// and decides when a rule fires (alert.raised, optional command.request) and clears.
+// cmd/automation runs it; core only stores and validates the rules.
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"asic-control/internal/modelnorm"
+	"asic-control/internal/netutil"
+	"asic-control/internal/settings"
+	"asic-control/internal/strutil"
+)
+
+// State is the last known state of a device, as carried by device.state_updated.
+type State struct {
+	At          time.Time // event time
+	IP          string
+	DeviceID    string
+	Online      bool
+	HashrateTHS float64
+	TempMaxC    float64
+	FanMaxRPM   float64
+	FanMinRPM   float64
+	Fans        float64
+	PowerW      float64
+	UptimeS     float64
+	Reboots1h   float64
+	Chains      float64 // hashing chains; only known when HasChains
+	HasChains   bool
+	Vendor      string
+	Model       string
+	Firmware    string
+	Worker      string
+	Auth        string
+}
+
+// metrics a condition can compare (false: the device does not report it); nominal is the
+// catalog figure "nominal" stands for.
+var metrics = map[string]struct {
+	get     func(State) (float64, bool)
+	nominal func(modelnorm.Spec) float64
+}{
+	"hashrate_ths":    {func(s State) (float64, bool) { return s.HashrateTHS, true }, func(sp modelnorm.Spec) float64 { return sp.NominalTHS }},
+	"temp_max_c":      {func(s State) (float64, bool) { return s.TempMaxC, true }, nil},
+	"fan_rpm_max":     {func(s State) (float64, bool) { return s.FanMaxRPM, true }, nil},
+	"fan_rpm_min":     {func(s State) (float64, bool) { return s.FanMinRPM, true }, nil},
+	"fans":            {func(s State) (float64, bool) { return s.Fans, true }, nil},
+	"chains":          {func(s State) (float64, bool) { return s.Chains, s.HasChains }, nil},
+	"power_w":         {func(s State) (float64, bool) { return s.PowerW, true }, func(sp modelnorm.Spec) float64 { return sp.PowerW }},
+	"uptime_s":        {func(s State) (float64, bool) { return s.UptimeS, true }, nil},
+	"reboot_count_1h": {func(s State) (float64, bool) { return s.Reboots1h, true }, nil},
+}
+
+// Condition is a parsed rule condition:
+//
+//	offline [for D]
+//	<metric> <op> <number> [for D]
+//	<metric> <op> [<number> *] nominal [for D]   (hashrate_ths, power_w: the model's rating)
+//
+// op is one of < <= > >= == !=; D is a Go duration ("15m", "1h30m").
+type Condition struct {
+	Offline bool
+	Metric  string
+	Op      string
+	Value   float64 // the number, or the factor of nominal
+	Nominal bool
+	For     time.Duration
+}
+
+var opRe = regexp.MustCompile(`<=|>=|==|!=|<|>|\*`)
+
+// ParseCondition parses the "when" text of a rule.
+func ParseCondition(s string) (Condition, error) {
+	var c Condition
+	f := strings.Fields(opRe.ReplaceAllString(strings.ToLower(s), " $0 "))
+	if n := len(f); n >= 2 && f[n-2] == "for" {
+		d, err := time.ParseDuration(f[n-1])
+		if err != nil || d < 0 {
+			return c, fmt.Errorf("bad hold time %q", f[n-1])
+		}
+		c.For, f = d, f[:n-2]
+	}
+	if len(f) == 1 && f[0] == "offline" {
+		c.Offline = true
+		return c, nil
+	}
+	if len(f) < 3 {
+		return c, errors.New(`expected "offline" or "<metric> <op> <value>"`)
+	}
+	m, ok := metrics[f[0]]
+	if !ok {
+		return c, fmt.Errorf("unknown metric %q", f[0])
+	}
+	c.Metric = f[0]
+	switch f[1] {
+	case "<", "<=", ">", ">=", "==", "!=":
+		c.Op = f[1]
+	default:
+		return c, fmt.Errorf("unknown operator %q", f[1])
+	}
+	rhs := strings.Join(f[2:], "")
+	num := rhs
+	if a, b, ok := strings.Cut(rhs, "*"); ok {
+		switch {
+		case b == "nominal":
+			num = a
+		case a == "nominal":
+			num = b
+		default:
+			return c, fmt.Errorf("bad value %q", strings.Join(f[2:], " "))
+		}
+		c.Nominal = true
+	} else if rhs == "nominal" {
+		c.Nominal, num = true, "1"
+	}
+	if c.Nominal && m.nominal == nil {
+		return c, fmt.Errorf("%s has no nominal value", c.Metric)
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return c, fmt.Errorf("bad value %q", strings.Join(f[2:], " "))
+	}
+	c.Value = v
+	return c, nil
+}
+
+// Threshold returns what the metric of s is compared with (false: nominal is unknown for
+// the model).
+func (c Condition) Threshold(s State) (float64, bool) {
+	if !c.Nominal {
+		return c.Value, true
+	}
+	sp, ok := modelnorm.Lookup(s.Model)
+	if !ok {
+		return 0, false
+	}
+	n := metrics[c.Metric].nominal(sp)
+	if n <= 0 {
+		return 0, false
+	}
+	return c.Value * n, true
+}
+
+// Eval reports whether s satisfies c right now, with the compared value. Metric conditions
+// never hold for an offline device (it has no telemetry; use "offline" for that) nor for a
+// metric the device does not report.
+func (c Condition) Eval(s State) (bool, float64) {
+	if c.Offline {
+		return !s.Online, 0
+	}
+	if !s.Online {
+		return false, 0
+	}
+	v, ok := metrics[c.Metric].get(s)
+	if !ok {
+		return false, 0
+	}
+	th, ok := c.Threshold(s)
+	if !ok {
+		return false, v
+	}
+	switch c.Op {
+	case "<":
+		return v < th, v
+	case "<=":
+		return v <= th, v
+	case ">":
+		return v > th, v
+	case ">=":
+		return v >= th, v
+	case "==":
+		return v == th, v
+	default:
+		return v != th, v
+	}
+}
+
+// Rule is a compiled settings.Rule.
+type Rule struct {
+	settings.Rule
+	Cond Condition
+}
+
+// Compile checks r and fills the defaults (severity warn, code rule_<id>).
+func Compile(r settings.Rule) (Rule, error) {
+	c, err := ParseCondition(r.When)
+	if err != nil {
+		return Rule{}, err
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = "warn"
+	case "info", "warn", "crit":
+	default:
+		return Rule{}, fmt.Errorf("bad severity %q (info/warn/crit)", r.Severity)
+	}
+	if strings.TrimSpace(r.Code) == "" {
+		r.Code = "rule_" + r.ID
+	}
+	return Rule{Rule: r, Cond: c}, nil
+}
+
+// Covers reports whether s is in scope: any of the subnets, vendors and models (each
+// empty = any). Models are case-insensitive globs ("*S19*").
+func (r Rule) Covers(s State) bool {
+	if len(r.Subnets) > 0 && !anyOf(r.Subnets, func(spec string) bool { return netutil.SpecContains(spec, s.IP) }) {
+		return false
+	}
+	if len(r.Vendors) > 0 && !anyOf(r.Vendors, func(v string) bool { return strings.EqualFold(strings.TrimSpace(v), s.Vendor) }) {
+		return false
+	}
+	if len(r.Models) > 0 && !anyOf(r.Models, func(p string) bool { return strutil.MatchGlob(p, s.Model) }) {
+		return false
+	}
+	return true
+}
+
+func anyOf(xs []string, f func(string) bool) bool {
+	for _, x := range xs {
+		if f(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe renders a firing rule for the alert message: "hashrate_ths 70.1 < 76 (0.8 * nominal) for 15m".
+func (r Rule) Describe(s State, v float64) string {
+	c := r.Cond
+	var b strings.Builder
+	if c.Offline {
+		b.WriteString("offline")
+	} else {
+		th, _ := c.Threshold(s)
+		fmt.Fprintf(&b, "%s %s %s %s", c.Metric, fmtNum(v), c.Op, fmtNum(th))
+		if c.Nominal {
+			fmt.Fprintf(&b, " (%s * nominal)", fmtNum(c.Value))
+		}
+	}
+	if c.For > 0 {
+		b.WriteString(" for " + fmtDur(c.For))
+	}
+	return b.String()
+}
+
+// fmtDur drops the zero tail of time.Duration.String: "15m0s" -> "15m", "1h0m0s" -> "1h".
+func fmtDur(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func fmtNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
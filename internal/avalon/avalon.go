@@ -0,0 +1,373 @@
+// Package avalon reads and controls Canaan AvalonMiner units over the cgminer API (4028).
+//
+// Avalon firmware packs per-module telemetry into estats "MM ID<n>" strings made of
+// Key[value] tokens, e.g.
+//
+//	Ver[1246-85-21082401_4ec6bb0_61407fa] Elapsed[1234] Temp[31] TMax[86] Fan1[3120]
+//	FanR[50%] MTmax[84 86 85] PS[0 1215 1288 253 3259 1287 3310] WORKMODE[1] GHSavg[90123.4]
+//
+// Control goes through the privileged "ascset" command (reboot, workmode, led); Avalon
+// firmware ships with write access enabled, so no credentials are involved.
+package avalon
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+)
+
+var tokenRe = regexp.MustCompile(`([A-Za-z][A-Za-z0-9_]*)\[([^\]]*)\]`)
+
+// ParseMMID splits an "MM ID" string into Key -> raw value.
+func ParseMMID(s string) map[string]string {
+	out := map[string]string{}
+	for _, m := range tokenRe.FindAllStringSubmatch(s, -1) {
+		out[m[1]] = strings.TrimSpace(m[2])
+	}
+	return out
+}
+
+// Module is one parsed "MM ID<n>" entry (one per controller module; A1xxx units have one).
+type Module struct {
+	ID       int               `json:"id"`
+	Version  string            `json:"version,omitempty"`
+	ElapsedS uint64            `json:"elapsed_s,omitempty"`
+	TempC    float64           `json:"temp_c,omitempty"`     // inlet
+	TempMaxC float64           `json:"temp_max_c,omitempty"` // hottest sensor
+	BoardMax []float64         `json:"board_temp_max_c,omitempty"`
+	BoardAvg []float64         `json:"board_temp_avg_c,omitempty"`
+	FansRPM  []int             `json:"fans_rpm,omitempty"`
+	FanPct   int               `json:"fan_pct,omitempty"`
+	GHSAvg   float64           `json:"ghs_avg,omitempty"`
+	PowerW   float64           `json:"power_w,omitempty"`
+	WorkMode int               `json:"work_mode"`
+	LED      bool              `json:"led,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+}
+
+// ParseModule turns one MM ID string into a Module.
+func ParseModule(id int, s string) Module {
+	f := ParseMMID(s)
+	m := Module{ID: id, Version: f["Ver"], Fields: f, WorkMode: -1}
+	m.ElapsedS = uint64(num(f["Elapsed"]))
+	m.TempC = num(f["Temp"])
+	m.TempMaxC = num(f["TMax"])
+	m.BoardMax = nums(f["MTmax"])
+	m.BoardAvg = nums(f["MTavg"])
+	for i := 1; i <= 4; i++ {
+		if v, ok := f["Fan"+strconv.Itoa(i)]; ok {
+			m.FansRPM = append(m.FansRPM, int(num(v)))
+		}
+	}
+	m.FanPct = int(num(strings.TrimSuffix(f["FanR"], "%")))
+	m.GHSAvg = num(f["GHSavg"])
+	if m.GHSAvg == 0 {
+		m.GHSAvg = num(f["GHSmm"])
+	}
+	// PS[err vctl vcore ia watts_out vout watts_in]: the last field is wall power; short
+	// (older) PS lines only carry the PSU output in the 5th.
+	if ps := nums(f["PS"]); len(ps) >= 7 {
+		m.PowerW = ps[len(ps)-1]
+	} else if len(ps) >= 5 {
+		m.PowerW = ps[4]
+	}
+	if v, ok := f["WORKMODE"]; ok {
+		m.WorkMode = int(num(v))
+	}
+	m.LED = num(f["Led"]) != 0
+	return m
+}
+
+// Model derives "A1246" style names from the Ver field (model number before the first dash).
+func (m Module) Model() string {
+	v := m.Version
+	if i := strings.IndexByte(v, '-'); i > 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return ""
+	}
+	if v[0] >= '0' && v[0] <= '9' {
+		return "A" + v
+	}
+	return v
+}
+
+// Temps returns per-board max temps, falling back to the module max / inlet.
+func (m Module) Temps() []float64 {
+	if len(m.BoardMax) > 0 {
+		return m.BoardMax
+	}
+	if m.TempMaxC > 0 {
+		return []float64{m.TempMaxC}
+	}
+	if m.TempC > 0 {
+		return []float64{m.TempC}
+	}
+	return nil
+}
+
+// Chains returns one entry per hashboard. Board count comes from MTmax; the per-board keys
+// are MGHS (GH/s), MH (hardware errors), SF<i> (chip frequencies), PVT_T<i> (chip temps)
+// and PVT_V<i> (chip voltages, mV). TA is the module-wide ASIC count, split evenly.
+func (m Module) Chains() []sdk.Chain {
+	f := m.Fields
+	boards := len(m.BoardMax)
+	if boards == 0 {
+		return nil
+	}
+	ghs := nums(f["MGHS"])
+	hw := nums(f["MH"])
+	var out []sdk.Chain
+	for i := 0; i < boards; i++ {
+		s := strconv.Itoa(i)
+		c := sdk.Chain{
+			Index:      i,
+			ChipTempsC: nonZero(nums(f["PVT_T"+s])),
+			FreqMHz:    avg(nonZero(nums(f["SF"+s]))),
+			VoltageV:   avg(nonZero(nums(f["PVT_V"+s]))) / 1000,
+		}
+		if i < len(ghs) {
+			c.HashrateTHS = ghs[i] / 1e3
+		}
+		if i < len(hw) {
+			c.HWErrors = uint64(hw[i])
+		}
+		if ta := int(num(f["TA"])); ta > 0 {
+			c.ChipsExpected = ta / boards
+		}
+		if len(c.ChipTempsC) == 0 {
+			c.ChipTempsC = []float64{m.BoardMax[i]}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Modules extracts every "MM ID<n>" entry from estats (older firmware puts them in stats).
+// Newer firmware splits a module over "MM ID0:Summary", "MM ID0:..." keys; they are merged.
+func Modules(stats []cgminer.StatsEntry) []Module {
+	byID := map[int][]string{}
+	for _, e := range stats {
+		for k, v := range e {
+			if !strings.HasPrefix(k, "MM ID") {
+				continue
+			}
+			s, ok := v.(string)
+			if !ok {
+				continue
+			}
+			rest := k[len("MM ID"):]
+			n := 0
+			for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+				n++
+			}
+			id, err := strconv.Atoi(rest[:n])
+			if err != nil {
+				continue
+			}
+			byID[id] = append(byID[id], s)
+		}
+	}
+	out := make([]Module, 0, len(byID))
+	for id, parts := range byID {
+		sort.Strings(parts)
+		out = append(out, ParseModule(id, strings.Join(parts, " ")))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Telemetry is the credential-less read set used by polling.
+type Telemetry struct {
+	Summary cgminer.Summary `json:"summary"`
+	Version cgminer.Version `json:"version"`
+	Pools   []cgminer.Pool  `json:"pools,omitempty"`
+	Modules []Module        `json:"modules,omitempty"`
+}
+
+// Read collects summary, version, pools and estats. Only estats is required.
+func Read(ctx context.Context, c *cgminer.Client) (Telemetry, error) {
+	var t Telemetry
+	st, err := c.Estats(ctx)
+	if err != nil || len(Modules(st)) == 0 {
+		// pre-estats firmware
+		if st2, err2 := c.Stats(ctx); err2 == nil && len(Modules(st2)) > 0 {
+			st, err = st2, nil
+		}
+	}
+	if err != nil {
+		return t, err
+	}
+	t.Modules = Modules(st)
+	if s, err := c.Summary(ctx); err == nil {
+		t.Summary = s
+	}
+	if v, err := c.Version(ctx); err == nil {
+		t.Version = v
+	}
+	if ps, err := c.Pools(ctx); err == nil {
+		t.Pools = ps
+	}
+	return t, nil
+}
+
+// Model prefers the version command ("MODEL":"1246" / "PROD":"AvalonMiner 1246").
+func (t Telemetry) Model() string {
+	if m := strings.TrimSpace(t.Version.All["MODEL"]); m != "" {
+		if m[0] >= '0' && m[0] <= '9' {
+			return "A" + m
+		}
+		return m
+	}
+	for _, m := range t.Modules {
+		if s := m.Model(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (t Telemetry) MAC() string {
+	mac := strings.ToLower(strings.TrimSpace(t.Version.All["MAC"]))
+	if len(mac) == 12 && !strings.Contains(mac, ":") {
+		parts := make([]string, 0, 6)
+		for i := 0; i < 12; i += 2 {
+			parts = append(parts, mac[i:i+2])
+		}
+		mac = strings.Join(parts, ":")
+	}
+	return mac
+}
+
+func (t Telemetry) Firmware() string {
+	return strings.TrimSpace(t.Version.All["VERSION"])
+}
+
+func (t Telemetry) Worker() string {
+	for _, p := range t.Pools {
+		if p.StratumActive && strings.TrimSpace(p.User) != "" {
+			return p.User
+		}
+	}
+	for _, p := range t.Pools {
+		if strings.TrimSpace(p.User) != "" {
+			return p.User
+		}
+	}
+	return ""
+}
+
+func (t Telemetry) UptimeS() uint64 {
+	if up := t.Summary.Elapsed.Int(); up > 0 {
+		return uint64(up)
+	}
+	for _, m := range t.Modules {
+		if m.ElapsedS > 0 {
+			return m.ElapsedS
+		}
+	}
+	return 0
+}
+
+func (t Telemetry) HashrateTHS() float64 {
+	if hr := t.Summary.HashrateTHS(); hr > 0 {
+		return hr
+	}
+	total := 0.0
+	for _, m := range t.Modules {
+		total += m.GHSAvg
+	}
+	return total / 1e3
+}
+
+func (t Telemetry) FansRPM() []int {
+	var out []int
+	for _, m := range t.Modules {
+		out = append(out, m.FansRPM...)
+	}
+	return out
+}
+
+func (t Telemetry) TempsC() []float64 {
+	var out []float64
+	for _, m := range t.Modules {
+		out = append(out, m.Temps()...)
+	}
+	return out
+}
+
+// Chains numbers hashboards sequentially across modules (most units have one module).
+func (t Telemetry) Chains() []sdk.Chain {
+	var out []sdk.Chain
+	for _, m := range t.Modules {
+		for _, c := range m.Chains() {
+			c.Index = len(out)
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (t Telemetry) PowerW() float64 {
+	total := 0.0
+	for _, m := range t.Modules {
+		total += m.PowerW
+	}
+	return total
+}
+
+// WorkMode is the first module's WORKMODE (-1 when not reported).
+func (t Telemetry) WorkMode() int {
+	for _, m := range t.Modules {
+		if m.WorkMode >= 0 {
+			return m.WorkMode
+		}
+	}
+	return -1
+}
+
+func num(s string) float64 {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func nums(s string) []float64 {
+	var out []float64
+	for _, p := range strings.Fields(s) {
+		if f, err := strconv.ParseFloat(p, 64); err == nil {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func nonZero(vs []float64) []float64 {
+	var out []float64
+	for _, v := range vs {
+		if v != 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func avg(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
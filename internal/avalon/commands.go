@@ -0,0 +1,70 @@
+package avalon
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/cgminer"
+)
+
+// CommandResult is the outcome of an ascset control call.
+type CommandResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+func ascset(ctx context.Context, c *cgminer.Client, param string) CommandResult {
+	resp, err := c.Call(ctx, "ascset", param)
+	out := CommandResult{}
+	if resp != nil && len(resp.Status) > 0 {
+		out.Body = resp.Status[0].Msg
+	}
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	// "ASC 0 set OK"; failures sometimes come back as STATUS=I with an explanatory Msg
+	if m := strings.ToLower(out.Body); strings.Contains(m, "fail") || strings.Contains(m, "invalid") {
+		out.Error = out.Body
+		return out
+	}
+	out.OK = true
+	return out
+}
+
+// Reboot restarts the whole unit (controller + hashboards).
+func Reboot(ctx context.Context, c *cgminer.Client) CommandResult {
+	r := ascset(ctx, c, "0,reboot,0")
+	if e := strings.ToLower(r.Error); !r.OK && (strings.Contains(e, "eof") || strings.Contains(e, "empty response") || strings.Contains(e, "connection reset")) {
+		// the controller may go down before answering
+		r.OK, r.Error = true, ""
+	}
+	return r
+}
+
+// SetWorkMode switches the work mode (A12+: 0 low, 1 normal, 2 high; model dependent).
+// Newer firmware takes "workmode,set,<n>", older "workmode,<n>".
+func SetWorkMode(ctx context.Context, c *cgminer.Client, mode int) CommandResult {
+	if mode < 0 || mode > 2 {
+		return CommandResult{Error: "work mode must be 0..2"}
+	}
+	r := ascset(ctx, c, "0,workmode,set,"+strconv.Itoa(mode))
+	if r.OK {
+		return r
+	}
+	if r2 := ascset(ctx, c, "0,workmode,"+strconv.Itoa(mode)); r2.OK {
+		return r2
+	}
+	return r
+}
+
+// SetLED turns the locate LED of module 1 on or off. Canaan's AvalonMiner cgminer API
+// reference: "ascset|0,led,<module>-<op>", op 1 = on, 0 = off.
+func SetLED(ctx context.Context, c *cgminer.Client, on bool) CommandResult {
+	if on {
+		return ascset(ctx, c, "0,led,1-1")
+	}
+	return ascset(ctx, c, "0,led,1-0")
+}
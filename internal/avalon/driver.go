@@ -0,0 +1,83 @@
+package avalon
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver parses Avalon estats MM ID strings and writes through ascset (cgminer API, no credentials).
+type driver struct{}
+
+func (driver) Name() string { return "avalon" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "avalonminer" {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, _ []sdk.Cred) sdk.ProbeResult {
+	tel, err := Read(ctx, cgminer.New(t.IP))
+	if err != nil {
+		return sdk.ProbeResult{Error: err.Error()}
+	}
+	return sdk.ProbeResult{OK: true, Reply: tel}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	tel, ok := pr.Reply.(Telemetry)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := sdk.Facts{
+		Vendor:      "avalonminer",
+		Model:       tel.Model(),
+		Firmware:    tel.Firmware(),
+		Worker:      tel.Worker(),
+		MAC:         tel.MAC(),
+		UptimeS:     tel.UptimeS(),
+		HashrateTHS: tel.HashrateTHS(),
+		FansRPM:     tel.FansRPM(),
+		TempsC:      tel.TempsC(),
+		PowerW:      tel.PowerW(),
+		Chains:      tel.Chains(),
+		Pools:       cgminer.PoolStatuses(tel.Pools),
+	}
+	if wm := tel.WorkMode(); wm >= 0 {
+		f.WorkMode = strconv.Itoa(wm)
+	}
+	return f
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, _ []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, cgminer.New(t.IP)))
+		},
+		Control: map[string]sdk.ControlFunc{
+			sdk.KindSetLED: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, args map[string]string) sdk.CommandResult {
+				return commandResult(SetLED(ctx, cgminer.New(t.IP), sdk.LEDOn(args)))
+			},
+			sdk.KindSetWorkMode: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, args map[string]string) sdk.CommandResult {
+				n, err := strconv.Atoi(strings.TrimSpace(args["mode"]))
+				if err != nil {
+					return sdk.CommandResult{Error: "bad args: mode"}
+				}
+				r := commandResult(SetWorkMode(ctx, cgminer.New(t.IP), n))
+				r.Detail = "workmode " + strconv.Itoa(n)
+				return r
+			},
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, Error: r.Error}
+}
@@ -0,0 +1,28 @@
+package httpapi
+
+import "context"
+
+// Reboot reboots the control board (whole miner).
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	return s.Write(ctx, "POST", "/api/v1/actions/reboot", map[string]any{})
+}
+
+// SetPowerTarget sets the autotuner power target (watts) and saves it to the configuration.
+// The tuner re-tunes towards the new target; this only works with the tuner in power-target mode.
+func SetPowerTarget(ctx context.Context, host string, creds []Cred, schemes []string, watts int) CommandResult {
+	if watts <= 0 {
+		return CommandResult{OK: false, Error: "power target must be > 0 W"}
+	}
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	return s.Write(ctx, "PUT", "/api/v1/performance/power-target", map[string]any{
+		"save_action":  "SAVE_ACTION_SAVE_AND_APPLY",
+		"power_target": map[string]any{"watt": watts},
+	})
+}
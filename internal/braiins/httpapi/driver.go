@@ -0,0 +1,109 @@
+package httpapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver is the Braiins OS public API. Antminers not yet fingerprinted as Braiins get it as
+// a fallback: the firmware serves its own SPA, which the stock CGI probe rejects.
+type driver struct{}
+
+func (driver) Name() string { return "braiins" }
+
+func (driver) Detect(t sdk.Target) int {
+	switch v := t.VendorKey(); {
+	case v != "" && v != "antminer":
+		return sdk.ConfidenceNone
+	case t.FirmwareHas("braiins"):
+		return sdk.ConfidenceExact
+	case sdk.IsVnishFirmware(t.Firmware), t.FirmwareHas("luxos"):
+		return sdk.ConfidenceNone
+	}
+	return sdk.ConfidenceFallback
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	res := Probe(ctx, t.IP, creds, t.Schemes())
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	res, ok := pr.Reply.(ProbeResult)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := ExtractFacts(res)
+	return sdk.Facts{
+		Vendor:      "antminer",
+		Model:       f.Model,
+		Firmware:    f.Firmware,
+		Worker:      f.Worker,
+		MAC:         f.MAC,
+		UptimeS:     f.UptimeS,
+		HashrateTHS: f.HashrateTHS,
+		FansRPM:     f.FansRPM,
+		TempsC:      f.TempsC,
+		PowerW:      f.PowerW,
+		WorkMode:    f.WorkMode,
+		Pools:       poolStatuses(res.Telemetry),
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, t.IP, creds, t.Schemes()))
+		},
+		Control: map[string]sdk.ControlFunc{
+			sdk.KindSetPowerTarget: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, args map[string]string) sdk.CommandResult {
+				w, err := strconv.Atoi(strings.TrimSpace(args["watts"]))
+				if err != nil || w <= 0 {
+					return sdk.CommandResult{Error: "bad args: watts must be > 0"}
+				}
+				r := commandResult(SetPowerTarget(ctx, t.IP, creds, t.Schemes(), w))
+				r.Detail = strconv.Itoa(w) + " W"
+				return r
+			},
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, UsedCred: r.UsedCred, Error: r.Error}
+}
+
+// poolStatuses flattens the pool groups; priority is the position within the group.
+func poolStatuses(t *Telemetry) []sdk.PoolStatus {
+	if t == nil {
+		return nil
+	}
+	var out []sdk.PoolStatus
+	prio := map[string]int{}
+	for _, p := range t.Pools {
+		st := sdk.PoolStatus{
+			URL:      p.URL,
+			User:     p.User,
+			Status:   "dead",
+			Priority: prio[p.Group],
+			Active:   p.Active,
+			Accepted: uint64(max(p.Accepted, 0)),
+			Rejected: uint64(max(p.Rejected, 0)),
+			Stale:    uint64(max(p.Stale, 0)),
+		}
+		prio[p.Group]++
+		switch {
+		case !p.Enabled:
+			st.Status = "disabled"
+		case p.Alive:
+			st.Status = "alive"
+		}
+		out = append(out, st)
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+type Facts struct {
+	Model       string
+	Firmware    string
+	MAC         string
+	Worker      string
+	UptimeS     uint64
+	HashrateTHS float64
+	FansRPM     []int
+	TempsC      []float64
+	PowerW      float64
+	WorkMode    string // "<target> W <tuner state>"
+}
+
+// ExtractFacts maps Braiins telemetry onto device facts.
+func ExtractFacts(res ProbeResult) Facts {
+	var f Facts
+	t := res.Telemetry
+	if t == nil {
+		return f
+	}
+	f.Model = t.Model
+	f.Firmware = "Braiins OS"
+	if t.Version != "" {
+		f.Firmware += " " + t.Version
+	}
+	f.MAC = t.MAC
+	f.UptimeS = t.UptimeS
+	f.HashrateTHS = t.HashrateTHS
+	f.FansRPM = t.FansRPM
+	for _, b := range t.Hashboards {
+		if b.ChipTempC > 0 {
+			f.TempsC = append(f.TempsC, b.ChipTempC)
+		} else if b.BoardTempC > 0 {
+			f.TempsC = append(f.TempsC, b.BoardTempC)
+		}
+	}
+	f.PowerW = t.PowerW
+	var mode []string
+	if t.PowerTargetW > 0 {
+		mode = append(mode, strconv.FormatFloat(t.PowerTargetW, 'f', 0, 64)+" W")
+	}
+	if t.TunerState != "" {
+		mode = append(mode, t.TunerState)
+	}
+	f.WorkMode = strings.Join(mode, " ")
+	for _, p := range t.Pools {
+		if p.Active && p.User != "" {
+			f.Worker = p.User
+			break
+		}
+	}
+	if f.Worker == "" {
+		for _, p := range t.Pools {
+			if p.Enabled && p.User != "" {
+				f.Worker = p.User
+				break
+			}
+		}
+	}
+	return f
+}
@@ -0,0 +1,241 @@
+// Package httpapi talks to the Braiins OS / Braiins OS+ public API through its REST gateway
+// (same messages as the gRPC API on :50051, JSON over HTTP on the web port).
+//
+// Reads used by MonA:
+//
+//	GET /api/v1/miner/details           model, hostname, MAC, BOS version, uptime
+//	GET /api/v1/miner/stats             real/nominal hashrate, approximated power, J/TH
+//	GET /api/v1/miner/hw/hashboards     per-board chips, temps, frequency, voltage, hashrate
+//	GET /api/v1/cooling/state           fan RPMs
+//	GET /api/v1/performance/tuner-state autotuner state and current power target
+//	GET /api/v1/pools                   pool groups with share counters
+//
+// Field names differ between gateway versions (snake_case vs lowerCamel), so documents are
+// read through a case/underscore-insensitive lookup instead of fixed structs.
+package httpapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Hashboard is one hashboard from /miner/hw/hashboards.
+type Hashboard struct {
+	ID           string  `json:"id"`
+	Enabled      bool    `json:"enabled"`
+	Chips        int     `json:"chips,omitempty"`
+	HashrateTHS  float64 `json:"hashrate_ths,omitempty"`
+	ChipTempC    float64 `json:"chip_temp_c,omitempty"` // highest chip
+	BoardTempC   float64 `json:"board_temp_c,omitempty"`
+	FrequencyMHz float64 `json:"frequency_mhz,omitempty"`
+	VoltageV     float64 `json:"voltage_v,omitempty"`
+}
+
+// PoolStat is one pool of a pool group.
+type PoolStat struct {
+	Group    string `json:"group,omitempty"`
+	URL      string `json:"url"`
+	User     string `json:"user"`
+	Enabled  bool   `json:"enabled"`
+	Alive    bool   `json:"alive"`
+	Active   bool   `json:"active"`
+	Accepted int64  `json:"accepted,omitempty"`
+	Rejected int64  `json:"rejected,omitempty"`
+	Stale    int64  `json:"stale,omitempty"`
+}
+
+// Telemetry is everything read in one probe.
+type Telemetry struct {
+	Model         string      `json:"model,omitempty"`
+	Hostname      string      `json:"hostname,omitempty"`
+	MAC           string      `json:"mac,omitempty"`
+	Version       string      `json:"version,omitempty"`
+	UptimeS       uint64      `json:"uptime_s,omitempty"`
+	HashrateTHS   float64     `json:"hashrate_ths,omitempty"`
+	NominalTHS    float64     `json:"nominal_ths,omitempty"`
+	PowerW        float64     `json:"power_w,omitempty"`
+	EfficiencyJTH float64     `json:"efficiency_j_th,omitempty"`
+	Hashboards    []Hashboard `json:"hashboards,omitempty"`
+	FansRPM       []int       `json:"fans_rpm,omitempty"`
+	TunerState    string      `json:"tuner_state,omitempty"` // stable/tuning/disabled/failed...
+	PowerTargetW  float64     `json:"power_target_w,omitempty"`
+	Pools         []PoolStat  `json:"pools,omitempty"`
+}
+
+type ProbeResult struct {
+	OK        bool       `json:"ok"`
+	Scheme    string     `json:"scheme,omitempty"`
+	UsedCred  string     `json:"used_cred,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"`
+}
+
+// Probe logs in and reads the telemetry set. Only miner details are required.
+func Probe(ctx context.Context, host string, creds []Cred, schemes []string) ProbeResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return ProbeResult{OK: false, Error: err.Error()}
+	}
+	out := ProbeResult{Scheme: s.Scheme, UsedCred: s.UsedCred}
+	t, err := s.Read(ctx)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.OK = true
+	out.Telemetry = t
+	return out
+}
+
+// Read collects the telemetry documents over an open session.
+func (s *Session) Read(ctx context.Context) (*Telemetry, error) {
+	details, err := s.GetJSON(ctx, "/api/v1/miner/details")
+	if err != nil {
+		return nil, err
+	}
+	t := &Telemetry{
+		Model:    str(get(details, "miner_identity", "model")),
+		Hostname: str(get(details, "hostname")),
+		MAC:      strings.ToLower(str(get(details, "mac_address"))),
+		Version:  str(get(details, "bos_version", "current")),
+		UptimeS:  uint64(num(get(details, "bosminer_uptime_s"))),
+	}
+	if t.Model == "" {
+		t.Model = str(get(details, "miner_identity", "name"))
+	}
+	if t.UptimeS == 0 {
+		t.UptimeS = uint64(num(get(details, "system_uptime_s")))
+	}
+
+	if st, err := s.GetJSON(ctx, "/api/v1/miner/stats"); err == nil {
+		t.HashrateTHS = ghs(get(st, "miner_stats", "real_hashrate", "last_5m"))
+		if t.HashrateTHS == 0 {
+			t.HashrateTHS = ghs(get(st, "miner_stats", "real_hashrate", "last_5s"))
+		}
+		t.NominalTHS = ghs(get(st, "miner_stats", "nominal_hashrate"))
+		t.PowerW = num(get(st, "power_stats", "approximated_consumption", "watt"))
+		t.EfficiencyJTH = num(get(st, "power_stats", "efficiency", "joule_per_terahash"))
+	}
+
+	if hb, err := s.GetJSON(ctx, "/api/v1/miner/hw/hashboards"); err == nil {
+		for _, v := range list(get(hb, "hashboards")) {
+			b := Hashboard{
+				ID:           str(get(v, "id")),
+				Enabled:      truthy(get(v, "enabled")),
+				Chips:        int(num(get(v, "chips_count"))),
+				HashrateTHS:  ghs(get(v, "stats", "real_hashrate", "last_5m")),
+				ChipTempC:    num(get(v, "highest_chip_temp", "temperature", "degree_c")),
+				BoardTempC:   num(get(v, "board_temp", "degree_c")),
+				FrequencyMHz: num(get(v, "current_frequency", "hertz")) / 1e6,
+				VoltageV:     num(get(v, "current_voltage", "volt")),
+			}
+			if b.HashrateTHS == 0 {
+				b.HashrateTHS = ghs(get(v, "stats", "real_hashrate", "last_5s"))
+			}
+			t.Hashboards = append(t.Hashboards, b)
+		}
+	}
+
+	if cs, err := s.GetJSON(ctx, "/api/v1/cooling/state"); err == nil {
+		for _, f := range list(get(cs, "fans")) {
+			t.FansRPM = append(t.FansRPM, int(num(get(f, "rpm"))))
+		}
+	}
+
+	if ts, err := s.GetJSON(ctx, "/api/v1/performance/tuner-state"); err == nil {
+		t.TunerState = tunerState(str(get(ts, "overall_tuner_state")))
+		t.PowerTargetW = num(get(ts, "mode_state", "power_target_mode_state", "current_target", "watt"))
+	}
+
+	if ps, err := s.GetJSON(ctx, "/api/v1/pools"); err == nil {
+		for _, g := range list(get(ps, "pool_groups")) {
+			name := str(get(g, "name"))
+			for _, p := range list(get(g, "pools")) {
+				t.Pools = append(t.Pools, PoolStat{
+					Group:    name,
+					URL:      str(get(p, "url")),
+					User:     str(get(p, "user")),
+					Enabled:  truthy(get(p, "enabled")),
+					Alive:    truthy(get(p, "alive")),
+					Active:   truthy(get(p, "active")),
+					Accepted: int64(num(get(p, "stats", "accepted_shares"))),
+					Rejected: int64(num(get(p, "stats", "rejected_shares"))),
+					Stale:    int64(num(get(p, "stats", "stale_shares"))),
+				})
+			}
+		}
+	}
+	return t, nil
+}
+
+// tunerState turns "TUNER_STATE_STABLE" into "stable".
+func tunerState(s string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "TUNER_STATE_"))
+}
+
+// normKey makes snake_case and lowerCamel names comparable.
+func normKey(k string) string {
+	return strings.ToLower(strings.ReplaceAll(k, "_", ""))
+}
+
+// get walks nested objects by key (case/underscore-insensitive). Missing keys give nil.
+func get(v any, path ...string) any {
+	for _, p := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		want := normKey(p)
+		v = nil
+		for k, vv := range m {
+			if normKey(k) == want {
+				v = vv
+				break
+			}
+		}
+	}
+	return v
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func str(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return ""
+}
+
+// num accepts JSON numbers and the quoted 64-bit integers protojson emits.
+func num(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f
+	}
+	return 0
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case string:
+		return strings.EqualFold(x, "true")
+	}
+	return false
+}
+
+// ghs reads a {"gigahash_per_second": n} hashrate message as TH/s.
+func ghs(v any) float64 {
+	return num(get(v, "gigahash_per_second")) / 1e3
+}
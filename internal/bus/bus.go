@@ -2,15 +2,20 @@ package bus
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrClosed is returned by Fetch once the subscription can no longer deliver
+// (connection closed or draining, consumer deleted). Callers should reconnect.
+var ErrClosed = errors.New("bus: subscription closed")
+
 type Publisher interface {
 	Publish(ctx context.Context, subject string, data []byte) error
 }
 
 type PullConsumer interface {
-	// Fetch blocks up to wait time, returning up to batch messages.
+	// Fetch blocks up to wait time, returning up to batch messages (none on timeout).
 	Fetch(ctx context.Context, batch int, wait time.Duration) ([]Message, error)
 }
 
@@ -19,5 +24,12 @@ type Message interface {
 	Ack() error
 	Nak() error
 	Term() error
+
+	// NakWithDelay asks for redelivery after d (retry with backoff).
+	NakWithDelay(d time.Duration) error
+	// InProgress extends the ack deadline for long-running handlers.
+	InProgress() error
+	// NumDelivered is the delivery attempt (1 = first delivery, 0 = unknown).
+	NumDelivered() uint64
 }
 
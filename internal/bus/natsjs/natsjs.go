@@ -84,14 +84,35 @@ type pullConsumer struct {
 }
 
 func (c *Client) NewPullConsumer(durable, filterSubject string, maxAckPending int) (bus.PullConsumer, error) {
+	return c.NewPullConsumerWith(durable, filterSubject, ConsumerOptions{MaxAckPending: maxAckPending})
+}
+
+// ConsumerOptions tunes a durable pull consumer. Zero values keep the server defaults.
+type ConsumerOptions struct {
+	MaxAckPending int
+	AckWait       time.Duration // redelivery if not acked within this time
+	MaxDeliver    int           // total delivery attempts (incl. naks)
+}
+
+func (c *Client) NewPullConsumerWith(durable, filterSubject string, o ConsumerOptions) (bus.PullConsumer, error) {
 	s := events.Subject(c.prefix, filterSubject)
 
-	// Create durable consumer implicitly by using PullSubscribe + durable name.
-	sub, err := c.js.PullSubscribe(s, durable,
+	opts := []nats.SubOpt{
 		nats.ManualAck(),
 		nats.AckExplicit(),
-		nats.MaxAckPending(maxAckPending),
-	)
+	}
+	if o.MaxAckPending > 0 {
+		opts = append(opts, nats.MaxAckPending(o.MaxAckPending))
+	}
+	if o.AckWait > 0 {
+		opts = append(opts, nats.AckWait(o.AckWait))
+	}
+	if o.MaxDeliver > 0 {
+		opts = append(opts, nats.MaxDeliver(o.MaxDeliver))
+	}
+
+	// Create durable consumer implicitly by using PullSubscribe + durable name.
+	sub, err := c.js.PullSubscribe(s, durable, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -107,9 +128,33 @@ func (m *msg) Ack() error   { return m.m.Ack() }
 func (m *msg) Nak() error   { return m.m.Nak() }
 func (m *msg) Term() error  { return m.m.Term() }
 
+func (m *msg) NakWithDelay(d time.Duration) error { return m.m.NakWithDelay(d) }
+func (m *msg) InProgress() error                  { return m.m.InProgress() }
+
+func (m *msg) NumDelivered() uint64 {
+	md, err := m.m.Metadata()
+	if err != nil {
+		return 0
+	}
+	return md.NumDelivered
+}
+
+// Fetch returns no messages and no error when wait expires empty. Errors that leave the
+// subscription unusable are wrapped in bus.ErrClosed.
 func (pc *pullConsumer) Fetch(ctx context.Context, batch int, wait time.Duration) ([]bus.Message, error) {
-	msgs, err := pc.sub.Fetch(batch, nats.Context(ctx), nats.MaxWait(wait))
+	// nats rejects a context together with MaxWait, so the wait rides on the context.
+	fctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	msgs, err := pc.sub.Fetch(batch, nats.Context(fctx))
 	if err != nil {
+		switch {
+		case ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout)):
+			return nil, nil
+		case errors.Is(err, nats.ErrBadSubscription), errors.Is(err, nats.ErrSubscriptionClosed),
+			errors.Is(err, nats.ErrConnectionClosed), errors.Is(err, nats.ErrConnectionDraining),
+			errors.Is(err, nats.ErrConsumerDeleted), errors.Is(err, nats.ErrConsumerNotFound):
+			return nil, fmt.Errorf("%w: %v", bus.ErrClosed, err)
+		}
 		return nil, err
 	}
 	out := make([]bus.Message, 0, len(msgs))
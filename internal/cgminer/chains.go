@@ -0,0 +1,170 @@
+package cgminer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// Chains extracts per-chain telemetry from bmminer-style STATS in either layout:
+//
+//   - a "chain" array of objects (stock S19 and later stats.cgi): index, rate_real,
+//     rate_ideal, asic / asic_num, temp_chip, temp_pcb, freq_avg, hw;
+//   - flat chain_rateN / chain_acnN / chain_acsN / temp_pcbN keys (older bmminer, the
+//     cgminer API on 4028, LuxOS).
+//
+// Rates are converted with the entry's rate_unit (GH/s when absent). Empty slots are skipped.
+func Chains(stats []StatsEntry) []sdk.Chain {
+	var out []sdk.Chain
+	for _, e := range stats {
+		div := rateDiv(e.String("rate_unit"))
+		if arr, ok := e["chain"].([]any); ok {
+			for i, x := range arr {
+				m, ok := x.(map[string]any)
+				if !ok {
+					continue
+				}
+				ce := StatsEntry(m)
+				c := sdk.Chain{
+					Index:       i,
+					HashrateTHS: ce.Float("rate_real") / div,
+					IdealTHS:    ce.Float("rate_ideal") / div,
+					FreqMHz:     ce.Float("freq_avg"),
+					VoltageV:    volts(ce.Float("voltage")),
+					HWErrors:    uint64(ce.Float("hw")),
+					ChipTempsC:  floats(m["temp_chip"]),
+					PCBTempsC:   floats(m["temp_pcb"]),
+				}
+				if _, ok := m["index"]; ok {
+					c.Index = int(ce.Float("index"))
+				}
+				c.ChipsExpected, c.Chips = chipStatus(ce.String("asic"))
+				if n := int(ce.Float("asic_num")); c.Chips == 0 && n > 0 {
+					c.Chips = n
+				}
+				if !emptyChain(c) {
+					out = append(out, c)
+				}
+			}
+			continue
+		}
+		for _, n := range chainIndexes(e) {
+			s := strconv.Itoa(n)
+			c := sdk.Chain{
+				Index:       n,
+				HashrateTHS: e.Float("chain_rate"+s) / div,
+				IdealTHS:    e.Float("chain_rateideal"+s) / div,
+				FreqMHz:     e.Float("freq_avg" + s),
+				VoltageV:    volts(e.Float("chain_vol" + s)),
+				HWErrors:    uint64(e.Float("chain_hw" + s)),
+				PCBTempsC:   dashFloats(e.String("temp_pcb" + s)),
+				ChipTempsC:  dashFloats(e.String("temp_chip" + s)),
+			}
+			if len(c.PCBTempsC) == 0 {
+				if t := e.Float("temp" + s); t > 0 {
+					c.PCBTempsC = []float64{t}
+				}
+			}
+			if len(c.ChipTempsC) == 0 {
+				if t := e.Float("temp2_" + s); t > 0 {
+					c.ChipTempsC = []float64{t}
+				}
+			}
+			c.ChipsExpected, c.Chips = chipStatus(e.String("chain_acs" + s))
+			if n := int(e.Float("chain_acn" + s)); c.Chips == 0 && n > 0 {
+				c.Chips = n
+			}
+			if !emptyChain(c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// chainIndexes lists N of chain_acnN / chain_rateN keys (bmminer reserves up to 16 slots).
+func chainIndexes(e StatsEntry) []int {
+	seen := map[int]bool{}
+	for k := range e {
+		for _, p := range []string{"chain_acn", "chain_rate"} {
+			if !strings.HasPrefix(k, p) {
+				continue
+			}
+			if n, err := strconv.Atoi(k[len(p):]); err == nil && n >= 0 && n <= 16 {
+				seen[n] = true
+			}
+		}
+	}
+	out := make([]int, 0, len(seen))
+	for n := range seen {
+		out = append(out, n)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// chipStatus counts a bmminer chip status string ("oooo xoo-"): o = ok, x = bad, - = missing.
+func chipStatus(s string) (expected, ok int) {
+	for _, r := range s {
+		switch r {
+		case 'o':
+			ok++
+			expected++
+		case 'x', 'X', '-':
+			expected++
+		}
+	}
+	return expected, ok
+}
+
+func emptyChain(c sdk.Chain) bool {
+	return c.HashrateTHS == 0 && c.Chips == 0 && c.ChipsExpected == 0 && len(c.ChipTempsC) == 0 && len(c.PCBTempsC) == 0
+}
+
+func rateDiv(unit string) float64 {
+	u := strings.ToLower(strings.TrimSpace(unit))
+	switch {
+	case strings.HasPrefix(u, "th"):
+		return 1
+	case strings.HasPrefix(u, "mh"):
+		return 1e6
+	case strings.HasPrefix(u, "kh"):
+		return 1e9
+	}
+	return 1e3
+}
+
+// volts accepts V or mV (bmminer reports either depending on the build).
+func volts(v float64) float64 {
+	if v > 100 {
+		return v / 1000
+	}
+	return v
+}
+
+func floats(v any) []float64 {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	var out []float64
+	for _, x := range arr {
+		if f := (StatsEntry{"v": x}).Float("v"); f != 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// dashFloats parses "38-37-52-51" sensor lists (legacy temp_pcbN / temp_chipN).
+func dashFloats(s string) []float64 {
+	var out []float64
+	for _, p := range strings.Split(s, "-") {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(p), 64); err == nil && f != 0 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
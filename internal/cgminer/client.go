@@ -0,0 +1,349 @@
+// Package cgminer is a client for the cgminer-style JSON API on TCP 4028 (cgminer, bmminer,
+// btminer, bosminer, luxminer and friends).
+//
+// One command per connection: the request is {"command":"...","parameter":"..."}, the miner
+// writes a JSON document (usually NUL terminated) and closes. Firmware quirks are repaired
+// before decoding: trailing NULs, objects glued together without commas ("}{"), trailing
+// commas, nan/inf numbers and junk after the first complete document.
+package cgminer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultPort = 4028
+
+// Client talks to one miner. The zero value is not usable; use New or fill Host.
+type Client struct {
+	Host string
+	Port int // default 4028
+
+	DialTimeout time.Duration // default 1.5s
+	Timeout     time.Duration // whole exchange after connect (default 5s)
+
+	// Dial overrides the dialer. Optional.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+func New(host string) *Client {
+	return &Client{Host: host, Port: DefaultPort, DialTimeout: 1500 * time.Millisecond, Timeout: 5 * time.Second}
+}
+
+// Status is one STATUS entry of a response.
+type Status struct {
+	Status      string `json:"STATUS"` // S(uccess) I(nfo) W(arning) E(rror) F(atal)
+	When        Number `json:"When"`
+	Code        Number `json:"Code"`
+	Msg         string `json:"Msg"`
+	Description string `json:"Description"`
+}
+
+// APIError is a STATUS=E/F answer (bad command, access denied, invalid pool id...).
+type APIError struct {
+	Command string
+	Code    int
+	Msg     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("cgminer %s: code %d: %s", e.Command, e.Code, e.Msg)
+}
+
+// Code 45 is cgminer's "Access denied to '%s' command" (privileged command without W: in api-allow).
+const codeAccessDenied = 45
+
+// IsAccessDenied reports whether err is a privileged-command rejection.
+func IsAccessDenied(err error) bool {
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.Code == codeAccessDenied || strings.Contains(strings.ToLower(ae.Msg), "access denied")
+	}
+	return false
+}
+
+// Response is a decoded reply: STATUS plus the named sections (SUMMARY, POOLS, STATS, ...).
+type Response struct {
+	Status   []Status
+	Sections map[string]json.RawMessage
+	Raw      string // repaired JSON text
+}
+
+// Section returns a section by name (case-insensitive), nil when absent.
+func (r *Response) Section(name string) json.RawMessage {
+	if r == nil {
+		return nil
+	}
+	if v, ok := r.Sections[name]; ok {
+		return v
+	}
+	for k, v := range r.Sections {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Decode unmarshals section name into v.
+func (r *Response) Decode(name string, v any) error {
+	raw := r.Section(name)
+	if raw == nil {
+		return fmt.Errorf("cgminer: no %s section", name)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Call sends a command (parameter may be empty) and returns the parsed response.
+// STATUS=E/F comes back as *APIError together with the response.
+func (c *Client) Call(ctx context.Context, command, parameter string) (*Response, error) {
+	b, err := c.roundTrip(ctx, command, parameter)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("cgminer %s: %w", command, err)
+	}
+	for _, st := range resp.Status {
+		switch strings.ToUpper(strings.TrimSpace(st.Status)) {
+		case "E", "F":
+			return resp, &APIError{Command: command, Code: int(st.Code), Msg: st.Msg}
+		}
+	}
+	return resp, nil
+}
+
+// CallRaw returns the repaired JSON text without interpreting it.
+func (c *Client) CallRaw(ctx context.Context, command, parameter string) (string, error) {
+	b, err := c.roundTrip(ctx, command, parameter)
+	if err != nil {
+		return "", err
+	}
+	return string(Repair(b)), nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, command, parameter string) ([]byte, error) {
+	port := c.Port
+	if port <= 0 {
+		port = DefaultPort
+	}
+	dialTimeout := c.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 1500 * time.Millisecond
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(port))
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if c.Dial != nil {
+		conn, err = c.Dial(dctx, "tcp", addr)
+	} else {
+		d := net.Dialer{Timeout: dialTimeout}
+		conn, err = d.DialContext(dctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = conn.SetDeadline(deadline)
+
+	req := map[string]string{"command": command}
+	if parameter != "" {
+		req["parameter"] = parameter
+	}
+	rb, _ := json.Marshal(req)
+	if _, err := conn.Write(rb); err != nil {
+		return nil, err
+	}
+
+	// Read until NUL terminator, EOF or 4 MiB (estats on big Avalon chains is large).
+	var buf bytes.Buffer
+	chunk := make([]byte, 32*1024)
+	for buf.Len() < 4<<20 {
+		n, rerr := conn.Read(chunk)
+		if n > 0 {
+			buf.Write(chunk[:n])
+			if bytes.IndexByte(chunk[:n], 0) >= 0 {
+				break
+			}
+		}
+		if rerr != nil {
+			if errors.Is(rerr, io.EOF) || buf.Len() > 0 {
+				break
+			}
+			return nil, rerr
+		}
+	}
+	if buf.Len() == 0 {
+		return nil, fmt.Errorf("cgminer %s: empty response", command)
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse repairs and decodes a raw reply.
+func Parse(b []byte) (*Response, error) {
+	fixed := Repair(b)
+	if len(fixed) == 0 {
+		return nil, errors.New("empty response")
+	}
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(fixed, &top); err != nil {
+		return nil, err
+	}
+	out := &Response{Sections: map[string]json.RawMessage{}, Raw: string(fixed)}
+	for k, v := range top {
+		if strings.EqualFold(k, "STATUS") {
+			var sts []Status
+			if json.Unmarshal(v, &sts) != nil {
+				var one Status
+				if json.Unmarshal(v, &one) == nil {
+					sts = []Status{one}
+				}
+			}
+			out.Status = sts
+			continue
+		}
+		out.Sections[k] = v
+	}
+	return out, nil
+}
+
+var nanRe = regexp.MustCompile(`:\s*-?(?i:nan|inf(inity)?)\b`)
+
+// Repair turns a miner reply into valid JSON where possible (see package doc). Input that is
+// already valid is returned trimmed but otherwise untouched.
+func Repair(b []byte) []byte {
+	s := bytes.TrimSpace(bytes.ReplaceAll(b, []byte{0}, nil))
+	if len(s) == 0 || json.Valid(s) {
+		return s
+	}
+	s = nanRe.ReplaceAll(s, []byte(":0"))
+	s = fixSeparators(s)
+	if json.Valid(s) {
+		return s
+	}
+	if first := firstDocument(s); first != nil && json.Valid(first) {
+		return first
+	}
+	return s
+}
+
+// fixSeparators inserts missing commas between adjacent objects/arrays ("}{", "]["
+// and "}[") and drops trailing commas before a closing bracket; string contents are kept.
+func fixSeparators(s []byte) []byte {
+	out := make([]byte, 0, len(s)+16)
+	inStr, esc := false, false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if inStr {
+			out = append(out, ch)
+			switch {
+			case esc:
+				esc = false
+			case ch == '\\':
+				esc = true
+			case ch == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inStr = true
+		case '{', '[':
+			if p := lastNonSpace(out); p == '}' || p == ']' {
+				out = append(out, ',')
+			}
+		case '}', ']':
+			out = trimTrailingComma(out)
+		}
+		out = append(out, ch)
+	}
+	return out
+}
+
+func lastNonSpace(b []byte) byte {
+	for i := len(b) - 1; i >= 0; i-- {
+		switch b[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b[i]
+	}
+	return 0
+}
+
+func trimTrailingComma(b []byte) []byte {
+	for i := len(b) - 1; i >= 0; i-- {
+		switch b[i] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case ',':
+			return append(b[:i], b[i+1:]...)
+		}
+		return b
+	}
+	return b
+}
+
+// firstDocument returns the first balanced top-level object (replies followed by garbage or
+// by a second document).
+func firstDocument(s []byte) []byte {
+	start := bytes.IndexByte(s, '{')
+	if start < 0 {
+		return nil
+	}
+	depth := 0
+	inStr, esc := false, false
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		if inStr {
+			switch {
+			case esc:
+				esc = false
+			case ch == '\\':
+				esc = true
+			case ch == '"':
+				inStr = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inStr = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return s[start : i+1]
+			}
+		}
+	}
+	return nil
+}
+
+// EscapeParam escapes a value for comma-separated parameters (addpool url,user,pass).
+func EscapeParam(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return strings.ReplaceAll(v, ",", `\,`)
+}
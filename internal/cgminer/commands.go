@@ -0,0 +1,182 @@
+package cgminer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+func first[T any](c *Client, ctx context.Context, command, section string) (T, error) {
+	var zero T
+	resp, err := c.Call(ctx, command, "")
+	if err != nil {
+		return zero, err
+	}
+	var list []T
+	if err := resp.Decode(section, &list); err != nil {
+		return zero, fmt.Errorf("cgminer %s: %w", command, err)
+	}
+	if len(list) == 0 {
+		return zero, fmt.Errorf("cgminer %s: empty %s", command, section)
+	}
+	return list[0], nil
+}
+
+func list[T any](c *Client, ctx context.Context, command, section string) ([]T, error) {
+	resp, err := c.Call(ctx, command, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Section(section) == nil {
+		// no devices/pools configured: STATUS only
+		return nil, nil
+	}
+	var out []T
+	if err := resp.Decode(section, &out); err != nil {
+		return nil, fmt.Errorf("cgminer %s: %w", command, err)
+	}
+	return out, nil
+}
+
+func (c *Client) Summary(ctx context.Context) (Summary, error) {
+	return first[Summary](c, ctx, "summary", "SUMMARY")
+}
+
+func (c *Client) Pools(ctx context.Context) ([]Pool, error) {
+	return list[Pool](c, ctx, "pools", "POOLS")
+}
+
+func (c *Client) Devs(ctx context.Context) ([]Dev, error) {
+	return list[Dev](c, ctx, "devs", "DEVS")
+}
+
+func (c *Client) Stats(ctx context.Context) ([]StatsEntry, error) {
+	return list[StatsEntry](c, ctx, "stats", "STATS")
+}
+
+// Estats is the extended stats command (Avalon MM ID lines, per-board detail).
+func (c *Client) Estats(ctx context.Context) ([]StatsEntry, error) {
+	return list[StatsEntry](c, ctx, "estats", "STATS")
+}
+
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	return first[Version](c, ctx, "version", "VERSION")
+}
+
+func (c *Client) Config(ctx context.Context) (Config, error) {
+	return first[Config](c, ctx, "config", "CONFIG")
+}
+
+func (c *Client) Coin(ctx context.Context) (Coin, error) {
+	return first[Coin](c, ctx, "coin", "COIN")
+}
+
+// Privileged commands (need W: access in api-allow). Success is a STATUS=S reply.
+
+// Restart restarts the mining software. The miner may close the socket before answering.
+func (c *Client) Restart(ctx context.Context) error {
+	_, err := c.Call(ctx, "restart", "")
+	if err != nil && sessionutil.IsDropAfterSend(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *Client) SwitchPool(ctx context.Context, id int) error {
+	_, err := c.Call(ctx, "switchpool", strconv.Itoa(id))
+	return err
+}
+
+func (c *Client) EnablePool(ctx context.Context, id int) error {
+	_, err := c.Call(ctx, "enablepool", strconv.Itoa(id))
+	return err
+}
+
+func (c *Client) DisablePool(ctx context.Context, id int) error {
+	_, err := c.Call(ctx, "disablepool", strconv.Itoa(id))
+	return err
+}
+
+func (c *Client) RemovePool(ctx context.Context, id int) error {
+	_, err := c.Call(ctx, "removepool", strconv.Itoa(id))
+	return err
+}
+
+// AddPool appends a pool (it becomes the lowest priority). cgminer does not persist it
+// across restarts unless the config is saved.
+func (c *Client) AddPool(ctx context.Context, url, user, pass string) error {
+	if strings.TrimSpace(url) == "" {
+		return errors.New("cgminer addpool: empty url")
+	}
+	_, err := c.Call(ctx, "addpool", EscapeParam(url)+","+EscapeParam(user)+","+EscapeParam(pass))
+	return err
+}
+
+// Fans collects fanN keys from STATS (bmminer: fan1..fan4, zeros kept for stopped fans).
+func Fans(stats []StatsEntry) []int {
+	m := map[int]int{}
+	for _, e := range stats {
+		for k, v := range e {
+			n, ok := suffixIndex(k, "fan")
+			if !ok {
+				continue
+			}
+			rpm := int(StatsEntry{"v": v}.Float("v"))
+			if rpm > 0 || m[n] == 0 {
+				m[n] = rpm
+			}
+		}
+	}
+	return dense(m)
+}
+
+// Temps collects tempN keys from STATS (board temps on bmminer), ignoring zeros.
+func Temps(stats []StatsEntry) []float64 {
+	m := map[int]float64{}
+	for _, e := range stats {
+		for k, v := range e {
+			n, ok := suffixIndex(k, "temp")
+			if !ok {
+				continue
+			}
+			if t := (StatsEntry{"v": v}).Float("v"); t != 0 {
+				m[n] = t
+			}
+		}
+	}
+	return dense(m)
+}
+
+// suffixIndex parses "fan3" -> 3 for prefix "fan" (pure numeric suffix, 1..8 only).
+func suffixIndex(k, prefix string) (int, bool) {
+	k = strings.ToLower(strings.TrimSpace(k))
+	if !strings.HasPrefix(k, prefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(k[len(prefix):])
+	if err != nil || n < 1 || n > 8 {
+		return 0, false
+	}
+	return n, true
+}
+
+func dense[T int | float64](m map[int]T) []T {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	out := make([]T, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, m[k])
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package cgminer
+
+import (
+	"context"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver reads the cgminer API on 4028 (no credentials). It is the fallback for vendors
+// without a dedicated driver and for boxes whose web UI rejected every credential.
+type driver struct{}
+
+func (driver) Name() string { return "cgminer" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "non-asic" || !t.HasPort(DefaultPort) {
+		return sdk.ConfidenceNone
+	}
+	// below every vendor fallback: a bare cgminer read says nothing about credentials
+	return 1
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, _ []sdk.Cred) sdk.ProbeResult {
+	snap, err := New(t.IP).Snapshot(ctx)
+	if err != nil {
+		return sdk.ProbeResult{Error: err.Error()}
+	}
+	return sdk.ProbeResult{OK: true, Reply: snap}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	snap, ok := pr.Reply.(Snapshot)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := sdk.Facts{
+		Model:       snap.Model(),
+		Firmware:    snap.Firmware(),
+		Worker:      snap.Worker(),
+		HashrateTHS: snap.Summary.HashrateTHS(),
+		FansRPM:     snap.Fans(),
+		TempsC:      snap.Temps(),
+		Chains:      Chains(snap.Stats),
+		Pools:       PoolStatuses(snap.Pools),
+	}
+	if up := snap.Summary.Elapsed.Int(); up > 0 {
+		f.UptimeS = uint64(up)
+	}
+	return f
+}
+
+func (driver) Commands() sdk.Commands { return sdk.Commands{} }
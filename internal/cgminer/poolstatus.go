@@ -0,0 +1,96 @@
+package cgminer
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// PoolStatuses maps the pools command onto the shared pool model. Without a Stratum Active
+// flag the first alive pool by priority is taken as active.
+func PoolStatuses(ps []Pool) []sdk.PoolStatus {
+	return poolStatuses(ps, time.Now())
+}
+
+func poolStatuses(ps []Pool, now time.Time) []sdk.PoolStatus {
+	out := make([]sdk.PoolStatus, 0, len(ps))
+	for _, p := range ps {
+		if strings.TrimSpace(p.URL) == "" {
+			continue
+		}
+		st := sdk.PoolStatus{
+			URL:       strings.TrimSpace(p.URL),
+			User:      strings.TrimSpace(p.User),
+			Status:    strings.ToLower(strings.TrimSpace(p.Status)),
+			Priority:  int(p.Priority.Int()),
+			Active:    bool(p.StratumActive),
+			Accepted:  count(p.Accepted),
+			Rejected:  count(p.Rejected),
+			Stale:     count(p.Stale),
+			LastShare: ShareTime(string(p.LastShareTime), now),
+		}
+		st.Difficulty = ParseDiff(string(p.Diff))
+		if st.Difficulty == 0 {
+			st.Difficulty = p.LastShareDifficulty.Float()
+		}
+		out = append(out, st)
+	}
+	sdk.MarkActive(out)
+	return out
+}
+
+// ShareTime reads a last-share time: unix seconds (cgminer) or "h:mm:ss" ago (bmminer).
+func ShareTime(s string, now time.Time) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return time.Time{}
+	}
+	if strings.Contains(s, ":") {
+		var secs int64
+		for _, p := range strings.Split(s, ":") {
+			n, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+			if err != nil {
+				return time.Time{}
+			}
+			secs = secs*60 + n
+		}
+		return now.Add(-time.Duration(secs) * time.Second).UTC().Truncate(time.Second)
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 1e9 {
+		return time.Unix(n, 0).UTC()
+	}
+	return time.Time{}
+}
+
+// ParseDiff reads pool difficulty as printed by miners ("65.5K", "1.05M", "8192").
+func ParseDiff(s string) float64 {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1e3
+	case strings.HasSuffix(s, "M"):
+		mult = 1e6
+	case strings.HasSuffix(s, "G"):
+		mult = 1e9
+	case strings.HasSuffix(s, "T"):
+		mult = 1e12
+	}
+	if mult != 1 {
+		s = strings.TrimSpace(s[:len(s)-1])
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0
+	}
+	return f * mult
+}
+
+func count(n Number) uint64 {
+	if n < 0 {
+		return 0
+	}
+	return uint64(n)
+}
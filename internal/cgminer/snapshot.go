@@ -0,0 +1,90 @@
+package cgminer
+
+import (
+	"context"
+	"strings"
+)
+
+// Snapshot is the read-only command set used by discovery and polling. Only summary is
+// required; the other sections are best-effort (older firmwares lack some commands).
+type Snapshot struct {
+	Summary Summary
+	Pools   []Pool
+	Devs    []Dev
+	Stats   []StatsEntry
+}
+
+func (c *Client) Snapshot(ctx context.Context) (Snapshot, error) {
+	var s Snapshot
+	sum, err := c.Summary(ctx)
+	if err != nil {
+		return s, err
+	}
+	s.Summary = sum
+	if ps, err := c.Pools(ctx); err == nil {
+		s.Pools = ps
+	}
+	if ds, err := c.Devs(ctx); err == nil {
+		s.Devs = ds
+	}
+	if st, err := c.Stats(ctx); err == nil {
+		s.Stats = st
+	}
+	return s, nil
+}
+
+// Worker is the user of the active pool (stratum active), else of the first pool with a user.
+func (s Snapshot) Worker() string {
+	for _, p := range s.Pools {
+		if p.StratumActive && strings.TrimSpace(p.User) != "" {
+			return p.User
+		}
+	}
+	for _, p := range s.Pools {
+		if strings.TrimSpace(p.User) != "" {
+			return p.User
+		}
+	}
+	return ""
+}
+
+// Model is the raw model string (devs first, then stats); normalize with modelnorm.
+func (s Snapshot) Model() string {
+	if len(s.Devs) > 0 {
+		d := s.Devs[0]
+		for _, v := range []string{d.Model, d.Name, d.Description} {
+			if strings.TrimSpace(v) != "" {
+				return v
+			}
+		}
+	}
+	for _, e := range s.Stats {
+		if v := e.String("Type", "Model", "Product", "Miner Type", "miner_type", "Device Model"); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Firmware is the firmware/miner version string from stats, if any.
+func (s Snapshot) Firmware() string {
+	for _, e := range s.Stats {
+		if v := e.String("Firmware Version", "firmware", "version", "Miner Version", "BMMiner Version"); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Chip is the ASIC chip type (e.g. BM1370) when stats report it, upper-cased.
+func (s Snapshot) Chip() string {
+	for _, e := range s.Stats {
+		if v := e.String("Chip Type", "ChipType", "ASIC", "asic"); v != "" {
+			return strings.ToUpper(v)
+		}
+	}
+	return ""
+}
+
+func (s Snapshot) Fans() []int      { return Fans(s.Stats) }
+func (s Snapshot) Temps() []float64 { return Temps(s.Stats) }
@@ -0,0 +1,281 @@
+package cgminer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Number accepts JSON numbers and numeric strings ("13,528.15", " 72 "); anything else
+// decodes as 0 instead of failing the whole response (bmminer quotes most numbers).
+type Number float64
+
+func (n *Number) UnmarshalJSON(b []byte) error {
+	*n = 0
+	s := strings.TrimSpace(string(b))
+	if s == "" || s == "null" {
+		return nil
+	}
+	if s == "true" {
+		*n = 1
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var str string
+		if json.Unmarshal(b, &str) != nil {
+			return nil
+		}
+		s = strings.ReplaceAll(strings.TrimSpace(str), ",", "")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		*n = Number(f)
+	}
+	return nil
+}
+
+func (n Number) Float() float64 { return float64(n) }
+func (n Number) Int() int64     { return int64(n) }
+
+// Text accepts strings and numbers ("Diff": "65.5K" on bmminer, 65536 on cgminer).
+type Text string
+
+func (t *Text) UnmarshalJSON(b []byte) error {
+	*t = ""
+	s := strings.TrimSpace(string(b))
+	if s == "" || s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var str string
+		if json.Unmarshal(b, &str) == nil {
+			*t = Text(str)
+		}
+		return nil
+	}
+	*t = Text(s)
+	return nil
+}
+
+// Bool accepts true/false, "Y"/"N", "true"/"false", 1/0.
+type Bool bool
+
+func (v *Bool) UnmarshalJSON(b []byte) error {
+	s := strings.ToLower(strings.Trim(strings.TrimSpace(string(b)), `"`))
+	*v = Bool(s == "true" || s == "y" || s == "yes" || s == "1")
+	return nil
+}
+
+// Summary is SUMMARY[0].
+type Summary struct {
+	Elapsed            Number `json:"Elapsed"`
+	MHSav              Number `json:"MHS av"`
+	MHS5s              Number `json:"MHS 5s"`
+	MHS1m              Number `json:"MHS 1m"`
+	MHS15m             Number `json:"MHS 15m"`
+	GHS5s              Number `json:"GHS 5s"`
+	GHSav              Number `json:"GHS av"`
+	FoundBlocks        Number `json:"Found Blocks"`
+	Getworks           Number `json:"Getworks"`
+	Accepted           Number `json:"Accepted"`
+	Rejected           Number `json:"Rejected"`
+	HardwareErrors     Number `json:"Hardware Errors"`
+	Utility            Number `json:"Utility"`
+	Discarded          Number `json:"Discarded"`
+	Stale              Number `json:"Stale"`
+	GetFailures        Number `json:"Get Failures"`
+	LocalWork          Number `json:"Local Work"`
+	RemoteFailures     Number `json:"Remote Failures"`
+	NetworkBlocks      Number `json:"Network Blocks"`
+	TotalMH            Number `json:"Total MH"`
+	WorkUtility        Number `json:"Work Utility"`
+	DifficultyAccepted Number `json:"Difficulty Accepted"`
+	DifficultyRejected Number `json:"Difficulty Rejected"`
+	DifficultyStale    Number `json:"Difficulty Stale"`
+	BestShare          Number `json:"Best Share"`
+	DeviceHardwarePct  Number `json:"Device Hardware%"`
+	DeviceRejectedPct  Number `json:"Device Rejected%"`
+	PoolRejectedPct    Number `json:"Pool Rejected%"`
+	PoolStalePct       Number `json:"Pool Stale%"`
+	LastGetwork        Number `json:"Last getwork"`
+}
+
+// HashrateTHS picks the freshest hashrate the firmware reports, in TH/s.
+func (s Summary) HashrateTHS() float64 {
+	switch {
+	case s.GHS5s > 0:
+		return s.GHS5s.Float() / 1e3
+	case s.GHSav > 0:
+		return s.GHSav.Float() / 1e3
+	case s.MHS5s > 0:
+		return s.MHS5s.Float() / 1e6
+	case s.MHS1m > 0:
+		return s.MHS1m.Float() / 1e6
+	case s.MHSav > 0:
+		return s.MHSav.Float() / 1e6
+	}
+	return 0
+}
+
+// Pool is one POOLS entry.
+type Pool struct {
+	ID                  Number `json:"POOL"`
+	URL                 string `json:"URL"`
+	Status              string `json:"Status"` // Alive / Dead / Disabled / Rejecting
+	Priority            Number `json:"Priority"`
+	Quota               Number `json:"Quota"`
+	LongPoll            Text   `json:"Long Poll"`
+	Getworks            Number `json:"Getworks"`
+	Accepted            Number `json:"Accepted"`
+	Rejected            Number `json:"Rejected"`
+	Works               Number `json:"Works"`
+	Discarded           Number `json:"Discarded"`
+	Stale               Number `json:"Stale"`
+	GetFailures         Number `json:"Get Failures"`
+	RemoteFailures      Number `json:"Remote Failures"`
+	User                string `json:"User"`
+	LastShareTime       Text   `json:"Last Share Time"` // unix seconds or "0:00:05" (bmminer)
+	Diff                Text   `json:"Diff"`
+	Diff1Shares         Number `json:"Diff1 Shares"`
+	ProxyType           string `json:"Proxy Type"`
+	Proxy               string `json:"Proxy"`
+	DifficultyAccepted  Number `json:"Difficulty Accepted"`
+	DifficultyRejected  Number `json:"Difficulty Rejected"`
+	DifficultyStale     Number `json:"Difficulty Stale"`
+	LastShareDifficulty Number `json:"Last Share Difficulty"`
+	HasStratum          Bool   `json:"Has Stratum"`
+	StratumActive       Bool   `json:"Stratum Active"`
+	StratumURL          string `json:"Stratum URL"`
+	BestShare           Number `json:"Best Share"`
+	PoolRejectedPct     Number `json:"Pool Rejected%"`
+	PoolStalePct        Number `json:"Pool Stale%"`
+	CurrentBlockHeight  Number `json:"Current Block Height"`
+}
+
+// Dev is one DEVS entry (ASC/PGA/GPU).
+type Dev struct {
+	ASC               Number `json:"ASC"`
+	PGA               Number `json:"PGA"`
+	GPU               Number `json:"GPU"`
+	Name              string `json:"Name"`
+	ID                Number `json:"ID"`
+	Enabled           Bool   `json:"Enabled"`
+	Status            string `json:"Status"`
+	Temperature       Number `json:"Temperature"`
+	MHSav             Number `json:"MHS av"`
+	MHS5s             Number `json:"MHS 5s"`
+	MHS1m             Number `json:"MHS 1m"`
+	Accepted          Number `json:"Accepted"`
+	Rejected          Number `json:"Rejected"`
+	HardwareErrors    Number `json:"Hardware Errors"`
+	Utility           Number `json:"Utility"`
+	LastSharePool     Number `json:"Last Share Pool"`
+	LastShareTime     Number `json:"Last Share Time"`
+	TotalMH           Number `json:"Total MH"`
+	DeviceHardwarePct Number `json:"Device Hardware%"`
+	DeviceRejectedPct Number `json:"Device Rejected%"`
+	DeviceElapsed     Number `json:"Device Elapsed"`
+
+	// Non-cgminer extras seen in the wild (btminer, bosminer).
+	Model       string `json:"Model"`
+	Description string `json:"Description"`
+}
+
+// Version is VERSION[0]. Field names differ per miner; All keeps every string value.
+type Version struct {
+	API         string
+	CGMiner     string
+	BMMiner     string
+	Miner       string
+	CompileTime string
+	Type        string
+	All         map[string]string
+}
+
+func (v *Version) UnmarshalJSON(b []byte) error {
+	var m map[string]Text
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	v.All = make(map[string]string, len(m))
+	for k, t := range m {
+		v.All[k] = string(t)
+	}
+	v.API = v.All["API"]
+	v.CGMiner = v.All["CGMiner"]
+	v.BMMiner = v.All["BMMiner"]
+	v.Miner = v.All["Miner"]
+	v.CompileTime = v.All["CompileTime"]
+	v.Type = v.All["Type"]
+	return nil
+}
+
+// MinerSoftware returns "<name> <version>" for the mining software that answered
+// (e.g. "BMMiner 2.0.0", "LUXminer 2024.5.1", "BOSer ..."), or "".
+func (v Version) MinerSoftware() string {
+	for _, k := range []string{"LUXminer", "BOSminer", "BOSer", "BTMiner", "BMMiner", "CGMiner", "Miner"} {
+		if s := strings.TrimSpace(v.All[k]); s != "" {
+			return k + " " + s
+		}
+	}
+	return ""
+}
+
+// Firmware names aftermarket firmware recognisable from VERSION alone
+// ("Braiins OS <bosminer version>", "LuxOS <version>"), or "" for stock / unknown firmware.
+func (v Version) Firmware() string {
+	if s := strings.TrimSpace(v.All["LUXminer"]); s != "" {
+		return "LuxOS " + s
+	}
+	for _, k := range []string{"BOSer", "BOSminer"} {
+		if s := strings.TrimSpace(v.All[k]); s != "" {
+			return "Braiins OS " + s
+		}
+	}
+	return ""
+}
+
+// Config is CONFIG[0].
+type Config struct {
+	ASCCount    Number `json:"ASC Count"`
+	PGACount    Number `json:"PGA Count"`
+	PoolCount   Number `json:"Pool Count"`
+	Strategy    string `json:"Strategy"`
+	LogInterval Number `json:"Log Interval"`
+	DeviceCode  string `json:"Device Code"`
+	OS          string `json:"OS"`
+	Hotplug     Text   `json:"Hotplug"`
+}
+
+// Coin is COIN[0].
+type Coin struct {
+	HashMethod        string `json:"Hash Method"`
+	CurrentBlockTime  Number `json:"Current Block Time"`
+	CurrentBlockHash  string `json:"Current Block Hash"`
+	LP                Bool   `json:"LP"`
+	NetworkDifficulty Number `json:"Network Difficulty"`
+}
+
+// StatsEntry is one STATS element. Layout is firmware specific, so it stays a map with helpers.
+type StatsEntry map[string]any
+
+// String returns the first non-empty string value among keys.
+func (e StatsEntry) String(keys ...string) string {
+	for _, k := range keys {
+		if s, ok := e[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// Float returns a numeric value (numbers or numeric strings), 0 when absent.
+func (e StatsEntry) Float(key string) float64 {
+	switch v := e[key].(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,16 @@
+// Package drivers links every vendor driver into a binary. Each package below registers
+// itself with sdk.Register from init; adding a vendor means adding its import here.
+package drivers
+
+import (
+	_ "asic-control/internal/antminer/httpapi"
+	_ "asic-control/internal/avalon"
+	_ "asic-control/internal/braiins/httpapi"
+	_ "asic-control/internal/cgminer"
+	_ "asic-control/internal/elphapex/httpapi"
+	_ "asic-control/internal/goldshell/httpapi"
+	_ "asic-control/internal/iceriver/httpapi"
+	_ "asic-control/internal/luxos"
+	_ "asic-control/internal/vnish/httpapi"
+	_ "asic-control/internal/whatsminer/httpapi"
+)
@@ -0,0 +1,208 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jhump/protoreflect/dynamic"
+
+	"asic-control/internal/events"
+)
+
+// Raw encodes facts into the PollResult.raw map (string carrier until the schema is pinned).
+func (f Facts) Raw() map[string]string {
+	m := map[string]string{}
+	put := func(k, v string) {
+		if v != "" {
+			m[k] = v
+		}
+	}
+	put("vendor", f.Vendor)
+	put("model", f.Model)
+	put("firmware", f.Firmware)
+	put("worker", f.Worker)
+	put("mac", f.MAC)
+	put("work_mode", f.WorkMode)
+	if f.PowerW > 0 {
+		m["power_w"] = strconv.FormatFloat(f.PowerW, 'f', -1, 64)
+	}
+	if f.UptimeS > 0 {
+		m["uptime_s"] = strconv.FormatUint(f.UptimeS, 10)
+	}
+	if f.HashrateTHS > 0 {
+		m["hashrate_ths"] = strconv.FormatFloat(f.HashrateTHS, 'f', -1, 64)
+	}
+	if len(f.FansRPM) > 0 {
+		parts := make([]string, 0, len(f.FansRPM))
+		for _, v := range f.FansRPM {
+			parts = append(parts, strconv.Itoa(v))
+		}
+		m["fans_rpm"] = strings.Join(parts, ",")
+	}
+	if len(f.TempsC) > 0 {
+		parts := make([]string, 0, len(f.TempsC))
+		for _, v := range f.TempsC {
+			parts = append(parts, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		m["temps_c"] = strings.Join(parts, ",")
+	}
+	if len(f.Chains) > 0 {
+		if b, err := json.Marshal(f.Chains); err == nil {
+			m["chains"] = string(b)
+		}
+	}
+	if len(f.Pools) > 0 {
+		if b, err := json.Marshal(f.Pools); err == nil {
+			m["pools"] = string(b)
+		}
+	}
+	return m
+}
+
+// FactsFromRaw is the inverse of Facts.Raw (unknown keys are ignored).
+func FactsFromRaw(m map[string]string) Facts {
+	f := Facts{
+		Vendor:   m["vendor"],
+		Model:    m["model"],
+		Firmware: m["firmware"],
+		Worker:   m["worker"],
+		MAC:      m["mac"],
+		WorkMode: m["work_mode"],
+	}
+	f.PowerW, _ = strconv.ParseFloat(m["power_w"], 64)
+	f.UptimeS, _ = strconv.ParseUint(m["uptime_s"], 10, 64)
+	f.HashrateTHS, _ = strconv.ParseFloat(m["hashrate_ths"], 64)
+	for _, s := range strings.Split(m["fans_rpm"], ",") {
+		if v, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+			f.FansRPM = append(f.FansRPM, v)
+		}
+	}
+	for _, s := range strings.Split(m["temps_c"], ",") {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			f.TempsC = append(f.TempsC, v)
+		}
+	}
+	if s := m["chains"]; s != "" {
+		_ = json.Unmarshal([]byte(s), &f.Chains)
+	}
+	if s := m["pools"]; s != "" {
+		_ = json.Unmarshal([]byte(s), &f.Pools)
+	}
+	return f
+}
+
+// StringMap converts a dynamic proto map field (map[interface{}]interface{}) to map[string]string.
+func StringMap(v any) map[string]string {
+	out := map[string]string{}
+	if m, ok := v.(map[interface{}]interface{}); ok {
+		for k, val := range m {
+			ks, _ := k.(string)
+			vs, _ := val.(string)
+			out[ks] = vs
+		}
+	}
+	return out
+}
+
+// EncodePollRequest builds a poll.request envelope for t.
+func EncodePollRequest(schema *events.Schema, t Target) ([]byte, error) {
+	env := schema.NewEnvelope(events.PollRequest)
+	env.SetFieldByName("device_id", t.DeviceID)
+	env.SetFieldByName("ip", t.IP)
+	pr := dynamic.NewMessage(schema.PollRequest)
+	pr.SetFieldByName("device_id", t.DeviceID)
+	pr.SetFieldByName("ip", t.IP)
+	pr.SetFieldByName("vendor", t.Vendor)
+	pr.SetFieldByName("firmware", t.Firmware)
+	pr.SetFieldByName("profile", t.Profile)
+	if len(t.OpenPorts) > 0 {
+		ports := make([]uint32, 0, len(t.OpenPorts))
+		for _, p := range t.OpenPorts {
+			ports = append(ports, uint32(p))
+		}
+		pr.SetFieldByName("open_ports", ports)
+	}
+	env.SetFieldByName("poll_request", pr)
+	return events.Marshal(env)
+}
+
+// DecodePollRequest parses a poll.request envelope.
+func DecodePollRequest(schema *events.Schema, b []byte) (Target, error) {
+	env, err := events.UnmarshalEnvelope(schema, b)
+	if err != nil {
+		return Target{}, err
+	}
+	pr, ok := env.GetFieldByName("poll_request").(*dynamic.Message)
+	if !ok || pr == nil {
+		return Target{}, errors.New("poll.request: missing payload")
+	}
+	t := Target{
+		DeviceID: pr.GetFieldByName("device_id").(string),
+		IP:       pr.GetFieldByName("ip").(string),
+		Vendor:   pr.GetFieldByName("vendor").(string),
+		Firmware: pr.GetFieldByName("firmware").(string),
+		Profile:  pr.GetFieldByName("profile").(string),
+	}
+	if ports, ok := pr.GetFieldByName("open_ports").([]interface{}); ok {
+		for _, p := range ports {
+			if v, ok := p.(uint32); ok {
+				t.OpenPorts = append(t.OpenPorts, int(v))
+			}
+		}
+	}
+	if t.IP == "" {
+		t.IP = env.GetFieldByName("ip").(string)
+	}
+	if t.IP == "" {
+		return Target{}, errors.New("poll.request: empty ip")
+	}
+	return t, nil
+}
+
+// EncodePollResult builds a poll.result envelope (facts in raw, plus driver/cred/timing).
+func EncodePollResult(schema *events.Schema, shardID string, p Poll) ([]byte, error) {
+	env := schema.NewEnvelope(events.PollResult)
+	env.SetFieldByName("shard_id", shardID)
+	env.SetFieldByName("device_id", p.Target.DeviceID)
+	env.SetFieldByName("ip", p.Target.IP)
+	env.SetFieldByName("mac", p.Facts.MAC)
+	raw := p.Facts.Raw()
+	raw["driver"] = p.Driver
+	raw["used_cred"] = p.UsedCred
+	raw["duration_ms"] = strconv.FormatInt(p.DurationMS, 10)
+	pr := dynamic.NewMessage(schema.PollResult)
+	pr.SetFieldByName("device_id", p.Target.DeviceID)
+	pr.SetFieldByName("ok", p.OK)
+	pr.SetFieldByName("error", p.Error)
+	pr.SetFieldByName("raw", raw)
+	env.SetFieldByName("poll_result", pr)
+	return events.Marshal(env)
+}
+
+// DecodePollResult parses a poll.result envelope back into a Poll.
+func DecodePollResult(schema *events.Schema, b []byte) (Poll, error) {
+	env, err := events.UnmarshalEnvelope(schema, b)
+	if err != nil {
+		return Poll{}, err
+	}
+	pr, ok := env.GetFieldByName("poll_result").(*dynamic.Message)
+	if !ok || pr == nil {
+		return Poll{}, errors.New("poll.result: missing payload")
+	}
+	raw := StringMap(pr.GetFieldByName("raw"))
+	p := Poll{
+		Target:   Target{DeviceID: pr.GetFieldByName("device_id").(string), IP: env.GetFieldByName("ip").(string)},
+		OK:       pr.GetFieldByName("ok").(bool),
+		Error:    pr.GetFieldByName("error").(string),
+		Driver:   raw["driver"],
+		UsedCred: raw["used_cred"],
+		Facts:    FactsFromRaw(raw),
+	}
+	p.DurationMS, _ = strconv.ParseInt(raw["duration_ms"], 10, 64)
+	if p.Target.IP == "" {
+		return Poll{}, errors.New("poll.result: empty ip")
+	}
+	return p, nil
+}
@@ -1,9 +1,229 @@
 package sdk
 
-import "context"
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
 
-// Collector is a generic device collector interface.
-type Collector interface {
-	Collect(ctx context.Context) error
-	Name() string
+// Target is a device to poll. It carries hints only; credentials are resolved by the
+// collector process itself and never travel on the bus.
+type Target struct {
+	DeviceID  string `json:"device_id,omitempty"`
+	IP        string `json:"ip"`
+	Vendor    string `json:"vendor,omitempty"`
+	Firmware  string `json:"firmware,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	OpenPorts []int  `json:"open_ports,omitempty"`
+}
+
+// Schemes returns http/https candidates (https only if 443 is known open: TLS hangs on many ASICs).
+func (t Target) Schemes() []string {
+	out := []string{"http"}
+	for _, p := range t.OpenPorts {
+		if p == 443 {
+			out = append(out, "https")
+			break
+		}
+	}
+	return out
+}
+
+// VendorKey is the normalized vendor hint ("" for unknown/generic asic).
+func (t Target) VendorKey() string {
+	v := strings.ToLower(strings.TrimSpace(t.Vendor))
+	if v == "unknown" || v == "asic" {
+		return ""
+	}
+	return v
+}
+
+// Facts is a normalized telemetry snapshot. Zero values mean "not reported".
+type Facts struct {
+	Vendor      string       `json:"vendor,omitempty"`
+	Model       string       `json:"model,omitempty"`
+	Firmware    string       `json:"firmware,omitempty"`
+	Worker      string       `json:"worker,omitempty"`
+	MAC         string       `json:"mac,omitempty"`
+	UptimeS     uint64       `json:"uptime_s,omitempty"`
+	HashrateTHS float64      `json:"hashrate_ths,omitempty"`
+	FansRPM     []int        `json:"fans_rpm,omitempty"`
+	TempsC      []float64    `json:"temps_c,omitempty"`
+	PowerW      float64      `json:"power_w,omitempty"`
+	WorkMode    string       `json:"work_mode,omitempty"`
+	Chains      []Chain      `json:"chains,omitempty"`
+	Pools       []PoolStatus `json:"pools,omitempty"`
+}
+
+// Chain is one hashboard (chain) as reported by the firmware. Index is the firmware's own
+// numbering; zero values mean "not reported".
+type Chain struct {
+	Index         int       `json:"index"`
+	HashrateTHS   float64   `json:"hashrate_ths,omitempty"` // real
+	IdealTHS      float64   `json:"ideal_ths,omitempty"`
+	ChipsExpected int       `json:"chips_expected,omitempty"`
+	Chips         int       `json:"chips,omitempty"` // detected / working
+	ChipTempsC    []float64 `json:"chip_temps_c,omitempty"`
+	PCBTempsC     []float64 `json:"pcb_temps_c,omitempty"`
+	FreqMHz       float64   `json:"freq_mhz,omitempty"`
+	VoltageV      float64   `json:"voltage_v,omitempty"`
+	HWErrors      uint64    `json:"hw_errors,omitempty"`
+}
+
+// PoolStatus is one configured pool as the miner reports it. Status keeps the vendor's
+// wording, lower-cased (alive, dead, disabled, rejecting...); Active marks the pool the miner
+// is hashing on.
+type PoolStatus struct {
+	URL        string    `json:"url"`
+	User       string    `json:"user,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Priority   int       `json:"priority"`
+	Active     bool      `json:"active,omitempty"`
+	Accepted   uint64    `json:"accepted,omitempty"`
+	Rejected   uint64    `json:"rejected,omitempty"`
+	Stale      uint64    `json:"stale,omitempty"`
+	LastShare  time.Time `json:"last_share,omitempty"`
+	Difficulty float64   `json:"difficulty,omitempty"`
+}
+
+// ActivePool returns the pool marked active, if any.
+func ActivePool(ps []PoolStatus) (PoolStatus, bool) {
+	for _, p := range ps {
+		if p.Active {
+			return p, true
+		}
+	}
+	return PoolStatus{}, false
+}
+
+// MarkActive flags the first alive pool by priority when the firmware reports no active pool
+// (stock CGI and some cgminer builds only say which pools are alive).
+func MarkActive(ps []PoolStatus) {
+	if _, ok := ActivePool(ps); ok {
+		return
+	}
+	best := -1
+	for i, p := range ps {
+		if p.Status != "alive" {
+			continue
+		}
+		if best < 0 || p.Priority < ps[best].Priority {
+			best = i
+		}
+	}
+	if best >= 0 {
+		ps[best].Active = true
+	}
+}
+
+// Empty reports whether nothing useful was extracted.
+func (f Facts) Empty() bool {
+	return f.MAC == "" && f.Worker == "" && f.Firmware == "" && f.Model == "" &&
+		f.HashrateTHS == 0 && f.UptimeS == 0 && len(f.FansRPM) == 0 && len(f.TempsC) == 0 && f.PowerW == 0
+}
+
+// Poll is the outcome of one dispatch.
+type Poll struct {
+	Target     Target    `json:"target"`
+	OK         bool      `json:"ok"`
+	Error      string    `json:"error,omitempty"`
+	Driver     string    `json:"driver,omitempty"`
+	UsedCred   string    `json:"used_cred,omitempty"`
+	Facts      Facts     `json:"facts"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+var ErrNoDriver = errors.New("no driver supports this device")
+
+// Dispatcher routes a target to its candidate drivers (see Candidates), most confident
+// first, until one returns parsable facts.
+type Dispatcher struct {
+	drivers []Driver // nil: everything registered
+}
+
+// NewDispatcher uses ds, or every registered driver when ds is empty.
+func NewDispatcher(ds ...Driver) *Dispatcher {
+	return &Dispatcher{drivers: ds}
+}
+
+func (d *Dispatcher) candidates(t Target) []Driver {
+	ds := d.drivers
+	if len(ds) == 0 {
+		ds = Drivers()
+	}
+	return Candidates(ds, t)
+}
+
+func (d *Dispatcher) Poll(ctx context.Context, t Target, creds []Cred) Poll {
+	p, _ := d.Probe(ctx, t, creds)
+	return p
+}
+
+// Probe is Poll that also returns the winning driver's raw reply (or the first failure).
+// The error of the most confident driver is kept: fallbacks rarely explain a failure better.
+// Results are named so the deferred DurationMS lands in the returned Poll.
+func (d *Dispatcher) Probe(ctx context.Context, t Target, creds []Cred) (p Poll, first ProbeResult) {
+	p = Poll{Target: t, StartedAt: time.Now().UTC(), Error: ErrNoDriver.Error()}
+	defer func() { p.DurationMS = time.Since(p.StartedAt).Milliseconds() }()
+	tried := false
+	for _, drv := range d.candidates(t) {
+		if ctx.Err() != nil {
+			if !tried {
+				p.Error = ctx.Err().Error()
+			}
+			break
+		}
+		res := drv.Probe(ctx, t, creds)
+		res.Driver = drv.Name()
+		if res.Responses == nil && res.Reply != nil {
+			res.Responses = map[string]any{drv.Name(): res.Reply}
+		}
+		if res.OK {
+			f := normalize(drv.ExtractFacts(res))
+			if !f.Empty() {
+				p.OK, p.Error, p.Driver, p.UsedCred, p.Facts = true, "", drv.Name(), res.UsedCred, f
+				return p, res
+			}
+			res.OK, res.Error = false, "ok but no parsable data"
+		}
+		if !tried {
+			tried = true
+			first = res
+			p.Driver, p.UsedCred, p.Error = drv.Name(), res.UsedCred, res.Error
+		}
+	}
+	if !tried {
+		first = ProbeResult{Error: p.Error}
+	}
+	return p, first
+}
+
+// ReadLog asks the candidate drivers that implement LogReader, most confident first, until
+// one returns a log.
+func (d *Dispatcher) ReadLog(ctx context.Context, t Target, creds []Cred) LogResult {
+	var first LogResult
+	tried := false
+	for _, drv := range d.candidates(t) {
+		lr, ok := drv.(LogReader)
+		if !ok {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		res := lr.ReadLog(ctx, t, creds)
+		res.Driver = drv.Name()
+		if res.OK {
+			return res
+		}
+		if !tried {
+			tried, first = true, res
+		}
+	}
+	if !tried {
+		return LogResult{Error: "no driver can read logs for this device"}
+	}
+	return first
 }
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"sort"
+	"strings"
+
+	"asic-control/internal/defaultcreds"
+	"asic-control/internal/secrets"
+	"asic-control/internal/settings"
+)
+
+// Cred is a decrypted login candidate. Only Name may be logged or published.
+type Cred struct {
+	Name     string
+	Username string
+	Password string
+}
+
+// FirmwareClass maps a firmware string to the class used by stored credentials (stock/vnish).
+func FirmwareClass(firmware string) string {
+	fw := strings.ToLower(strings.TrimSpace(firmware))
+	if strings.Contains(fw, "vnish") || strings.Contains(fw, "anthill") || strings.Contains(fw, "brains") {
+		return "vnish"
+	}
+	return "stock"
+}
+
+// BuildCreds orders credential candidates for a device: known stock pairs for stock Antminer,
+// then enabled stored credentials (firmware class match first, then priority), then optional
+// built-in defaults. Never returns an empty list ("no-auth" as last resort).
+func BuildCreds(cfg settings.Settings, sec *secrets.Secrets, vendor, firmware string) []Cred {
+	type cand struct {
+		score int
+		cred  Cred
+	}
+	dv := strings.ToLower(strings.TrimSpace(vendor))
+	devClass := FirmwareClass(firmware)
+
+	var cands []cand
+	for _, c := range cfg.Credentials {
+		if !c.Enabled {
+			continue
+		}
+		cv := strings.ToLower(strings.TrimSpace(c.Vendor))
+		if dv != "" && dv != "unknown" && dv != "asic" && cv != "" && cv != dv {
+			continue
+		}
+		user, err := sec.DecryptString(c.UsernameEnc)
+		if err != nil {
+			continue
+		}
+		pass, err := sec.DecryptString(c.PasswordEnc)
+		if err != nil {
+			continue
+		}
+		score := c.Priority
+		if fw := strings.ToLower(strings.TrimSpace(c.Firmware)); fw != "" && fw == devClass {
+			score += 1000
+		}
+		cands = append(cands, cand{score: score, cred: Cred{Name: c.Name, Username: user, Password: pass}})
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].score > cands[j].score })
+
+	out := make([]Cred, 0, len(cands)+10)
+
+	// btcTools-like: for stock antminer, try known stock pairs first (before custom)
+	// to reduce operator friction. Only when device does NOT look like vnish.
+	if devClass == "stock" && (dv == "" || dv == "antminer" || dv == "asic" || dv == "unknown") {
+		out = append(out,
+			Cred{Name: "stock:root/root", Username: "root", Password: "root"},
+			Cred{Name: "stock:root/admin", Username: "root", Password: "admin"},
+		)
+	}
+	for _, x := range cands {
+		out = append(out, x.cred)
+	}
+	if cfg.TryDefaultCreds {
+		// Braiins OS factory default is root with an empty password (keyed by firmware, not vendor).
+		if strings.Contains(strings.ToLower(firmware), "braiins") {
+			out = append(out, Cred{Name: "stock:root/", Username: "root"})
+		}
+		for _, dc := range defaultcreds.Defaults() {
+			if dc.Vendor != "generic" && dc.Vendor != dv {
+				continue
+			}
+			out = append(out, Cred{Name: "default:" + dc.Vendor, Username: dc.Username, Password: dc.Password})
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, Cred{Name: "no-auth"})
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package sdk
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"asic-control/internal/modelnorm"
+)
+
+// Detect confidence levels. Drivers may return anything in between; only the order matters.
+const (
+	ConfidenceNone     = 0
+	ConfidenceFallback = 10  // may answer (generic API, SPA that might be ours): try last
+	ConfidenceLikely   = 50  // vendor fits but firmware is unknown
+	ConfidenceExact    = 100 // vendor and firmware fingerprint both match
+)
+
+// Control kinds understood by Commands.Control (re-exported by internal/core/commands).
+const (
+	KindPowerOff       = "power_off"
+	KindPowerOn        = "power_on"
+	KindSetLED         = "set_led"
+	KindSetPowerPct    = "set_power_pct"
+	KindSetWorkMode    = "set_workmode"
+	KindSetPowerTarget = "set_power_target"
+	KindSetProfile     = "set_profile"
+	// KindDiagnostics reads the driver's live diagnostics; CommandResult.Detail is a JSON object.
+	KindDiagnostics = "diagnostics"
+)
+
+// Driver is one vendor/firmware family. Vendor packages register theirs from init (see
+// Register); binaries link them in with a blank import of internal/collectors/drivers.
+type Driver interface {
+	Name() string
+	// Detect scores how well t matches from discovery hints alone (no I/O). 0 = never try.
+	Detect(t Target) int
+	// Probe reads the device. Reply keeps the driver's own reply for ExtractFacts.
+	Probe(ctx context.Context, t Target, creds []Cred) ProbeResult
+	ExtractFacts(res ProbeResult) Facts
+	// Commands lists the write operations; nil entries are unsupported.
+	Commands() Commands
+}
+
+// ProbeResult is the outcome of Driver.Probe; its JSON is the /api/devices/{ip}/probe reply.
+// Drivers without per-endpoint replies leave Responses nil and the dispatcher files Reply
+// under the driver name.
+type ProbeResult struct {
+	OK        bool              `json:"ok"`
+	Driver    string            `json:"driver,omitempty"` // set by the dispatcher
+	Scheme    string            `json:"scheme,omitempty"`
+	UsedCred  string            `json:"used_cred,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Responses map[string]any    `json:"responses,omitempty"` // endpoint -> parsed json or string
+	Raw       map[string]string `json:"raw,omitempty"`       // endpoint -> raw (truncated)
+	Reply     any               `json:"-"`
+}
+
+// Pool is one pool slot of a set_pools command (user already expanded for the device).
+type Pool struct {
+	URL  string `json:"url"`
+	User string `json:"user"`
+	Pass string `json:"pass"`
+}
+
+// CommandResult is a driver's answer to a write. Driver overrides the registered name when a
+// driver switched API on the way (e.g. whatsminer btminer vs LuCI).
+type CommandResult struct {
+	OK       bool
+	Driver   string
+	UsedCred string
+	Detail   string
+	Error    string
+}
+
+// ControlFunc runs one control kind with its string args.
+type ControlFunc func(ctx context.Context, t Target, creds []Cred, args map[string]string) CommandResult
+
+// Commands is the write side of a driver.
+type Commands struct {
+	Reboot   func(ctx context.Context, t Target, creds []Cred) CommandResult
+	SetPools func(ctx context.Context, t Target, creds []Cred, pools []Pool) CommandResult
+	Control  map[string]ControlFunc // by kind
+}
+
+// LogReader is implemented by drivers that can read the miner's own logs (kernel,
+// cgminer/bmminer). Reading is best effort: the classifier works on whatever text came back.
+type LogReader interface {
+	ReadLog(ctx context.Context, t Target, creds []Cred) LogResult
+}
+
+// LogResult is the outcome of LogReader.ReadLog. Text concatenates the sources read,
+// each introduced by a "==> source <==" line.
+type LogResult struct {
+	OK       bool     `json:"ok"`
+	Driver   string   `json:"driver,omitempty"` // set by the dispatcher
+	UsedCred string   `json:"used_cred,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Text     string   `json:"-"`
+}
+
+var (
+	regMu   sync.RWMutex
+	drivers []Driver
+)
+
+// Register adds a driver. It panics on a duplicate name (two packages claiming one family).
+func Register(d Driver) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	for _, x := range drivers {
+		if x.Name() == d.Name() {
+			panic("sdk: driver registered twice: " + d.Name())
+		}
+	}
+	drivers = append(drivers, d)
+}
+
+// Drivers returns the registered drivers in registration order.
+func Drivers() []Driver {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	return append([]Driver(nil), drivers...)
+}
+
+// Candidates returns the drivers of ds that detect t, most confident first
+// (ties keep the input order).
+func Candidates(ds []Driver, t Target) []Driver {
+	type scored struct {
+		d     Driver
+		score int
+	}
+	var ss []scored
+	for _, d := range ds {
+		if s := d.Detect(t); s > ConfidenceNone {
+			ss = append(ss, scored{d, s})
+		}
+	}
+	sort.SliceStable(ss, func(i, j int) bool { return ss[i].score > ss[j].score })
+	out := make([]Driver, 0, len(ss))
+	for _, s := range ss {
+		out = append(out, s.d)
+	}
+	return out
+}
+
+// Supported reports whether any registered driver would try t.
+func Supported(t Target) bool {
+	return len(Candidates(Drivers(), t)) > 0
+}
+
+// IsVnishFirmware reports Vnish/Anthill firmware strings.
+func IsVnishFirmware(fw string) bool {
+	fw = strings.ToLower(fw)
+	return strings.Contains(fw, "vnish") || strings.Contains(fw, "anthill")
+}
+
+// FirmwareHas reports whether the firmware hint contains s (case-insensitive).
+func (t Target) FirmwareHas(s string) bool {
+	return strings.Contains(strings.ToLower(t.Firmware), strings.ToLower(s))
+}
+
+// HasPort reports whether p is known open.
+func (t Target) HasPort(p int) bool {
+	for _, op := range t.OpenPorts {
+		if op == p {
+			return true
+		}
+	}
+	return false
+}
+
+// LEDOn reads set_led args for drivers with a plain on/off locate LED (blink and on both light it).
+func LEDOn(args map[string]string) bool {
+	mode := strings.ToLower(strings.TrimSpace(args["mode"]))
+	return mode == "blink" || mode == "on"
+}
+
+// normalize maps the raw model onto the catalog name; a model that identifies the vendor
+// (e.g. a DG1 answering the Bitmain CGI) wins over the driver's guess.
+func normalize(f Facts) Facts {
+	if f.Model == "" {
+		return f
+	}
+	n := modelnorm.Normalize(f.Model)
+	if n.Model == "" {
+		return f
+	}
+	f.Model = n.Model
+	if n.Vendor != "" && n.Vendor != "unknown" {
+		f.Vendor = n.Vendor
+	}
+	return f
+}
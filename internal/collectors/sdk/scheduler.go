@@ -1,7 +1,188 @@
 package sdk
 
-// Scheduler is a placeholder for scheduling collection jobs.
-type Scheduler struct{}
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
 
-func NewScheduler() *Scheduler { return &Scheduler{} }
+// SchedulerConfig controls poll cadence. Zero values fall back to defaults.
+type SchedulerConfig struct {
+	Interval      time.Duration // base interval for healthy devices (default 60s)
+	AlertInterval time.Duration // devices in alert state (default 15s)
+	MaxBackoff    time.Duration // cap for unreachable devices (default 15m)
+}
 
+func (c SchedulerConfig) withDefaults() SchedulerConfig {
+	if c.Interval <= 0 {
+		c.Interval = 60 * time.Second
+	}
+	if c.AlertInterval <= 0 {
+		c.AlertInterval = 15 * time.Second
+	}
+	if c.AlertInterval > c.Interval {
+		c.AlertInterval = c.Interval
+	}
+	if c.MaxBackoff < c.Interval {
+		c.MaxBackoff = 15 * time.Minute
+		if c.MaxBackoff < c.Interval {
+			c.MaxBackoff = c.Interval
+		}
+	}
+	return c
+}
+
+// Scheduler decides when each device (key, usually IP) is due for a poll:
+//
+//   - new devices get a stable offset inside the interval (hash of the key), so a fleet
+//     that appears at once is spread evenly instead of polled in one burst;
+//   - failures back off exponentially (interval * 2^n, capped at MaxBackoff);
+//   - devices flagged as alerting are polled at AlertInterval.
+//
+// It does no I/O: callers ask for Due keys, poll them however they like (in-process or via
+// poll.request) and report back with Done.
+type Scheduler struct {
+	mu    sync.Mutex
+	cfg   SchedulerConfig
+	items map[string]*schedItem
+}
+
+type schedItem struct {
+	next     time.Time
+	failures int
+	alert    bool
+}
+
+// SchedulerStats is a snapshot for status endpoints.
+type SchedulerStats struct {
+	Tracked  int    `json:"tracked"`
+	Alerting int    `json:"alerting"`
+	Backoff  int    `json:"backoff"` // devices with at least one consecutive failure
+	Interval string `json:"interval"`
+}
+
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	return &Scheduler{cfg: cfg.withDefaults(), items: map[string]*schedItem{}}
+}
+
+// SetConfig applies new intervals (existing schedules converge on their next poll).
+func (s *Scheduler) SetConfig(cfg SchedulerConfig) {
+	s.mu.Lock()
+	s.cfg = cfg.withDefaults()
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) offset(key string, d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return time.Duration(uint64(h.Sum32()) % uint64(d))
+}
+
+// Sync sets the pollable key set: unknown keys are added (spread over one interval),
+// keys no longer present are forgotten.
+func (s *Scheduler) Sync(keys []string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		seen[k] = struct{}{}
+		if _, ok := s.items[k]; !ok {
+			s.items[k] = &schedItem{next: now.Add(s.offset(k, s.cfg.Interval))}
+		}
+	}
+	for k := range s.items {
+		if _, ok := seen[k]; !ok {
+			delete(s.items, k)
+		}
+	}
+}
+
+// SetAlert flags a key as alerting; entering alert state pulls the next poll forward.
+func (s *Scheduler) SetAlert(key string, alert bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it := s.items[key]
+	if it == nil || it.alert == alert {
+		return
+	}
+	it.alert = alert
+	if alert && it.failures == 0 {
+		if n := now.Add(s.cfg.AlertInterval); n.Before(it.next) {
+			it.next = n
+		}
+	}
+}
+
+// Due returns up to limit keys whose poll time has come (oldest first). Returned keys are
+// provisionally rescheduled one interval ahead, so a lost result never stalls a device.
+func (s *Scheduler) Due(now time.Time, limit int) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type due struct {
+		key  string
+		next time.Time
+	}
+	var ds []due
+	for k, it := range s.items {
+		if !it.next.After(now) {
+			ds = append(ds, due{key: k, next: it.next})
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i].next.Before(ds[j].next) })
+	if limit > 0 && len(ds) > limit {
+		ds = ds[:limit]
+	}
+	out := make([]string, 0, len(ds))
+	for _, d := range ds {
+		s.items[d.key].next = now.Add(s.cfg.Interval)
+		out = append(out, d.key)
+	}
+	return out
+}
+
+// Done records a poll outcome and schedules the next poll.
+func (s *Scheduler) Done(key string, ok bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	it := s.items[key]
+	if it == nil {
+		return
+	}
+	if ok {
+		it.failures = 0
+		if it.alert {
+			it.next = now.Add(s.cfg.AlertInterval)
+		} else {
+			it.next = now.Add(s.cfg.Interval)
+		}
+		return
+	}
+	it.failures++
+	d := s.cfg.Interval
+	for i := 1; i < it.failures && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+	it.next = now.Add(d)
+}
+
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := SchedulerStats{Tracked: len(s.items), Interval: s.cfg.Interval.String()}
+	for _, it := range s.items {
+		if it.alert {
+			st.Alerting++
+		}
+		if it.failures > 0 {
+			st.Backoff++
+		}
+	}
+	return st
+}
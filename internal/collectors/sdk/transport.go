@@ -1,5 +0,0 @@
-package sdk
-
-// Transport is a placeholder for communication layer (HTTP/TCP/UDP/etc).
-type Transport interface{}
-
@@ -0,0 +1,137 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"asic-control/internal/bus"
+	"asic-control/internal/events"
+)
+
+// Bus is what a worker needs from the message bus (natsjs.Client satisfies it).
+type Bus interface {
+	bus.Publisher
+	NewPullConsumer(durable, filterSubject string, maxAckPending int) (bus.PullConsumer, error)
+}
+
+const (
+	fetchBackoffMin = 100 * time.Millisecond
+	fetchBackoffMax = 5 * time.Second
+)
+
+// Worker pulls poll.request, dispatches to vendor drivers and publishes poll.result.
+// It keeps no device state, so any number of workers can share
+// the same durable consumer (JetStream load-balances between them).
+type Worker struct {
+	Bus         Bus
+	Schema      *events.Schema
+	Dispatcher  *Dispatcher
+	Creds       func(t Target) []Cred
+	Durable     string        // default "collector"
+	ShardID     string        // published as envelope shard_id
+	Concurrency int           // parallel polls (default 256)
+	Batch       int           // fetch batch (default 64)
+	PollTimeout time.Duration // per device (default 5s)
+	Log         *zap.Logger
+}
+
+// Run blocks until ctx is done, the consumer cannot be created or the subscription is
+// closed (bus.ErrClosed); the caller reconnects on error. Other fetch errors back off.
+func (w *Worker) Run(ctx context.Context) error {
+	durable := w.Durable
+	if durable == "" {
+		durable = "collector"
+	}
+	conc := w.Concurrency
+	if conc <= 0 {
+		conc = 256
+	}
+	batch := w.Batch
+	if batch <= 0 {
+		batch = 64
+	}
+	timeout := w.PollTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	log := w.Log
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	consumer, err := w.Bus.NewPullConsumer(durable, events.PollRequest, conc*2)
+	if err != nil {
+		return err
+	}
+	sem := make(chan struct{}, conc)
+	backoff := fetchBackoffMin
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		msgs, err := consumer.Fetch(ctx, batch, 2*time.Second)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, bus.ErrClosed) {
+				return err
+			}
+			log.Warn("fetch poll requests", zap.Error(err), zap.Duration("retry_in", backoff))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, fetchBackoffMax)
+			continue
+		}
+		backoff = fetchBackoffMin
+		for _, m := range msgs {
+			t, err := DecodePollRequest(w.Schema, m.Data())
+			if err != nil {
+				_ = m.Term()
+				continue
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				_ = m.Nak()
+				return nil
+			}
+			go func(m bus.Message, t Target) {
+				defer func() { <-sem }()
+				pctx, cancel := context.WithTimeout(ctx, timeout)
+				p := w.Dispatcher.Poll(pctx, t, w.credsFor(t))
+				cancel()
+				if err := w.publish(ctx, p); err != nil {
+					// result lost: let JetStream redeliver the request
+					log.Warn("publish poll result", zap.String("ip", t.IP), zap.Error(err))
+					_ = m.Nak()
+					return
+				}
+				_ = m.Ack()
+			}(m, t)
+		}
+	}
+}
+
+func (w *Worker) credsFor(t Target) []Cred {
+	if w.Creds == nil {
+		return []Cred{{Name: "no-auth"}}
+	}
+	return w.Creds(t)
+}
+
+func (w *Worker) publish(ctx context.Context, p Poll) error {
+	b, err := EncodePollResult(w.Schema, w.ShardID, p)
+	if err != nil {
+		return err
+	}
+	return w.Bus.Publish(ctx, events.PollResult, b)
+}
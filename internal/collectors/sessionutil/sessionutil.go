@@ -0,0 +1,80 @@
+// Package sessionutil holds the helpers shared by the vendor web/API clients (HTTP session
+// drivers, cgminer and btminer sockets).
+package sessionutil
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+)
+
+// NewClient returns a per-session HTTP client for a miner web UI: short dial, no keep-alive
+// (embedded web servers choke on idle connections), self-signed TLS accepted. With cookies
+// it carries a jar for cookie-based logins.
+func NewClient(cookies bool) *http.Client {
+	tr := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: 1500 * time.Millisecond, KeepAlive: -1}).DialContext,
+		DisableKeepAlives:   true,
+		ForceAttemptHTTP2:   false,
+		TLSHandshakeTimeout: 900 * time.Millisecond,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec
+			MinVersion:         tls.VersionTLS10,
+		},
+	}
+	c := &http.Client{Timeout: 6 * time.Second, Transport: tr}
+	if cookies {
+		c.Jar, _ = cookiejar.New(nil)
+	}
+	return c
+}
+
+// IsDropAfterSend reports whether err is the connection dropping after the request went out,
+// which is how many controllers acknowledge a reboot. Timeouts are not: nothing says the
+// device got the request.
+func IsDropAfterSend(err error) bool {
+	if err == nil {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return false
+	}
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "eof") || strings.Contains(s, "connection reset") || strings.Contains(s, "broken pipe") ||
+		strings.Contains(s, "empty response")
+}
+
+// TruncBody trims a response body for error messages and command details.
+func TruncBody(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > 1024 {
+		s = s[:1024] + "…"
+	}
+	return s
+}
+
+// SanitizeJSON drops the junk some firmwares print before the JSON payload (object, else array).
+func SanitizeJSON(b []byte) []byte {
+	s := string(b)
+	if i := strings.Index(s, "{"); i >= 0 {
+		return []byte(strings.TrimSpace(s[i:]))
+	}
+	if i := strings.Index(s, "["); i >= 0 {
+		return []byte(strings.TrimSpace(s[i:]))
+	}
+	return b
+}
+
+// FirstNonEmpty returns a if it is not blank, else b (both trimmed).
+func FirstNonEmpty(a, b string) string {
+	if strings.TrimSpace(a) != "" {
+		return strings.TrimSpace(a)
+	}
+	return strings.TrimSpace(b)
+}
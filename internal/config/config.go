@@ -37,3 +37,7 @@ type Collector struct {
 	QueueBatchSize int           `env:"COLLECTOR_BATCH" envDefault:"64"`
 }
 
+type Automation struct {
+	ConsumerName string        `env:"AUTOMATION_CONSUMER" envDefault:"automation"`
+	Tick         time.Duration `env:"AUTOMATION_TICK" envDefault:"15s"`
+}
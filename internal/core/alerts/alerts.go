@@ -0,0 +1,506 @@
+// Package alerts is the alert store of core. alert.raised (from core itself and from other
+// services over JetStream) is deduplicated per device and code into one alert with an
+// open -> acknowledged -> resolved lifecycle; silences mute matching alerts until they expire.
+// State is journaled to data/alerts/alerts.jsonl and compacted on open; repeated raises of
+// an alert are journaled only when they change its state or severity (and on close).
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"asic-control/internal/netutil"
+	"asic-control/internal/strutil"
+)
+
+// States.
+const (
+	StateOpen     = "open"
+	StateAcked    = "acknowledged"
+	StateResolved = "resolved"
+)
+
+// keepResolved is how long resolved alerts stay in the store.
+const keepResolved = 7 * 24 * time.Hour
+
+var severityRank = map[string]int{"info": 0, "warn": 1, "crit": 2}
+
+// Raised is one incoming alert.raised.
+type Raised struct {
+	IP       string
+	DeviceID string
+	Severity string // info/warn/crit
+	Code     string
+	Message  string
+	Tags     map[string]string // tags.state = "resolved" clears the alert
+	Source   string            // publishing service (envelope shard_id), "core" for core itself
+	At       time.Time
+}
+
+// Alert is the deduplicated alert of one device and code.
+type Alert struct {
+	ID       string            `json:"id"`
+	IP       string            `json:"ip"`
+	DeviceID string            `json:"device_id,omitempty"`
+	Code     string            `json:"code"`
+	Severity string            `json:"severity"`
+	Message  string            `json:"message"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Source   string            `json:"source,omitempty"`
+	State    string            `json:"state"`
+	Count    int               `json:"count"` // raises since opened
+
+	OpenedAt   time.Time `json:"opened_at"`
+	LastAt     time.Time `json:"last_at"`
+	AckedAt    time.Time `json:"acked_at,omitempty"`
+	AckedBy    string    `json:"acked_by,omitempty"`
+	ResolvedAt time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy string    `json:"resolved_by,omitempty"` // operator, or the source that cleared it
+
+	// SilencedBy is the matching silence when listed (not stored).
+	SilencedBy string `json:"silenced_by,omitempty"`
+}
+
+// Active reports open or acknowledged.
+func (a Alert) Active() bool { return a.State == StateOpen || a.State == StateAcked }
+
+// Silence mutes the alerts it matches until ExpiresAt. Empty matchers match anything, but at
+// least one must be set. Group is an address pool spec (CIDR/range), Code a glob ("pool_*").
+type Silence struct {
+	ID        string    `json:"id"`
+	IP        string    `json:"ip,omitempty"`
+	Group     string    `json:"group,omitempty"`
+	Code      string    `json:"code,omitempty"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Matches reports whether s mutes a at now.
+func (s Silence) Matches(a Alert, now time.Time) bool {
+	if !now.Before(s.ExpiresAt) {
+		return false
+	}
+	if s.IP != "" && s.IP != a.IP {
+		return false
+	}
+	if s.Group != "" && !netutil.SpecContains(s.Group, a.IP) {
+		return false
+	}
+	if s.Code != "" && !strutil.MatchGlob(s.Code, a.Code) {
+		return false
+	}
+	return true
+}
+
+// record is one journal line.
+type record struct {
+	Alert   *Alert   `json:"alert,omitempty"`
+	Silence *Silence `json:"silence,omitempty"`
+	Deleted bool     `json:"deleted,omitempty"` // silence removed
+}
+
+// Errors.
+var (
+	ErrNotFound = errors.New("alert not found")
+	ErrResolved = errors.New("alert already resolved")
+)
+
+// Store holds the alerts and silences.
+type Store struct {
+	mu       sync.Mutex
+	f        *os.File
+	byID     map[string]*Alert
+	active   map[string]string // ip|code -> id of the open/acknowledged alert
+	silences map[string]Silence
+	dirty    map[string]bool // alerts with raises not journaled yet (count, last_at, message)
+
+	subMu sync.Mutex
+	subs  map[uint64]chan struct{}
+	subID atomic.Uint64
+}
+
+func key(ip, code string) string { return ip + "|" + code }
+
+// Open restores data/alerts (dir) and compacts its journal.
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "alerts")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "alerts.jsonl")
+	s := &Store{
+		byID:     map[string]*Alert{},
+		active:   map[string]string{},
+		silences: map[string]Silence{},
+		dirty:    map[string]bool{},
+		subs:     map[uint64]chan struct{}{},
+	}
+
+	// replay (last line wins; tolerate a torn last line)
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for sc.Scan() {
+			var rec record
+			if json.Unmarshal(sc.Bytes(), &rec) != nil {
+				continue
+			}
+			switch {
+			case rec.Alert != nil && rec.Alert.ID != "":
+				a := *rec.Alert
+				s.byID[a.ID] = &a
+			case rec.Silence != nil && rec.Deleted:
+				delete(s.silences, rec.Silence.ID)
+			case rec.Silence != nil && rec.Silence.ID != "":
+				s.silences[rec.Silence.ID] = *rec.Silence
+			}
+		}
+		_ = f.Close()
+	}
+	now := time.Now().UTC()
+	s.pruneLocked(now)
+	for id, a := range s.byID {
+		if a.Active() {
+			s.active[key(a.IP, a.Code)] = id
+		}
+	}
+
+	// compact: rewrite the live state, then append to it
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, a := range s.byID {
+		_ = enc.Encode(record{Alert: a})
+	}
+	for _, sl := range s.silences {
+		_ = enc.Encode(record{Silence: &sl})
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+	s.f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close journals the pending raises and closes the journal.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	for id := range s.dirty {
+		if a, ok := s.byID[id]; ok {
+			s.writeLocked(record{Alert: a})
+		}
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func (s *Store) writeLocked(rec record) {
+	if rec.Alert != nil {
+		delete(s.dirty, rec.Alert.ID)
+	}
+	if s.f == nil {
+		return
+	}
+	if b, err := json.Marshal(rec); err == nil {
+		_, _ = s.f.Write(append(b, '\n'))
+	}
+}
+
+// pruneLocked drops resolved alerts older than keepResolved and expired silences.
+func (s *Store) pruneLocked(now time.Time) {
+	for id, a := range s.byID {
+		if a.State == StateResolved && now.Sub(a.ResolvedAt) > keepResolved {
+			delete(s.byID, id)
+		}
+	}
+	for id, sl := range s.silences {
+		if !now.Before(sl.ExpiresAt) {
+			delete(s.silences, id)
+		}
+	}
+}
+
+// Raise records r: it updates the active alert of the device and code (re-opening an
+// acknowledged one whose severity rose) or opens a new one. A raise tagged state=resolved
+// resolves the active alert instead. It returns the alert and whether anything changed.
+func (s *Store) Raise(r Raised) (Alert, bool) {
+	if r.At.IsZero() {
+		r.At = time.Now().UTC()
+	}
+	if r.Severity == "" {
+		r.Severity = "warn"
+	}
+	s.mu.Lock()
+	k := key(r.IP, r.Code)
+	a := s.byID[s.active[k]]
+
+	if r.Tags["state"] == StateResolved {
+		if a == nil {
+			s.mu.Unlock()
+			return Alert{}, false
+		}
+		by := r.Source
+		if by == "" {
+			by = "auto"
+		}
+		s.resolveLocked(a, by, r.At)
+		out := *a
+		s.mu.Unlock()
+		s.notify()
+		return out, true
+	}
+
+	changed := a == nil || a.Severity != r.Severity
+	if a == nil {
+		a = &Alert{ID: uuid.NewString(), IP: r.IP, Code: r.Code, State: StateOpen, OpenedAt: r.At}
+		s.byID[a.ID] = a
+		s.active[k] = a.ID
+	} else if a.State == StateAcked && severityRank[r.Severity] > severityRank[a.Severity] {
+		a.State, a.AckedAt, a.AckedBy = StateOpen, time.Time{}, ""
+	}
+	if r.DeviceID != "" {
+		a.DeviceID = r.DeviceID
+	}
+	a.Severity, a.Message, a.Tags, a.Source = r.Severity, r.Message, r.Tags, r.Source
+	a.Count++
+	if r.At.After(a.LastAt) {
+		a.LastAt = r.At
+	}
+	// a repeat of the same alert only bumps its counters: keep it in memory, not in the journal
+	if changed {
+		s.writeLocked(record{Alert: a})
+	} else {
+		s.dirty[a.ID] = true
+	}
+	out := *a
+	s.mu.Unlock()
+	s.notify()
+	return out, true
+}
+
+func (s *Store) resolveLocked(a *Alert, by string, at time.Time) {
+	a.State, a.ResolvedAt, a.ResolvedBy = StateResolved, at, by
+	delete(s.active, key(a.IP, a.Code))
+	s.writeLocked(record{Alert: a})
+}
+
+// Ack acknowledges an open alert on behalf of operator.
+func (s *Store) Ack(id, operator string, now time.Time) (Alert, error) {
+	s.mu.Lock()
+	a, ok := s.byID[id]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return Alert{}, ErrNotFound
+	case a.State == StateResolved:
+		s.mu.Unlock()
+		return Alert{}, ErrResolved
+	}
+	a.State, a.AckedAt, a.AckedBy = StateAcked, now, operator
+	s.writeLocked(record{Alert: a})
+	out := *a
+	s.mu.Unlock()
+	s.notify()
+	return out, nil
+}
+
+// Resolve closes an alert on behalf of operator. The next raise of its device and code
+// opens a new alert.
+func (s *Store) Resolve(id, operator string, now time.Time) (Alert, error) {
+	s.mu.Lock()
+	a, ok := s.byID[id]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return Alert{}, ErrNotFound
+	case a.State == StateResolved:
+		s.mu.Unlock()
+		return Alert{}, ErrResolved
+	}
+	s.resolveLocked(a, operator, now)
+	out := *a
+	s.mu.Unlock()
+	s.notify()
+	return out, nil
+}
+
+// Get returns one alert.
+func (s *Store) Get(id string, now time.Time) (Alert, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.byID[id]
+	if !ok {
+		return Alert{}, false
+	}
+	return s.withSilenceLocked(*a, now), true
+}
+
+func (s *Store) withSilenceLocked(a Alert, now time.Time) Alert {
+	for _, sl := range s.silences {
+		if sl.Matches(a, now) {
+			a.SilencedBy = sl.ID
+			break
+		}
+	}
+	return a
+}
+
+// Filter narrows List. State "active" means open or acknowledged; empty fields match any.
+type Filter struct {
+	State    string
+	IP       string
+	Code     string
+	Severity string
+	Silenced *bool
+	Limit    int
+}
+
+// List returns the matching alerts, active first, then newest first.
+func (s *Store) List(f Filter, now time.Time) []Alert {
+	s.mu.Lock()
+	s.pruneLocked(now)
+	out := []Alert{}
+	for _, a := range s.byID {
+		switch {
+		case f.State == "active" && !a.Active():
+			continue
+		case f.State != "" && f.State != "active" && a.State != f.State:
+			continue
+		case f.IP != "" && a.IP != f.IP:
+			continue
+		case f.Code != "" && !strutil.MatchGlob(f.Code, a.Code):
+			continue
+		case f.Severity != "" && !strings.EqualFold(a.Severity, f.Severity):
+			continue
+		}
+		x := s.withSilenceLocked(*a, now)
+		if f.Silenced != nil && (x.SilencedBy != "") != *f.Silenced {
+			continue
+		}
+		out = append(out, x)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Active() != out[j].Active() {
+			return out[i].Active()
+		}
+		if !out[i].LastAt.Equal(out[j].LastAt) {
+			return out[i].LastAt.After(out[j].LastAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out
+}
+
+// AddSilence stores sl (ID and CreatedAt are filled in).
+func (s *Store) AddSilence(sl Silence, now time.Time) (Silence, error) {
+	if sl.IP == "" && sl.Group == "" && sl.Code == "" {
+		return Silence{}, errors.New("silence needs ip, group or code")
+	}
+	if !sl.ExpiresAt.After(now) {
+		return Silence{}, errors.New("silence expires in the past")
+	}
+	sl.ID = uuid.NewString()
+	sl.CreatedAt = now
+	s.mu.Lock()
+	s.silences[sl.ID] = sl
+	s.writeLocked(record{Silence: &sl})
+	s.mu.Unlock()
+	s.notify()
+	return sl, nil
+}
+
+// DeleteSilence ends a silence early.
+func (s *Store) DeleteSilence(id string) bool {
+	s.mu.Lock()
+	sl, ok := s.silences[id]
+	if ok {
+		delete(s.silences, id)
+		s.writeLocked(record{Silence: &sl, Deleted: true})
+	}
+	s.mu.Unlock()
+	if ok {
+		s.notify()
+	}
+	return ok
+}
+
+// Silences lists the unexpired silences, soonest to expire first.
+func (s *Store) Silences(now time.Time) []Silence {
+	s.mu.Lock()
+	s.pruneLocked(now)
+	out := make([]Silence, 0, len(s.silences))
+	for _, sl := range s.silences {
+		out = append(out, sl)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].ExpiresAt.Before(out[j].ExpiresAt) })
+	return out
+}
+
+// Subscribe emits a signal (coalesced) when alerts or silences change.
+func (s *Store) Subscribe(ctx context.Context) <-chan struct{} {
+	id := s.subID.Add(1)
+	ch := make(chan struct{}, 1)
+
+	s.subMu.Lock()
+	s.subs[id] = ch
+	s.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subMu.Lock()
+		delete(s.subs, id)
+		close(ch)
+		s.subMu.Unlock()
+	}()
+	return ch
+}
+
+func (s *Store) notify() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, ch := range s.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one audit entry: a command attempt and its outcome.
+type Record struct {
+	Result
+	Operator   string    `json:"operator,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	BatchID    string    `json:"batch_id,omitempty"`
+	Attempt    int       `json:"attempt"`
+	Final      bool      `json:"final"` // false = will be retried
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Audit keeps command results: append-only data/commands/audit.jsonl plus a recent
+// in-memory window for the API. It also lets API calls wait for a command's final result.
+type Audit struct {
+	mu     sync.Mutex
+	f      *os.File
+	recent []Record
+	max    int
+
+	waiters map[string][]chan Record
+}
+
+type AuditFilter struct {
+	IP       string
+	Kind     string
+	Operator string
+	BatchID  string
+	Limit    int
+}
+
+func OpenAudit(dir string) (*Audit, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "commands")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "audit.jsonl")
+	a := &Audit{max: 5000, waiters: map[string][]chan Record{}}
+
+	// restore the recent window (tolerate a torn last line)
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for sc.Scan() {
+			var r Record
+			if json.Unmarshal(sc.Bytes(), &r) == nil {
+				a.appendRecentLocked(r)
+			}
+		}
+		_ = f.Close()
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	a.f = f
+	return a, nil
+}
+
+func (a *Audit) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil {
+		return nil
+	}
+	err := a.f.Close()
+	a.f = nil
+	return err
+}
+
+// Add persists a record and wakes waiters when the record is final.
+func (a *Audit) Add(r Record) {
+	if r.RecordedAt.IsZero() {
+		r.RecordedAt = time.Now().UTC()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f != nil {
+		if b, err := json.Marshal(r); err == nil {
+			_, _ = a.f.Write(append(b, '\n'))
+		}
+	}
+	a.appendRecentLocked(r)
+	if r.Final && r.CommandID != "" {
+		for _, ch := range a.waiters[r.CommandID] {
+			ch <- r
+		}
+		delete(a.waiters, r.CommandID)
+	}
+}
+
+func (a *Audit) appendRecentLocked(r Record) {
+	a.recent = append(a.recent, r)
+	if len(a.recent) > a.max {
+		a.recent = append([]Record(nil), a.recent[len(a.recent)-a.max:]...)
+	}
+}
+
+// Wait blocks until the final record for id arrives or ctx ends.
+func (a *Audit) Wait(ctx context.Context, id string) (Record, bool) {
+	ch := make(chan Record, 1)
+	a.mu.Lock()
+	for i := len(a.recent) - 1; i >= 0; i-- {
+		if a.recent[i].CommandID == id && a.recent[i].Final {
+			r := a.recent[i]
+			a.mu.Unlock()
+			return r, true
+		}
+	}
+	a.waiters[id] = append(a.waiters[id], ch)
+	a.mu.Unlock()
+
+	select {
+	case r := <-ch:
+		return r, true
+	case <-ctx.Done():
+		a.mu.Lock()
+		ws := a.waiters[id]
+		for i, w := range ws {
+			if w == ch {
+				a.waiters[id] = append(ws[:i], ws[i+1:]...)
+				break
+			}
+		}
+		if len(a.waiters[id]) == 0 {
+			delete(a.waiters, id)
+		}
+		a.mu.Unlock()
+		// the record may have raced in between
+		select {
+		case r := <-ch:
+			return r, true
+		default:
+		}
+		return Record{}, false
+	}
+}
+
+// List returns recent records, newest first.
+func (a *Audit) List(f AuditFilter) []Record {
+	if f.Limit <= 0 || f.Limit > a.max {
+		f.Limit = 500
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]Record, 0, f.Limit)
+	for i := len(a.recent) - 1; i >= 0 && len(out) < f.Limit; i-- {
+		r := a.recent[i]
+		if f.IP != "" && r.IP != f.IP {
+			continue
+		}
+		if f.Kind != "" && r.Kind != f.Kind {
+			continue
+		}
+		if f.Operator != "" && !strings.EqualFold(r.Operator, f.Operator) {
+			continue
+		}
+		if f.BatchID != "" && r.BatchID != f.BatchID {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
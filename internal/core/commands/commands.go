@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+const (
+	KindReboot = "reboot"
+)
+
+// Target is everything an executor needs to reach one device.
+type Target struct {
+	IP        string
+	Vendor    string
+	Firmware  string
+	OpenPorts []int
+	Creds     []sdk.Cred // ordered: most likely first
+}
+
+// Result is a per-device command outcome (returned to the UI / API).
+type Result struct {
+	CommandID  string    `json:"command_id,omitempty"`
+	IP         string    `json:"ip"`
+	Kind       string    `json:"kind"`
+	OK         bool      `json:"ok"`
+	Driver     string    `json:"driver,omitempty"` // antminer/vnish/whatsminer
+	UsedCred   string    `json:"used_cred,omitempty"`
+	Detail     string    `json:"detail,omitempty"` // kind specific (e.g. applied workers)
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Schemes returns http/https candidates based on open ports (btcTools-like: https only if 443 is open).
+func (t Target) Schemes() []string {
+	out := []string{"http"}
+	for _, p := range t.OpenPorts {
+		if p == 443 {
+			out = append(out, "https")
+			break
+		}
+	}
+	return out
+}
+
+func (t Target) vendor() string {
+	return strings.ToLower(strings.TrimSpace(t.Vendor))
+}
+
+func (t Target) sdkTarget() sdk.Target {
+	return sdk.Target{IP: t.IP, Vendor: t.Vendor, Firmware: t.Firmware, OpenPorts: t.OpenPorts}
+}
+
+// dispatch runs call on the registered drivers that detect t, most confident first, until one
+// succeeds (e.g. stock CGI, then Vnish when the CGI turned out to be an SPA). call reports
+// false when a driver lacks the command; errors of failed attempts are kept side by side.
+func dispatch(ctx context.Context, t Target, res Result, call func(sdk.Driver, sdk.Target) (sdk.CommandResult, bool)) Result {
+	st := t.sdkTarget()
+	tried := false
+	for _, d := range sdk.Candidates(sdk.Drivers(), st) {
+		if tried && ctx.Err() != nil {
+			break
+		}
+		r, ok := call(d, st)
+		if !ok {
+			continue
+		}
+		tried = true
+		res.Driver, res.OK, res.UsedCred = d.Name(), r.OK, r.UsedCred
+		if r.Driver != "" {
+			res.Driver = r.Driver
+		}
+		if r.Detail != "" {
+			res.Detail = r.Detail
+		}
+		res.Error = joinErr(res.Error, r.Error, r.OK)
+		if r.OK {
+			break
+		}
+	}
+	if !tried {
+		res.Error = "unsupported vendor: " + t.vendor() + " has no " + res.Kind
+	}
+	return res
+}
+
+// Reboot reboots a single device through the first driver that accepts it.
+func Reboot(ctx context.Context, t Target) (res Result) {
+	res = Result{IP: t.IP, Kind: KindReboot, StartedAt: time.Now().UTC()}
+	// named result: the deferred write must reach the caller
+	defer func() { res.DurationMS = time.Since(res.StartedAt).Milliseconds() }()
+
+	res = dispatch(ctx, t, res, func(d sdk.Driver, st sdk.Target) (sdk.CommandResult, bool) {
+		fn := d.Commands().Reboot
+		if fn == nil {
+			return sdk.CommandResult{}, false
+		}
+		return fn(ctx, st, t.Creds), true
+	})
+	return res
+}
+
+// joinErr keeps the error of an earlier attempt next to the fallback's one; success clears both.
+func joinErr(prev, cur string, ok bool) string {
+	switch {
+	case ok:
+		return ""
+	case prev == "":
+		return cur
+	case cur == "":
+		return prev
+	}
+	return prev + "; " + cur
+}
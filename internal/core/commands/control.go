@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// Power, LED and work mode control. Only drivers with a write API support these
+// (btminer, Avalon ascset, IceRiver locate LED, Braiins power target, LuxOS session
+// commands); everything else fails with "unsupported vendor" and is not retried.
+const (
+	KindPowerOff    = sdk.KindPowerOff
+	KindPowerOn     = sdk.KindPowerOn
+	KindSetLED      = sdk.KindSetLED      // args: mode=auto|blink|on|off, color, period_ms, duration_ms
+	KindSetPowerPct = sdk.KindSetPowerPct // args: percent=0..100
+	KindSetWorkMode = sdk.KindSetWorkMode // args: mode (Avalon: 0 low, 1 normal, 2 high)
+	// KindSetPowerTarget sets the autotuner power target. args: watts
+	KindSetPowerTarget = sdk.KindSetPowerTarget
+	// KindSetProfile switches the LuxOS frequency/voltage profile. args: profile
+	KindSetProfile = sdk.KindSetProfile
+	// KindDiagnostics is read-only: Result.Detail carries the driver's JSON report
+	// (Whatsminer btminer: PSU and active error codes).
+	KindDiagnostics = sdk.KindDiagnostics
+)
+
+// Known reports whether kind is an executable command kind.
+func Known(kind string) bool {
+	switch kind {
+	case KindReboot, KindSetPools, KindPowerOff, KindPowerOn, KindSetLED, KindSetPowerPct, KindSetWorkMode, KindSetPowerTarget, KindSetProfile, KindDiagnostics:
+		return true
+	}
+	return false
+}
+
+// Control runs one of the power/LED kinds with its string args (as carried by Request.Args).
+func Control(ctx context.Context, t Target, kind string, args map[string]string) (res Result) {
+	res = Result{IP: t.IP, Kind: kind, StartedAt: time.Now().UTC()}
+	// named result: the deferred write must reach the caller
+	defer func() { res.DurationMS = time.Since(res.StartedAt).Milliseconds() }()
+
+	res = dispatch(ctx, t, res, func(d sdk.Driver, st sdk.Target) (sdk.CommandResult, bool) {
+		fn := d.Commands().Control[kind]
+		if fn == nil {
+			return sdk.CommandResult{}, false
+		}
+		return fn(ctx, st, t.Creds, args), true
+	})
+	return res
+}
@@ -1,4 +1,4 @@
 package commands
 
-// Package commands contains CLI/administrative commands used by core.
-
+// Package commands executes control commands (reboot, pool changes, ...) against miners
+// and reports per-device results back to core.
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Request is a queued command (transported as events.CommandRequest).
+type Request struct {
+	CommandID   string            `json:"command_id"`
+	Kind        string            `json:"kind"`
+	IP          string            `json:"ip"`
+	Operator    string            `json:"operator,omitempty"`
+	Source      string            `json:"source,omitempty"`
+	BatchID     string            `json:"batch_id,omitempty"`
+	MaxAttempts int               `json:"max_attempts,omitempty"`
+	Args        map[string]string `json:"args,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Deadline    time.Time         `json:"deadline"` // not run (nor retried) after this; zero = CreatedAt + DefaultTTL
+}
+
+// DefaultMaxAttempts applies when a request does not set MaxAttempts.
+const DefaultMaxAttempts = 3
+
+// DefaultTTL bounds how long a queued command stays runnable. The command stream keeps
+// messages for days; a reboot nobody is waiting for anymore must not fire on redelivery.
+const DefaultTTL = 10 * time.Minute
+
+// Stamp fills CreatedAt and Deadline when unset.
+func (r *Request) Stamp(now time.Time) {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now.UTC()
+	}
+	if r.Deadline.IsZero() {
+		r.Deadline = r.CreatedAt.Add(DefaultTTL)
+	}
+}
+
+// Expired reports whether the request is past its deadline at now. Requests without any
+// timestamps (older publishers) never expire.
+func (r Request) Expired(now time.Time) bool {
+	dl := r.Deadline
+	if dl.IsZero() && !r.CreatedAt.IsZero() {
+		dl = r.CreatedAt.Add(DefaultTTL)
+	}
+	return !dl.IsZero() && now.After(dl)
+}
+
+// PoolsArgs encodes pools for Request.Args (already expanded for the device).
+func PoolsArgs(pools []Pool) map[string]string {
+	b, _ := json.Marshal(pools)
+	return map[string]string{"pools": string(b)}
+}
+
+// Execute runs a command kind against a target. Unknown kinds fail without touching the device.
+func Execute(ctx context.Context, t Target, kind string, args map[string]string) Result {
+	switch kind {
+	case KindReboot:
+		return Reboot(ctx, t)
+	case KindSetPools:
+		var pools []Pool
+		if err := json.Unmarshal([]byte(args["pools"]), &pools); err != nil || len(pools) == 0 {
+			return Result{IP: t.IP, Kind: kind, Error: "bad args: pools", StartedAt: time.Now().UTC()}
+		}
+		return SetPools(ctx, t, pools)
+	case KindPowerOff, KindPowerOn, KindSetLED, KindSetPowerPct, KindSetWorkMode, KindSetPowerTarget, KindSetProfile, KindDiagnostics:
+		return Control(ctx, t, kind, args)
+	}
+	return Result{IP: t.IP, Kind: kind, Error: "unknown command: " + kind, StartedAt: time.Now().UTC()}
+}
+
+// permanentErrors do not get better with another attempt: bad input, an unsupported device,
+// or credentials the device refused (retrying those only feeds its lockout counter).
+var permanentErrors = []string{"unknown command", "unsupported vendor", "bad args", "not found", "no pools",
+	"unauthorized", "no credentials accepted", "expired"}
+
+// unsentErrors are transport failures before the request reached the device.
+var unsentErrors = []string{"connection refused", "no route to host", "network is unreachable", "dial tcp"}
+
+// Retryable reports whether a failed result is worth another attempt
+// (transient network errors yes; bad input / unsupported device / refused credentials no).
+// Reboot and power_off are only retried when no attempt reached the device: after a timeout
+// or a dropped connection the device may already be going down.
+func Retryable(r Result) bool {
+	if r.OK {
+		return false
+	}
+	e := strings.ToLower(r.Error)
+	for _, perm := range permanentErrors {
+		if strings.Contains(e, perm) {
+			return false
+		}
+	}
+	switch r.Kind {
+	case KindReboot, KindPowerOff:
+		for _, part := range strings.Split(e, "; ") {
+			if !containsAny(part, unsentErrors) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryDelay is the backoff before redelivery of attempt n (1-based).
+func RetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := time.Duration(attempt*attempt) * 10 * time.Second
+	if d > 5*time.Minute {
+		d = 5 * time.Minute
+	}
+	return d
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+const KindSetPools = "set_pools"
+
+// Pool is one pool slot; User may contain template placeholders (see Expand).
+type Pool = sdk.Pool
+
+// TemplateVars returns placeholder values for a device:
+//
+//	{ip} {ip_last_octet} {ip_dashed} {site} {mac} {model} {worker}
+//
+// site is usually the note of the address pool the device belongs to.
+func TemplateVars(ip, mac, model, worker, site string) map[string]string {
+	last := ip
+	if i := strings.LastIndex(ip, "."); i >= 0 {
+		last = ip[i+1:]
+	}
+	return map[string]string{
+		"ip":            ip,
+		"ip_last_octet": last,
+		"ip_dashed":     strings.ReplaceAll(ip, ".", "-"),
+		"site":          sanitizeWorkerPart(site),
+		"mac":           strings.ToLower(strings.ReplaceAll(mac, ":", "")),
+		"model":         sanitizeWorkerPart(model),
+		"worker":        worker,
+	}
+}
+
+// Expand replaces {name} placeholders; unknown placeholders are kept as-is so typos are visible in previews.
+func Expand(tpl string, vars map[string]string) string {
+	if !strings.Contains(tpl, "{") {
+		return tpl
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(tpl)
+}
+
+// ExpandPools expands the user field of every pool for one device.
+func ExpandPools(pools []Pool, vars map[string]string) []Pool {
+	out := make([]Pool, 0, len(pools))
+	for _, p := range pools {
+		out = append(out, Pool{URL: strings.TrimSpace(p.URL), User: Expand(strings.TrimSpace(p.User), vars), Pass: p.Pass})
+	}
+	return out
+}
+
+// pool user names end up in stratum logins: keep them to [A-Za-z0-9_-].
+func sanitizeWorkerPart(s string) string {
+	s = strings.TrimSpace(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ' || r == '.':
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// SetPools writes pools 1..3 (already expanded for this device).
+func SetPools(ctx context.Context, t Target, pools []Pool) (res Result) {
+	res = Result{IP: t.IP, Kind: KindSetPools, StartedAt: time.Now().UTC()}
+	// named result: the deferred write must reach the caller
+	defer func() { res.DurationMS = time.Since(res.StartedAt).Milliseconds() }()
+
+	users := make([]string, 0, len(pools))
+	for _, p := range pools {
+		if p.URL != "" {
+			users = append(users, p.User)
+		}
+	}
+	res.Detail = strings.Join(users, ", ")
+
+	res = dispatch(ctx, t, res, func(d sdk.Driver, st sdk.Target) (sdk.CommandResult, bool) {
+		fn := d.Commands().SetPools
+		if fn == nil {
+			return sdk.CommandResult{}, false
+		}
+		return fn(ctx, st, t.Creds, pools), true
+	})
+	return res
+}
@@ -0,0 +1,189 @@
+// Package poolguard compares the pools a miner reports with the allow-list of its address
+// pool (settings.PoolPolicy) and flags deviations: a hijacked pool URL or a swapped wallet.
+package poolguard
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/netutil"
+	"asic-control/internal/settings"
+	"asic-control/internal/strutil"
+)
+
+// DefaultRestoreEvery rate-limits automatic restores of one device (a miner that keeps
+// reverting is being managed by someone else; alerting is enough then).
+const DefaultRestoreEvery = 30 * time.Minute
+
+// Violation is one configured pool outside the allow-list.
+type Violation struct {
+	URL    string `json:"url"`
+	User   string `json:"user,omitempty"`
+	Active bool   `json:"active,omitempty"`
+	Reason string `json:"reason"` // url / user
+}
+
+// Finding is the open deviation of one device.
+type Finding struct {
+	IP         string      `json:"ip"`
+	Policy     string      `json:"policy"`
+	Severity   string      `json:"severity"` // crit when the active pool deviates, else warn
+	Violations []Violation `json:"violations"`
+	Since      time.Time   `json:"since"`
+	RestoredAt time.Time   `json:"restored_at,omitempty"`
+	// Restore asks the caller to push the policy's approved pools now.
+	Restore bool `json:"-"`
+}
+
+// hostPort drops the stratum scheme and trailing slash: "stratum+tcp://a.b:3333/" -> "a.b:3333".
+func hostPort(u string) string {
+	u = strings.TrimSpace(u)
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+func anyMatch(patterns []string, s string, norm func(string) string) bool {
+	for _, p := range patterns {
+		if strutil.MatchGlob(norm(p), norm(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyFor returns the first enabled policy covering ip.
+func PolicyFor(ps []settings.PoolPolicy, ip string) (settings.PoolPolicy, bool) {
+	for _, p := range ps {
+		if !p.Enabled || len(p.URLs) == 0 {
+			continue
+		}
+		if len(p.Subnets) == 0 {
+			return p, true
+		}
+		for _, spec := range p.Subnets {
+			if netutil.SpecContains(spec, ip) {
+				return p, true
+			}
+		}
+	}
+	return settings.PoolPolicy{}, false
+}
+
+// Check lists the configured pools that fall outside p.
+func Check(p settings.PoolPolicy, pools []sdk.PoolStatus) []Violation {
+	var out []Violation
+	same := func(s string) string { return s }
+	for _, ps := range pools {
+		if strings.TrimSpace(ps.URL) == "" {
+			continue
+		}
+		v := Violation{URL: ps.URL, User: ps.User, Active: ps.Active}
+		switch {
+		case !anyMatch(p.URLs, ps.URL, hostPort):
+			v.Reason = "url"
+		case len(p.Users) > 0 && !anyMatch(p.Users, ps.User, same):
+			v.Reason = "user"
+		default:
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Guard keeps the open finding per device so a deviation alerts once (and again when it
+// changes), and rate-limits automatic restores.
+type Guard struct {
+	RestoreEvery time.Duration
+
+	mu   sync.Mutex
+	open map[string]*entry
+}
+
+type entry struct {
+	f   Finding
+	sig string
+}
+
+func NewGuard() *Guard {
+	return &Guard{RestoreEvery: DefaultRestoreEvery, open: map[string]*entry{}}
+}
+
+// Observe checks the pools of ip against its policy. raise reports a new or changed
+// deviation; f.Restore is set when an automatic restore is due. A compliant device (or one
+// no policy covers) clears its finding.
+func (g *Guard) Observe(ip string, policies []settings.PoolPolicy, pools []sdk.PoolStatus, now time.Time) (f Finding, raise bool) {
+	p, ok := PolicyFor(policies, ip)
+	var vs []Violation
+	if ok {
+		vs = Check(p, pools)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(vs) == 0 {
+		delete(g.open, ip)
+		return Finding{}, false
+	}
+
+	sev := "warn"
+	parts := make([]string, 0, len(vs))
+	for _, v := range vs {
+		if v.Active {
+			sev = "crit"
+		}
+		parts = append(parts, v.Reason+"|"+v.URL+"|"+v.User)
+	}
+	sort.Strings(parts)
+	sig := p.ID + "#" + strings.Join(parts, ",")
+
+	e := g.open[ip]
+	if e == nil || e.sig != sig {
+		raise = true
+		since := now
+		var restored time.Time
+		if e != nil {
+			since, restored = e.f.Since, e.f.RestoredAt
+		}
+		e = &entry{sig: sig, f: Finding{IP: ip, Since: since, RestoredAt: restored}}
+		g.open[ip] = e
+	}
+	e.f.Policy = p.Name
+	if e.f.Policy == "" {
+		e.f.Policy = p.ID
+	}
+	e.f.Severity = sev
+	e.f.Violations = vs
+	every := g.RestoreEvery
+	if every <= 0 {
+		every = DefaultRestoreEvery
+	}
+	out := e.f
+	if p.AutoRestore && len(p.Restore) > 0 && now.Sub(e.f.RestoredAt) >= every {
+		e.f.RestoredAt = now
+		out.RestoredAt = now
+		out.Restore = true
+	}
+	return out, raise
+}
+
+// List returns the open findings, worst first.
+func (g *Guard) List() []Finding {
+	g.mu.Lock()
+	out := make([]Finding, 0, len(g.open))
+	for _, e := range g.open {
+		out = append(out, e.f)
+	}
+	g.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Severity != out[j].Severity {
+			return out[i].Severity == "crit"
+		}
+		return out[i].IP < out[j].IP
+	})
+	return out
+}
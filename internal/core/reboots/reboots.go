@@ -0,0 +1,227 @@
+// Package reboots records device restarts: detected from uptime going backwards between
+// polls, or sent by MonA itself. History is append-only data/reboots/reboots.jsonl.
+package reboots
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons.
+const (
+	ReasonUptimeReset = "uptime_reset" // uptime went backwards between two polls
+	ReasonCommand     = "command"      // a MonA reboot command succeeded
+)
+
+// MinDrop ignores uptime jitter between drivers/APIs of one device (cgminer Elapsed vs
+// system uptime); a real restart drops uptime far more than this.
+const MinDrop = 30
+
+// commandWindow: a reset detected this soon after a commanded reboot is that reboot.
+const commandWindow = 15 * time.Minute
+
+// Event is one restart.
+type Event struct {
+	IP     string    `json:"ip"`
+	At     time.Time `json:"at"` // boot time (now - uptime) for detected, send time for commanded
+	Reason string    `json:"reason"`
+	// Source is who noticed or caused it: poll, or the command source (api/ui/automation/...).
+	Source        string    `json:"source"`
+	Operator      string    `json:"operator,omitempty"`
+	CommandID     string    `json:"command_id,omitempty"`
+	UptimeBeforeS uint64    `json:"uptime_before_s,omitempty"` // last uptime seen before the reset
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// Summary is one device in the frequent rebooters view.
+type Summary struct {
+	IP         string `json:"ip"`
+	Reboots1h  int    `json:"reboots_1h"`
+	Reboots24h int    `json:"reboots_24h"`
+	Last       Event  `json:"last"`
+}
+
+// Log keeps the last 200 events of every device in memory over the jsonl file.
+type Log struct {
+	mu        sync.Mutex
+	f         *os.File
+	byIP      map[string][]Event
+	perDevice int
+}
+
+func Open(dir string) (*Log, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "reboots")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "reboots.jsonl")
+	l := &Log{byIP: map[string][]Event{}, perDevice: 200}
+
+	// restore history (tolerate a torn last line)
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for sc.Scan() {
+			var e Event
+			if json.Unmarshal(sc.Bytes(), &e) == nil && e.IP != "" {
+				l.appendLocked(e)
+			}
+		}
+		_ = f.Close()
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l.f = f
+	return l, nil
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// Add persists e.
+func (l *Log) Add(e Event) {
+	if e.DetectedAt.IsZero() {
+		e.DetectedAt = time.Now().UTC()
+	}
+	if e.At.IsZero() {
+		e.At = e.DetectedAt
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.addLocked(e)
+}
+
+func (l *Log) addLocked(e Event) {
+	if l.f != nil {
+		if b, err := json.Marshal(e); err == nil {
+			_, _ = l.f.Write(append(b, '\n'))
+		}
+	}
+	l.appendLocked(e)
+}
+
+// appendLocked keeps each history ordered by At (detected reboots are back-dated to boot).
+func (l *Log) appendLocked(e Event) {
+	es := append(l.byIP[e.IP], e)
+	for i := len(es) - 1; i > 0 && es[i-1].At.After(es[i].At); i-- {
+		es[i-1], es[i] = es[i], es[i-1]
+	}
+	if len(es) > l.perDevice {
+		es = append([]Event(nil), es[len(es)-l.perDevice:]...)
+	}
+	l.byIP[e.IP] = es
+}
+
+// Observe compares a freshly polled uptime with the previous one and records a reboot when
+// it went backwards. A reset that follows a commanded reboot of the same device is not
+// counted twice.
+func (l *Log) Observe(ip string, prevS, curS uint64, now time.Time, source string) (Event, bool) {
+	if prevS == 0 || curS == 0 || curS+MinDrop > prevS {
+		return Event{}, false
+	}
+	boot := now.Add(-time.Duration(curS) * time.Second)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if es := l.byIP[ip]; len(es) > 0 {
+		last := es[len(es)-1]
+		if last.Reason == ReasonCommand && now.Sub(last.At) < commandWindow && boot.After(last.At.Add(-time.Minute)) {
+			return Event{}, false
+		}
+	}
+	e := Event{IP: ip, At: boot, Reason: ReasonUptimeReset, Source: source, UptimeBeforeS: prevS, DetectedAt: now}
+	l.addLocked(e)
+	return e, true
+}
+
+// List returns the history of ip, newest first.
+func (l *Log) List(ip string, limit int) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	es := l.byIP[ip]
+	if limit <= 0 || limit > len(es) {
+		limit = len(es)
+	}
+	out := make([]Event, 0, limit)
+	for i := len(es) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, es[i])
+	}
+	return out
+}
+
+// Counts returns the reboots of ip in the last hour and day, and the latest one.
+func (l *Log) Counts(ip string, now time.Time) (h1, h24 int, last Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return countLocked(l.byIP[ip], now)
+}
+
+func countLocked(es []Event, now time.Time) (h1, h24 int, last Event) {
+	for i := len(es) - 1; i >= 0; i-- {
+		age := now.Sub(es[i].At)
+		if age > 24*time.Hour {
+			break
+		}
+		h24++
+		if age <= time.Hour {
+			h1++
+		}
+	}
+	if len(es) > 0 {
+		last = es[len(es)-1]
+	}
+	return h1, h24, last
+}
+
+// IPs lists the devices with any history.
+func (l *Log) IPs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]string, 0, len(l.byIP))
+	for ip := range l.byIP {
+		out = append(out, ip)
+	}
+	return out
+}
+
+// Frequent returns devices with at least min1h reboots in the last hour or min24h in the
+// last day, most reboots first.
+func (l *Log) Frequent(now time.Time, min1h, min24h int) []Summary {
+	l.mu.Lock()
+	out := []Summary{}
+	for ip, es := range l.byIP {
+		h1, h24, last := countLocked(es, now)
+		if h24 == 0 || (h1 < min1h && h24 < min24h) {
+			continue
+		}
+		out = append(out, Summary{IP: ip, Reboots1h: h1, Reboots24h: h24, Last: last})
+	}
+	l.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Reboots24h != out[j].Reboots24h {
+			return out[i].Reboots24h > out[j].Reboots24h
+		}
+		if out[i].Reboots1h != out[j].Reboots1h {
+			return out[i].Reboots1h > out[j].Reboots1h
+		}
+		return out[i].IP < out[j].IP
+	})
+	return out
+}
@@ -0,0 +1,371 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend persists registry mutations so the fleet survives core restarts.
+// Store calls Put with a copy of every mutated device, outside its own lock and from
+// one goroutine at a time; implementations must not call back into the Store.
+type Backend interface {
+	Load() ([]Device, error)
+	Put(d Device) error
+	Delete(ip string) error
+	Close() error
+}
+
+// FileBackend is a snapshot + write-ahead log backend stored under a directory
+// (default data/registry):
+//
+//   - snapshot.json — full device list (compacted state)
+//   - wal.jsonl     — one JSON record per mutation since the last snapshot
+//
+// On Load the snapshot is read and the WAL is replayed on top of it. The WAL is
+// compacted into a new snapshot once it grows past CompactEvery records; the snapshot
+// is written outside mu, so Put keeps appending meanwhile.
+type FileBackend struct {
+	mu   sync.Mutex
+	cmu  sync.Mutex // serializes compactions (taken before mu)
+	dir  string
+	wal  *os.File
+	bw   *bufio.Writer
+	recs int
+	size int64 // WAL bytes written, buffered included
+
+	// latest encoded device per IP (used for compaction)
+	state map[string]json.RawMessage
+
+	stop chan struct{}
+	done chan struct{}
+
+	CompactEvery int
+}
+
+type walRecord struct {
+	Op     string          `json:"op"` // put/del
+	IP     string          `json:"ip"`
+	Device json.RawMessage `json:"device,omitempty"`
+}
+
+const (
+	snapshotFile = "snapshot.json"
+	walFile      = "wal.jsonl"
+)
+
+func OpenFileBackend(dir string) (*FileBackend, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "registry")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	b := &FileBackend{
+		dir:          dir,
+		state:        map[string]json.RawMessage{},
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+		CompactEvery: 20000,
+	}
+	go b.flushLoop()
+	return b, nil
+}
+
+// flushLoop bounds data loss on crash to ~1s of mutations without paying an fsync per update.
+func (b *FileBackend) flushLoop() {
+	defer close(b.done)
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-t.C:
+			_ = b.Flush()
+		}
+	}
+}
+
+func (b *FileBackend) Load() ([]Device, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = map[string]json.RawMessage{}
+
+	// snapshot
+	if raw, err := os.ReadFile(filepath.Join(b.dir, snapshotFile)); err == nil {
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err == nil {
+			for _, r := range list {
+				var d Device
+				if json.Unmarshal(r, &d) == nil && d.IP != "" {
+					b.state[d.IP] = r
+				}
+			}
+		}
+		// corrupt snapshot: keep going with WAL only (same policy as settings.json)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// WAL replay (tolerate a torn last line after a crash)
+	if f, err := os.Open(filepath.Join(b.dir, walFile)); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			var rec walRecord
+			if json.Unmarshal(sc.Bytes(), &rec) != nil || rec.IP == "" {
+				continue
+			}
+			switch rec.Op {
+			case "put":
+				b.state[rec.IP] = append(json.RawMessage(nil), rec.Device...)
+			case "del":
+				delete(b.state, rec.IP)
+			}
+		}
+		_ = f.Close()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// Start from a clean snapshot so the WAL only holds this run's changes.
+	if err := b.compactLocked(); err != nil {
+		return nil, err
+	}
+
+	out := make([]Device, 0, len(b.state))
+	for _, r := range b.state {
+		var d Device
+		if json.Unmarshal(r, &d) == nil {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (b *FileBackend) Put(d Device) error {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.state[d.IP] = raw
+	err = b.appendLocked(walRecord{Op: "put", IP: d.IP, Device: raw})
+	due := b.compactDueLocked()
+	b.mu.Unlock()
+	if err == nil && due {
+		err = b.compact()
+	}
+	return err
+}
+
+func (b *FileBackend) Delete(ip string) error {
+	b.mu.Lock()
+	delete(b.state, ip)
+	err := b.appendLocked(walRecord{Op: "del", IP: ip})
+	due := b.compactDueLocked()
+	b.mu.Unlock()
+	if err == nil && due {
+		err = b.compact()
+	}
+	return err
+}
+
+// Flush writes buffered WAL records to disk.
+func (b *FileBackend) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.bw == nil {
+		return nil
+	}
+	return b.bw.Flush()
+}
+
+// Close compacts the WAL into a fresh snapshot and releases files.
+func (b *FileBackend) Close() error {
+	select {
+	case <-b.stop:
+	default:
+		close(b.stop)
+	}
+	<-b.done
+
+	b.cmu.Lock()
+	defer b.cmu.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.compactLocked()
+	if b.wal != nil {
+		_ = b.wal.Close()
+		b.wal = nil
+		b.bw = nil
+	}
+	return err
+}
+
+func (b *FileBackend) appendLocked(rec walRecord) error {
+	if b.wal == nil {
+		if err := b.openWALLocked(); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err := b.bw.Write(line); err != nil {
+		return err
+	}
+	b.recs++
+	b.size += int64(len(line))
+	return nil
+}
+
+func (b *FileBackend) compactDueLocked() bool {
+	return b.CompactEvery > 0 && b.recs >= b.CompactEvery
+}
+
+func (b *FileBackend) openWALLocked() error {
+	f, err := os.OpenFile(filepath.Join(b.dir, walFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	b.wal = f
+	b.bw = bufio.NewWriterSize(f, 64*1024)
+	b.size = st.Size()
+	return nil
+}
+
+// compact writes a snapshot of the state as of now without holding mu during the write,
+// then drops the WAL records it covers (the ones appended meanwhile stay).
+func (b *FileBackend) compact() error {
+	b.cmu.Lock()
+	defer b.cmu.Unlock()
+
+	b.mu.Lock()
+	if !b.compactDueLocked() {
+		b.mu.Unlock()
+		return nil
+	}
+	list := b.snapshotLocked()
+	if b.bw != nil {
+		if err := b.bw.Flush(); err != nil {
+			b.mu.Unlock()
+			return err
+		}
+	}
+	off, recs := b.size, b.recs
+	b.mu.Unlock()
+
+	if err := b.writeSnapshot(list); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropWALLocked(off, recs)
+}
+
+// compactLocked snapshots and empties the WAL in one go (Load and Close: nothing else runs).
+func (b *FileBackend) compactLocked() error {
+	if err := b.writeSnapshot(b.snapshotLocked()); err != nil {
+		return err
+	}
+
+	// snapshot is durable: truncate WAL
+	if b.wal != nil {
+		_ = b.bw.Flush()
+		_ = b.wal.Close()
+		b.wal = nil
+		b.bw = nil
+	}
+	if err := os.Truncate(filepath.Join(b.dir, walFile), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	b.recs, b.size = 0, 0
+	return nil
+}
+
+// snapshotLocked copies the current state, sorted by IP (the encoded devices are never
+// modified in place, so sharing them is safe).
+func (b *FileBackend) snapshotLocked() []json.RawMessage {
+	ips := make([]string, 0, len(b.state))
+	for ip := range b.state {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	list := make([]json.RawMessage, 0, len(ips))
+	for _, ip := range ips {
+		list = append(list, b.state[ip])
+	}
+	return list
+}
+
+func (b *FileBackend) writeSnapshot(list []json.RawMessage) error {
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(b.dir, snapshotFile+".tmp")
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(b.dir, snapshotFile))
+}
+
+// dropWALLocked removes the first off bytes (recs records) of the WAL, which a durable
+// snapshot now covers.
+func (b *FileBackend) dropWALLocked(off int64, recs int) error {
+	if b.wal != nil {
+		if err := b.bw.Flush(); err != nil {
+			return err
+		}
+		_ = b.wal.Close()
+		b.wal = nil
+		b.bw = nil
+	}
+	path := filepath.Join(b.dir, walFile)
+	var tail []byte
+	if f, err := os.Open(path); err == nil {
+		_, err = f.Seek(off, io.SeekStart)
+		if err == nil {
+			tail, err = io.ReadAll(f)
+		}
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if len(tail) == 0 {
+		if err := os.Truncate(path, 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, tail, 0o644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+	b.recs -= recs
+	b.size = int64(len(tail))
+	return nil
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"math"
+	"sort"
+)
+
+// JTH is joules per terahash (= W per TH/s), 0 when either side is unknown.
+func JTH(powerW, hashrateTHS float64) float64 {
+	if powerW <= 0 || hashrateTHS <= 0 {
+		return 0
+	}
+	return math.Round(powerW/hashrateTHS*100) / 100
+}
+
+// EfficiencyGroup aggregates power and hashrate over a set of devices. Only devices that are
+// online and have both values count towards the totals (and J/TH).
+type EfficiencyGroup struct {
+	Key         string  `json:"key"`
+	Devices     int     `json:"devices"`
+	Counted     int     `json:"counted"`
+	Estimated   int     `json:"estimated"` // of Counted, power from the model catalog
+	HashrateTHS float64 `json:"hashrate_ths"`
+	PowerW      float64 `json:"power_w"`
+	JTH         float64 `json:"j_th,omitempty"`
+}
+
+// Efficiency groups devices by key (devices with an empty key are skipped), sorted by key.
+func Efficiency(ds []*Device, key func(*Device) string) []EfficiencyGroup {
+	byKey := map[string]*EfficiencyGroup{}
+	for _, d := range ds {
+		k := key(d)
+		if k == "" {
+			continue
+		}
+		g := byKey[k]
+		if g == nil {
+			g = &EfficiencyGroup{Key: k}
+			byKey[k] = g
+		}
+		g.Devices++
+		if !d.Online || d.PowerW <= 0 || d.HashrateTHS <= 0 {
+			continue
+		}
+		g.Counted++
+		if d.PowerEstimated {
+			g.Estimated++
+		}
+		g.HashrateTHS += d.HashrateTHS
+		g.PowerW += d.PowerW
+	}
+	out := make([]EfficiencyGroup, 0, len(byKey))
+	for _, g := range byKey {
+		g.JTH = JTH(g.PowerW, g.HashrateTHS)
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
@@ -0,0 +1,71 @@
+package registry
+
+import "strings"
+
+// Selection picks devices for bulk operations. Empty fields match everything;
+// when IPs is set it wins over the filters.
+type Selection struct {
+	IPs        []string `json:"ips,omitempty"`
+	Vendor     string   `json:"vendor,omitempty"`
+	Model      string   `json:"model,omitempty"`    // substring, case-insensitive
+	Firmware   string   `json:"firmware,omitempty"` // substring, case-insensitive
+	Worker     string   `json:"worker,omitempty"`   // substring, case-insensitive
+	Pool       string   `json:"pool,omitempty"`     // active pool URL, substring, case-insensitive
+	Query      string   `json:"q,omitempty"`        // matches ip/mac/model/worker/firmware
+	OnlineOnly bool     `json:"online_only,omitempty"`
+}
+
+// Empty reports whether the selection names no devices (guards against "reboot everything" by accident).
+// OnlineOnly only narrows a selection, it does not make one: {"online_only":true} is empty.
+func (sel Selection) Empty() bool {
+	return len(sel.IPs) == 0 && strings.TrimSpace(sel.Vendor) == "" && strings.TrimSpace(sel.Model) == "" &&
+		strings.TrimSpace(sel.Firmware) == "" && strings.TrimSpace(sel.Worker) == "" &&
+		strings.TrimSpace(sel.Pool) == "" && strings.TrimSpace(sel.Query) == ""
+}
+
+// Select returns copies of devices matching sel.
+func (s *Store) Select(sel Selection) []*Device {
+	if len(sel.IPs) > 0 {
+		out := make([]*Device, 0, len(sel.IPs))
+		seen := map[string]bool{}
+		for _, ip := range sel.IPs {
+			ip = strings.TrimSpace(ip)
+			if ip == "" || seen[ip] {
+				continue
+			}
+			seen[ip] = true
+			if d, ok := s.Get(ip); ok {
+				if sel.OnlineOnly && !d.Online {
+					continue
+				}
+				out = append(out, d)
+			}
+		}
+		return out
+	}
+
+	has := func(v, sub string) bool {
+		sub = strings.ToLower(strings.TrimSpace(sub))
+		return sub == "" || strings.Contains(strings.ToLower(v), sub)
+	}
+	out := []*Device{}
+	for _, d := range s.List() {
+		if sel.OnlineOnly && !d.Online {
+			continue
+		}
+		if sel.Vendor != "" && !strings.EqualFold(d.Vendor, strings.TrimSpace(sel.Vendor)) {
+			continue
+		}
+		if !has(d.Model, sel.Model) || !has(d.Firmware, sel.Firmware) || !has(d.Worker, sel.Worker) || !has(d.ActivePool, sel.Pool) {
+			continue
+		}
+		if q := strings.TrimSpace(sel.Query); q != "" {
+			hay := strings.Join([]string{d.IP, d.MAC, d.Vendor, d.Model, d.Worker, d.Firmware, d.ActivePool}, " ")
+			if !has(hay, q) {
+				continue
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -5,6 +5,9 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/minerlog"
 )
 
 type Device struct {
@@ -26,8 +29,26 @@ type Device struct {
 	Confidence  int    `json:"confidence,omitempty"` // 0..100
 
 	// Telemetry (best-effort; vendor specific)
-	FansRPM []int     `json:"fans_rpm,omitempty"`
-	TempsC  []float64 `json:"temps_c,omitempty"`
+	FansRPM        []int       `json:"fans_rpm,omitempty"`
+	TempsC         []float64   `json:"temps_c,omitempty"`
+	PowerW         float64     `json:"power_w,omitempty"` // reported by the device, else catalog estimate (0 = unknown)
+	PowerEstimated bool        `json:"power_estimated,omitempty"`
+	EfficiencyJTH  float64     `json:"efficiency_j_th,omitempty"`
+	WorkMode       string      `json:"work_mode,omitempty"` // vendor specific (Avalon WORKMODE, profile name...)
+	Chains         []sdk.Chain `json:"chains,omitempty"`    // per hashboard, as last reported
+
+	// Mining pools as last reported; ActivePool is the URL the miner is hashing on.
+	Pools      []sdk.PoolStatus `json:"pools,omitempty"`
+	ActivePool string           `json:"active_pool,omitempty"`
+
+	// Failure signatures found in the miner's own logs on the last read (see internal/minerlog).
+	LogFindings  []minerlog.Finding `json:"log_findings,omitempty"`
+	LogCheckedAt time.Time          `json:"log_checked_at,omitempty"`
+
+	// Restarts (see internal/core/reboots); rolling counters refreshed every minute.
+	Reboots1h    int       `json:"reboots_1h,omitempty"`
+	Reboots24h   int       `json:"reboots_24h,omitempty"`
+	LastRebootAt time.Time `json:"last_reboot_at,omitempty"`
 
 	// Probe / login status (minimal UI indicator)
 	AuthStatus   string    `json:"auth_status,omitempty"`    // idle/trying/ok/fail
@@ -40,20 +61,73 @@ type Store struct {
 	mu   sync.RWMutex
 	byIP map[string]*Device
 
+	// optional durable backend (nil = in-memory only)
+	backend Backend
+	// devices changed since the last backend write (latest copy wins); one goroutine at a
+	// time drains them outside mu, so disk I/O never blocks readers or other mutations
+	pending  map[string]Device
+	draining bool
+	drained  *sync.Cond // on mu: signalled when draining stops
+
 	subMu sync.Mutex
 	subs  map[int64]chan struct{}
 	subID atomic.Int64
 }
 
 func NewStore() *Store {
-	return &Store{
-		byIP: map[string]*Device{},
-		subs: map[int64]chan struct{}{},
+	s := &Store{
+		byIP:    map[string]*Device{},
+		pending: map[string]Device{},
+		subs:    map[int64]chan struct{}{},
+	}
+	s.drained = sync.NewCond(&s.mu)
+	return s
+}
+
+// Open creates a store backed by b and restores previously persisted devices.
+func Open(b Backend) (*Store, error) {
+	s := NewStore()
+	if b == nil {
+		return s, nil
+	}
+	devs, err := b.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range devs {
+		d := devs[i]
+		if d.IP == "" {
+			continue
+		}
+		s.byIP[d.IP] = &d
+	}
+	s.backend = b
+	return s, nil
+}
+
+// Close flushes and closes the backend (if any).
+func (s *Store) Close() error {
+	s.persistPending()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.draining {
+		s.drained.Wait()
+	}
+	if s.backend == nil {
+		return nil
 	}
+	for ip, d := range s.pending {
+		_ = s.backend.Put(d)
+		delete(s.pending, ip)
+	}
+	err := s.backend.Close()
+	s.backend = nil
+	return err
 }
 
 func (s *Store) UpsertDiscovery(shardID, ip, mac string, now time.Time) *Device {
 	s.mu.Lock()
+	defer s.persistPending() // runs after the unlock
 	defer s.mu.Unlock()
 
 	d := s.byIP[ip]
@@ -69,12 +143,14 @@ func (s *Store) UpsertDiscovery(shardID, ip, mac string, now time.Time) *Device
 	}
 	d.LastSeen = now
 
+	s.persistLocked(d)
 	s.notifyLocked()
 	return d
 }
 
 func (s *Store) UpdateEnrichment(ip string, fn func(d *Device)) {
 	s.mu.Lock()
+	defer s.persistPending() // runs after the unlock
 	defer s.mu.Unlock()
 	d := s.byIP[ip]
 	if d == nil {
@@ -82,11 +158,13 @@ func (s *Store) UpdateEnrichment(ip string, fn func(d *Device)) {
 	}
 	fn(d)
 	d.LastSeen = time.Now().UTC()
+	s.persistLocked(d)
 	s.notifyLocked()
 }
 
 func (s *Store) UpsertObserved(shardID, ip, mac string, online bool, now time.Time) *Device {
 	s.mu.Lock()
+	defer s.persistPending() // runs after the unlock
 	defer s.mu.Unlock()
 
 	d := s.byIP[ip]
@@ -103,6 +181,7 @@ func (s *Store) UpsertObserved(shardID, ip, mac string, online bool, now time.Ti
 	d.Online = online
 	d.LastSeen = now
 
+	s.persistLocked(d)
 	s.notifyLocked()
 	return d
 }
@@ -150,6 +229,41 @@ func (s *Store) Subscribe(ctx context.Context) <-chan struct{} {
 	return ch
 }
 
+// persistLocked queues a copy of d for the backend; persistPending writes it once mu is released.
+func (s *Store) persistLocked(d *Device) {
+	if s.backend == nil {
+		return
+	}
+	s.pending[d.IP] = *d
+}
+
+// persistPending writes the queued devices to the backend outside mu. If another goroutine
+// is already draining, it picks up the new entries too.
+func (s *Store) persistPending() {
+	s.mu.Lock()
+	if s.draining || len(s.pending) == 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.draining = true
+	for {
+		batch, b := s.pending, s.backend
+		if len(batch) == 0 || b == nil {
+			s.draining = false
+			s.drained.Broadcast()
+			s.mu.Unlock()
+			return
+		}
+		s.pending = map[string]Device{}
+		s.mu.Unlock()
+		for _, d := range batch {
+			// best-effort: a failing disk must not stop discovery/probing
+			_ = b.Put(d)
+		}
+		s.mu.Lock()
+	}
+}
+
 func (s *Store) notifyLocked() {
 	s.subMu.Lock()
 	defer s.subMu.Unlock()
@@ -0,0 +1,221 @@
+// Package statechange decides when a device changed enough to publish device.state_updated:
+// online state, auth status, hashrate, temperature or fans past the configured thresholds
+// (settings.StateEvents), plus a periodic heartbeat so consumers can rebuild their state.
+package statechange
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+
+	"asic-control/internal/core/registry"
+	"asic-control/internal/events"
+	"asic-control/internal/settings"
+)
+
+// Defaults for zero settings.StateEvents fields.
+const (
+	DefaultHashratePct = 10
+	DefaultTempC       = 3
+	DefaultFanRPM      = 500
+	DefaultHeartbeat   = 5 * time.Minute
+)
+
+// State is the part of a device that device.state_updated carries.
+type State struct {
+	IP          string
+	MAC         string
+	Online      bool
+	Auth        string // ok / fail
+	HashrateTHS float64
+	TempMaxC    float64
+	FanMinRPM   int
+	FanMaxRPM   int
+	Fans        int
+	Chains      int // hashing chains; -1 = the driver reports no per-chain data
+	PowerW      float64
+	UptimeS     uint64
+	Reboots1h   int
+	Vendor      string
+	Model       string
+	Firmware    string
+	Worker      string
+}
+
+// FromDevice snapshots d. An offline device reports no telemetry (the registry keeps the
+// last polled values, which no longer describe a running miner).
+func FromDevice(d *registry.Device) State {
+	s := State{
+		IP:        d.IP,
+		MAC:       d.MAC,
+		Online:    d.Online,
+		Auth:      strings.ToLower(d.AuthStatus),
+		Reboots1h: d.Reboots1h,
+		Chains:    -1,
+		Vendor:    d.Vendor,
+		Model:     d.Model,
+		Firmware:  d.Firmware,
+		Worker:    d.Worker,
+	}
+	if !d.Online {
+		return s
+	}
+	s.HashrateTHS = d.HashrateTHS
+	s.PowerW = d.PowerW
+	s.UptimeS = d.UptimeS
+	for _, t := range d.TempsC {
+		s.TempMaxC = math.Max(s.TempMaxC, t)
+	}
+	for i, f := range d.FansRPM {
+		if i == 0 || f < s.FanMinRPM {
+			s.FanMinRPM = f
+		}
+		s.FanMaxRPM = max(s.FanMaxRPM, f)
+	}
+	s.Fans = len(d.FansRPM)
+	if len(d.Chains) > 0 {
+		s.Chains = 0
+		for _, c := range d.Chains {
+			if c.HashrateTHS > 0 || c.Chips > 0 {
+				s.Chains++
+			}
+		}
+	}
+	return s
+}
+
+type entry struct {
+	s  State
+	at time.Time
+}
+
+// Tracker remembers the last published state of every device.
+type Tracker struct {
+	mu   sync.Mutex
+	last map[string]entry
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{last: map[string]entry{}}
+}
+
+// Check compares s with the last published state of its device and returns why it should be
+// published now ("new", "online", "auth", "hashrate", "temp", "fans", "chains", "reboot",
+// "heartbeat");
+// nil means nothing material changed. A non-nil result is remembered as published.
+// Transient auth states ("trying") keep the previous status; the returned State has it
+// resolved and is what should be encoded.
+func (t *Tracker) Check(s State, cfg settings.StateEvents, now time.Time) (State, []string) {
+	pct, tempC, fanRPM, heartbeat := cfg.HashratePct, cfg.TempC, cfg.FanRPM, cfg.Heartbeat
+	if pct <= 0 {
+		pct = DefaultHashratePct
+	}
+	if tempC <= 0 {
+		tempC = DefaultTempC
+	}
+	if fanRPM <= 0 {
+		fanRPM = DefaultFanRPM
+	}
+	if heartbeat == 0 {
+		heartbeat = DefaultHeartbeat
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, seen := t.last[s.IP]
+	if s.Auth != "ok" && s.Auth != "fail" {
+		s.Auth = prev.s.Auth
+	}
+	var why []string
+	switch {
+	case !seen:
+		why = append(why, "new")
+	default:
+		p := prev.s
+		if s.Online != p.Online {
+			why = append(why, "online")
+		}
+		if s.Auth != p.Auth {
+			why = append(why, "auth")
+		}
+		if s.Online && p.Online {
+			if changedPct(p.HashrateTHS, s.HashrateTHS, pct) {
+				why = append(why, "hashrate")
+			}
+			if math.Abs(s.TempMaxC-p.TempMaxC) >= tempC {
+				why = append(why, "temp")
+			}
+			if s.Fans != p.Fans || abs(s.FanMinRPM-p.FanMinRPM) >= fanRPM || abs(s.FanMaxRPM-p.FanMaxRPM) >= fanRPM {
+				why = append(why, "fans")
+			}
+			if s.Chains != p.Chains {
+				why = append(why, "chains")
+			}
+		}
+		if s.Reboots1h > p.Reboots1h {
+			why = append(why, "reboot")
+		}
+		if len(why) == 0 && heartbeat > 0 && now.Sub(prev.at) >= heartbeat {
+			why = append(why, "heartbeat")
+		}
+	}
+	if len(why) > 0 {
+		t.last[s.IP] = entry{s: s, at: now}
+	}
+	return s, why
+}
+
+// changedPct: a start or stop of hashing always counts; otherwise the relative change.
+func changedPct(prev, cur, pct float64) bool {
+	if prev == 0 || cur == 0 {
+		return (prev == 0) != (cur == 0)
+	}
+	return math.Abs(cur-prev)/prev*100 >= pct
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Encode builds the device.state_updated envelope; meta carries the identity fields, the
+// auth status, the fan count/min, the hashing chains and the change reasons ("changed").
+func Encode(schema *events.Schema, s State, why []string) ([]byte, error) {
+	env := schema.NewEnvelope(events.DeviceStateUpdated)
+	env.SetFieldByName("shard_id", "core")
+	env.SetFieldByName("device_id", s.MAC)
+	env.SetFieldByName("ip", s.IP)
+	env.SetFieldByName("mac", s.MAC)
+	su := dynamic.NewMessage(schema.DeviceStateUpdated)
+	su.SetFieldByName("device_id", s.MAC)
+	su.SetFieldByName("ip", s.IP)
+	su.SetFieldByName("online", s.Online)
+	su.SetFieldByName("hashrate_ths", s.HashrateTHS)
+	su.SetFieldByName("temp_max_c", s.TempMaxC)
+	su.SetFieldByName("fan_rpm_max", uint32(s.FanMaxRPM))
+	su.SetFieldByName("power_w", uint32(s.PowerW))
+	su.SetFieldByName("uptime_s", s.UptimeS)
+	su.SetFieldByName("reboot_count_1h", uint32(s.Reboots1h))
+	meta := map[string]string{"changed": strings.Join(why, ",")}
+	for k, v := range map[string]string{"vendor": s.Vendor, "model": s.Model, "firmware": s.Firmware, "worker": s.Worker, "auth": s.Auth} {
+		if v != "" {
+			meta[k] = v
+		}
+	}
+	if s.Fans > 0 {
+		meta["fans"] = strconv.Itoa(s.Fans)
+		meta["fan_rpm_min"] = strconv.Itoa(s.FanMinRPM)
+	}
+	if s.Chains >= 0 {
+		meta["chains"] = strconv.Itoa(s.Chains)
+	}
+	su.SetFieldByName("meta", meta)
+	env.SetFieldByName("device_state_updated", su)
+	return events.Marshal(env)
+}
@@ -26,6 +26,7 @@ const state = {
   hashMin: "",
   hashMax: "",
   dashRefreshMs: 10000,
+  operator: localStorage.getItem("mona.operator") || "",
   lastDashSampleTs: 0,
   lastDashDrawTs: 0,
 };
@@ -80,7 +81,7 @@ function setConn(kind) {
 
 function setRoute(name) {
   state.route = name || "dashboard";
-  ["dashboard", "devices", "device", "discovery", "creds", "settings"].forEach((p) => {
+  ["dashboard", "devices", "device", "discovery", "commands", "creds", "settings"].forEach((p) => {
     const page = $(`page_${p}`);
     if (page) page.classList.toggle("hidden", p !== state.route);
   });
@@ -89,6 +90,7 @@ function setRoute(name) {
   });
   renderTopbar();
   renderDashboard();
+  if (state.route === "commands") loadCommands();
 }
 
 function computeFleetSummary(devices) {
@@ -325,7 +327,7 @@ function applyDeviceFilters(devices) {
     if (hashMin && h < hashMin) return false;
     if (hashMax && h > hashMax) return false;
     if (!q) return true;
-    const hay = `${d.ip} ${d.mac} ${d.vendor} ${d.model} ${d.worker}`.toLowerCase();
+    const hay = `${d.ip} ${d.mac} ${d.vendor} ${d.model} ${d.worker} ${d.active_pool || ""}`.toLowerCase();
     return hay.includes(q);
   });
 }
@@ -428,10 +430,20 @@ function renderDeviceDetails(d) {
   const st = d.online ? "ONLINE" : "OFFLINE";
   if ($("dev_status")) $("dev_status").textContent = st;
   if ($("dev_ip")) $("dev_ip").textContent = `${d.ip || ""} • ${d.mac || ""}`;
-  if ($("dev_hash")) $("dev_hash").textContent = fmtTHS(d.hashrate_ths) || "—";
+  if ($("dev_hash")) {
+    const parts = [fmtTHS(d.hashrate_ths) || "—"];
+    if (Number(d.power_w || 0) > 0) parts.push(`${Math.round(d.power_w)} W${d.power_estimated ? " (est.)" : ""}`);
+    if (Number(d.efficiency_j_th || 0) > 0) parts.push(`${Number(d.efficiency_j_th).toFixed(1)} J/TH`);
+    $("dev_hash").textContent = parts.join(" • ");
+  }
   if ($("dev_worker")) $("dev_worker").textContent = `${d.vendor || ""} • ${d.model || ""} • ${d.worker || ""}`.replace(/\s+•\s+•/g, " • ").replace(/^ • /, "").replace(/ • $/, "");
-  if ($("dev_uptime")) $("dev_uptime").textContent = fmtUptime(d.uptime_s) || "—";
+  if ($("dev_uptime")) {
+    const up = fmtUptime(d.uptime_s) || "—";
+    const rb = Number(d.reboots_24h || 0) > 0 ? ` • reboots ${d.reboots_1h || 0}/1h ${d.reboots_24h}/24h` : "";
+    $("dev_uptime").textContent = up + rb;
+  }
   if ($("dev_fw")) $("dev_fw").textContent = `${d.firmware || ""}` || "—";
+  if ($("dev_fw") && d.active_pool) $("dev_fw").title = `pool: ${d.active_pool}`;
 
   // fans
   const hostFans = $("dev_fans");
@@ -591,6 +603,74 @@ function renderLogs() {
     .join("");
 }
 
+function renderCommandResults(title, out) {
+  const panel = $("cmd_panel");
+  const tb = $("cmd_tbody");
+  if (!panel || !tb) return;
+  panel.classList.remove("hidden");
+  const st = $("cmd_status");
+  const results = (out && out.results) || [];
+  if (st) {
+    st.textContent = `${title}: ${out.ok || 0} ok • ${out.failed || 0} failed`;
+    st.classList.remove("pill-warn", "pill-ok", "pill-bad");
+    st.classList.add(out.failed ? "pill-bad" : "pill-ok");
+  }
+  tb.innerHTML = results
+    .map((r) => {
+      const res = r.ok ? `<span class="pill pill-ok">ok</span>` : `<span class="pill pill-bad">failed</span>`;
+      return `<tr>
+        <td>${r.ip}</td>
+        <td>${res}</td>
+        <td>${r.driver || "—"}</td>
+        <td>${r.used_cred || "—"}</td>
+        <td>${r.detail || ""}</td>
+        <td class="muted">${r.error || ""}</td>
+        <td>${r.duration_ms || 0}</td>
+      </tr>`;
+    })
+    .join("");
+}
+
+// headers for control calls: operator name ends up in the command audit
+function cmdHeaders() {
+  const h = { "content-type": "application/json" };
+  if (state.operator) h["X-MonA-Operator"] = state.operator;
+  return h;
+}
+
+async function loadCommands() {
+  const tb = $("audit_tbody");
+  if (!tb) return;
+  const q = new URLSearchParams();
+  const ip = ($("cmd_ip") && $("cmd_ip").value.trim()) || "";
+  const kind = ($("cmd_kind") && $("cmd_kind").value) || "";
+  if (ip) q.set("ip", ip);
+  if (kind) q.set("kind", kind);
+  q.set("limit", "300");
+  try {
+    const rows = await fetchJSON(`/api/commands?${q.toString()}`);
+    tb.innerHTML = (rows || [])
+      .map((r) => {
+        let res = r.ok ? `<span class="pill pill-ok">ok</span>` : `<span class="pill pill-bad">failed</span>`;
+        if (!r.ok && !r.final) res = `<span class="pill pill-warn">retrying</span>`;
+        return `<tr>
+          <td>${fmtTs(r.recorded_at)}</td>
+          <td>${r.operator || "—"}</td>
+          <td>${r.kind || ""}</td>
+          <td>${r.ip || ""}</td>
+          <td>${res}</td>
+          <td>${r.attempt || 1}</td>
+          <td>${r.driver || "—"}</td>
+          <td>${r.detail || ""}</td>
+          <td class="muted">${r.error || ""}</td>
+        </tr>`;
+      })
+      .join("");
+  } catch {
+    logLine("error", "Commands: failed to load audit");
+  }
+}
+
 async function fetchJSON(url, opt) {
   const res = await fetch(url, { cache: "no-store", ...(opt || {}) });
   if (!res.ok) throw new Error(`HTTP ${res.status}`);
@@ -652,6 +732,108 @@ function initControls() {
     });
   }
 
+  if ($("reboot_filtered")) {
+    $("reboot_filtered").addEventListener("click", async () => {
+      const ips = applyDeviceFilters(state.devices).filter((d) => d.online).map((d) => d.ip);
+      if (!ips.length) {
+        logLine("warn", "Reboot: no online devices match the filters");
+        return;
+      }
+      if (!confirm(`Reboot ${ips.length} device(s)?`)) return;
+      const btn = $("reboot_filtered");
+      btn.disabled = true;
+      logLine("warn", `Reboot: ${ips.length} device(s) requested`);
+      try {
+        const out = await fetchJSON("/api/devices/reboot", {
+          method: "POST",
+          headers: cmdHeaders(),
+          body: JSON.stringify({ ips }),
+        });
+        renderCommandResults("reboot", out);
+        logLine(out.failed ? "warn" : "info", `Reboot: ${out.ok} ok, ${out.failed} failed`);
+      } catch {
+        logLine("error", "Reboot: request failed");
+      } finally {
+        btn.disabled = false;
+      }
+    });
+  }
+  if ($("cmd_close")) $("cmd_close").addEventListener("click", () => $("cmd_panel").classList.add("hidden"));
+
+  // command audit page
+  if ($("cmd_operator")) {
+    $("cmd_operator").value = state.operator;
+    $("cmd_operator").addEventListener("change", (e) => {
+      state.operator = (e.target.value || "").trim();
+      localStorage.setItem("mona.operator", state.operator);
+    });
+  }
+  if ($("cmd_refresh")) $("cmd_refresh").addEventListener("click", () => loadCommands());
+  if ($("cmd_kind")) $("cmd_kind").addEventListener("change", () => loadCommands());
+
+  // pool config push
+  const poolsRequest = (dryRun) => {
+    const pools = [1, 2, 3]
+      .map((n) => ({
+        url: ($(`pool${n}_url`).value || "").trim(),
+        user: ($(`pool${n}_user`).value || "").trim(),
+        pass: $(`pool${n}_pass`).value || "",
+      }))
+      .filter((p, i) => i === 0 || p.url);
+    const ips = applyDeviceFilters(state.devices).filter((d) => d.online).map((d) => d.ip);
+    return { selection: { ips }, pools, site: ($("pools_site").value || "").trim(), dry_run: dryRun };
+  };
+  if ($("pools_toggle")) $("pools_toggle").addEventListener("click", () => $("pools_panel").classList.toggle("hidden"));
+  if ($("pools_preview")) {
+    $("pools_preview").addEventListener("click", async () => {
+      const req = poolsRequest(true);
+      if (!req.selection.ips.length || !req.pools[0].url) {
+        logLine("warn", "Pools: need pool 1 url and at least one online device in the filter");
+        return;
+      }
+      try {
+        const out = await fetchJSON("/api/pools/apply", {
+          method: "POST",
+          headers: cmdHeaders(),
+          body: JSON.stringify(req),
+        });
+        const pre = $("pools_preview_out");
+        pre.classList.remove("hidden");
+        pre.textContent = (out.preview || [])
+          .map((p) => `${p.ip}  ${p.pools.map((x) => `${x.url} ${x.user}`).join("  |  ")}`)
+          .join("\n");
+      } catch {
+        logLine("error", "Pools: preview failed");
+      }
+    });
+  }
+  if ($("pools_apply")) {
+    $("pools_apply").addEventListener("click", async () => {
+      const req = poolsRequest(false);
+      if (!req.selection.ips.length || !req.pools[0].url) {
+        logLine("warn", "Pools: need pool 1 url and at least one online device in the filter");
+        return;
+      }
+      if (!confirm(`Write pools to ${req.selection.ips.length} device(s)?`)) return;
+      const btn = $("pools_apply");
+      btn.disabled = true;
+      logLine("warn", `Pools: applying to ${req.selection.ips.length} device(s)`);
+      try {
+        const out = await fetchJSON("/api/pools/apply", {
+          method: "POST",
+          headers: cmdHeaders(),
+          body: JSON.stringify(req),
+        });
+        renderCommandResults("pools", out);
+        logLine(out.failed ? "warn" : "info", `Pools: ${out.ok} ok, ${out.failed} failed`);
+      } catch {
+        logLine("error", "Pools: request failed");
+      } finally {
+        btn.disabled = false;
+      }
+    });
+  }
+
   // dashboard controls
   if ($("scan_all")) {
     $("scan_all").addEventListener("click", async () => {
@@ -716,6 +898,109 @@ function initControls() {
     });
   }
 
+  if ($("device_reboot")) {
+    $("device_reboot").addEventListener("click", async () => {
+      if (!state.selectedIP) return;
+      if (!confirm(`Reboot ${state.selectedIP}?`)) return;
+      const st = $("probe_status");
+      if (st) {
+        st.textContent = "rebooting…";
+        st.classList.remove("pill-ok", "pill-bad");
+        st.classList.add("pill-warn");
+      }
+      try {
+        const res = await fetchJSON(`/api/devices/${encodeURIComponent(state.selectedIP)}/reboot`, { method: "POST", headers: cmdHeaders() });
+        if ($("probe_out")) $("probe_out").textContent = JSON.stringify(res, null, 2);
+        if (st) {
+          st.textContent = res.ok ? `reboot sent • ${res.used_cred || ""}`.trim() : "reboot failed";
+          st.classList.remove("pill-warn");
+          st.classList.add(res.ok ? "pill-ok" : "pill-bad");
+        }
+        logLine(res.ok ? "warn" : "error", `Reboot ${state.selectedIP}: ${res.ok ? "sent" : res.error || "failed"}`);
+      } catch {
+        if (st) {
+          st.textContent = "reboot failed";
+          st.classList.remove("pill-warn");
+          st.classList.add("pill-bad");
+        }
+        logLine("error", `Reboot ${state.selectedIP}: request failed`);
+      }
+    });
+  }
+
+  // device control (power / LED); results land in the probe panel
+  const deviceCommand = async (kind, args, confirmText) => {
+    if (!state.selectedIP) return;
+    if (confirmText && !confirm(confirmText)) return;
+    const st = $("probe_status");
+    if (st) {
+      st.textContent = `${kind}…`;
+      st.classList.remove("pill-ok", "pill-bad");
+      st.classList.add("pill-warn");
+    }
+    try {
+      const res = await fetchJSON(`/api/devices/${encodeURIComponent(state.selectedIP)}/commands`, {
+        method: "POST",
+        headers: cmdHeaders(),
+        body: JSON.stringify({ kind, args: args || {} }),
+      });
+      if ($("probe_out")) $("probe_out").textContent = JSON.stringify(res, null, 2);
+      if (st) {
+        st.textContent = res.ok ? `${kind} ok` : `${kind} failed`;
+        st.classList.remove("pill-warn");
+        st.classList.add(res.ok ? "pill-ok" : "pill-bad");
+      }
+      logLine(res.ok ? "info" : "error", `${kind} ${state.selectedIP}: ${res.ok ? "ok" : res.error || "failed"}`);
+    } catch {
+      if (st) {
+        st.textContent = `${kind} failed`;
+        st.classList.remove("pill-warn");
+        st.classList.add("pill-bad");
+      }
+      logLine("error", `${kind} ${state.selectedIP}: request failed`);
+    }
+  };
+  if ($("ctl_power_off")) {
+    $("ctl_power_off").addEventListener("click", () => deviceCommand("power_off", {}, `Stop hashing on ${state.selectedIP}?`));
+  }
+  if ($("ctl_power_on")) {
+    $("ctl_power_on").addEventListener("click", () => deviceCommand("power_on", {}));
+  }
+  if ($("ctl_led_blink")) {
+    $("ctl_led_blink").addEventListener("click", () => deviceCommand("set_led", { mode: "blink", color: "red", period_ms: "1000", duration_ms: "500" }));
+  }
+  if ($("ctl_led_auto")) {
+    $("ctl_led_auto").addEventListener("click", () => deviceCommand("set_led", { mode: "auto" }));
+  }
+  if ($("ctl_power_pct_apply")) {
+    $("ctl_power_pct_apply").addEventListener("click", () => {
+      const pct = ($("ctl_power_pct").value || "").trim();
+      if (!pct) return;
+      deviceCommand("set_power_pct", { percent: pct }, `Set power to ${pct}% on ${state.selectedIP}?`);
+    });
+  }
+
+  if ($("ctl_workmode_apply")) {
+    $("ctl_workmode_apply").addEventListener("click", () => {
+      const mode = $("ctl_workmode").value;
+      deviceCommand("set_workmode", { mode }, `Switch ${state.selectedIP} to work mode ${mode}?`);
+    });
+  }
+  if ($("ctl_power_target_apply")) {
+    $("ctl_power_target_apply").addEventListener("click", () => {
+      const watts = ($("ctl_power_target").value || "").trim();
+      if (!watts) return;
+      deviceCommand("set_power_target", { watts }, `Set power target to ${watts} W on ${state.selectedIP}?`);
+    });
+  }
+  if ($("ctl_profile_apply")) {
+    $("ctl_profile_apply").addEventListener("click", () => {
+      const profile = ($("ctl_profile").value || "").trim();
+      if (!profile) return;
+      deviceCommand("set_profile", { profile }, `Switch ${state.selectedIP} to profile ${profile}?`);
+    });
+  }
+
   // discovery add subnet + preview
   if ($("add_subnet")) {
     $("add_subnet").addEventListener("click", async () => {
@@ -813,6 +1098,28 @@ function initControls() {
       cur.embedded_nats.http_port = Number(($("set_embedded_http_port").value || "").trim()) || 0;
       cur.embedded_nats.store_dir = ($("set_embedded_store").value || "").trim();
       cur.try_default_creds = $("set_try_defaults").checked;
+      cur.polling = cur.polling || {};
+      cur.polling.remote = $("set_poll_remote").checked;
+      // durations are nanoseconds in settings.json
+      const secs = (id) => Math.max(0, Number(($(id).value || "").trim()) || 0) * 1e9;
+      cur.polling.interval = secs("set_poll_interval");
+      cur.polling.alert_interval = secs("set_poll_alert_interval");
+      cur.polling.max_backoff = secs("set_poll_max_backoff");
+      cur.state_events = cur.state_events || {};
+      const num = (id) => Number(($(id).value || "").trim()) || 0;
+      cur.state_events.hashrate_pct = num("set_state_hashrate_pct");
+      cur.state_events.temp_c = num("set_state_temp_c");
+      cur.state_events.fan_rpm = num("set_state_fan_rpm");
+      cur.state_events.heartbeat = num("set_state_heartbeat") * 1e9;
+      cur.remediation = cur.remediation || {};
+      cur.remediation.max_reboots_per_hour = num("set_rem_max_hour");
+      cur.remediation.max_concurrent = num("set_rem_max_conc");
+      cur.remediation.quiet_hours = ($("set_rem_quiet").value || "").trim();
+      cur.syslog = cur.syslog || {};
+      cur.syslog.enabled = $("set_syslog").checked;
+      cur.syslog.udp_addr = ($("set_syslog_udp").value || "").trim();
+      cur.syslog.tcp_addr = ($("set_syslog_tcp").value || "").trim();
+      cur.syslog.max_lines = Number(($("set_syslog_lines").value || "").trim()) || 0;
       await fetch("/api/settings", {
         method: "PUT",
         headers: { "content-type": "application/json" },
@@ -1026,6 +1333,25 @@ async function main() {
     $("set_embedded_http_port").value = (s.embedded_nats && s.embedded_nats.http_port) || "";
     $("set_embedded_store").value = (s.embedded_nats && s.embedded_nats.store_dir) || "";
     $("set_try_defaults").checked = !!s.try_default_creds;
+    $("set_poll_remote").checked = !!(s.polling && s.polling.remote);
+    const p = s.polling || {};
+    $("set_poll_interval").value = p.interval ? p.interval / 1e9 : "";
+    $("set_poll_alert_interval").value = p.alert_interval ? p.alert_interval / 1e9 : "";
+    $("set_poll_max_backoff").value = p.max_backoff ? p.max_backoff / 1e9 : "";
+    const se = s.state_events || {};
+    $("set_state_hashrate_pct").value = se.hashrate_pct || "";
+    $("set_state_temp_c").value = se.temp_c || "";
+    $("set_state_fan_rpm").value = se.fan_rpm || "";
+    $("set_state_heartbeat").value = se.heartbeat ? se.heartbeat / 1e9 : "";
+    const rem = s.remediation || {};
+    $("set_rem_max_hour").value = rem.max_reboots_per_hour || "";
+    $("set_rem_max_conc").value = rem.max_concurrent || "";
+    $("set_rem_quiet").value = rem.quiet_hours || "";
+    const sl = s.syslog || {};
+    $("set_syslog").checked = !!sl.enabled;
+    $("set_syslog_udp").value = sl.udp_addr || "";
+    $("set_syslog_tcp").value = sl.tcp_addr || "";
+    $("set_syslog_lines").value = sl.max_lines || "";
   } catch {
     // ignore
   }
@@ -9,9 +9,14 @@ type Entry struct {
 
 // Defaults are built-in and NOT stored in settings.json.
 // This is meant for bootstrap discovery only. Custom creds come later (encrypted).
+// Only tried when Settings.TryDefaultCreds is on.
 func Defaults() []Entry {
-	// Intentionally empty by default to avoid trying random common pairs on production fleets.
+	// Only the vendors' printed factory passwords: no random common pairs on production fleets.
 	// Use encrypted Stored credentials in UI instead.
-	return nil
+	return []Entry{
+		{Vendor: "iceriver", Username: "admin", Password: "12345678", Note: "IceRiver web UI factory default"},
+		{Vendor: "goldshell", Username: "admin", Password: "123456789", Note: "Goldshell web UI factory default"},
+		{Vendor: "elphapex", Username: "root", Password: "root", Note: "Elphapex CGI factory default"},
+	}
 }
 
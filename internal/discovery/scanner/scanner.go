@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"asic-control/internal/cgminer"
 	"asic-control/internal/defaultcreds"
 	"asic-control/internal/modelnorm"
 	"asic-control/internal/netutil"
@@ -318,6 +319,8 @@ func (s *Scanner) fingerprintHTTPWithDefaults(ctx context.Context, r *Result, ho
 					r.Vendor = "avalonminer"
 				case strings.Contains(body, "elphapex"):
 					r.Vendor = "elphapex"
+				case strings.Contains(body, "goldshell"):
+					r.Vendor = "goldshell"
 				}
 			}
 			// try parse common JSON fields if response looks like JSON
@@ -368,6 +371,12 @@ func (s *Scanner) fingerprintHTTP(ctx context.Context, r *Result, url string) {
 	case strings.Contains(body, "unifi") || strings.Contains(body, "ubnt") || strings.Contains(body, "camera"):
 		// common non-ASIC web UIs (avoid false positives)
 		r.Vendor = "non-asic"
+	case strings.Contains(body, "braiins"):
+		// Braiins OS web UI (checked before "antminer": its pages mention the model).
+		r.Vendor = "antminer"
+		if r.Firmware == "" {
+			r.Firmware = "Braiins OS"
+		}
 	case strings.Contains(body, "antminer"):
 		r.Vendor = "antminer"
 	case strings.Contains(body, `meta name="firmware"`) && strings.Contains(body, "anthillos"):
@@ -384,6 +393,8 @@ func (s *Scanner) fingerprintHTTP(ctx context.Context, r *Result, url string) {
 		r.Vendor = "iceriver"
 	case strings.Contains(body, "elphapex"):
 		r.Vendor = "elphapex"
+	case strings.Contains(body, "goldshell"):
+		r.Vendor = "goldshell"
 	case strings.Contains(body, "cgminer") || strings.Contains(body, "bmminer"):
 		// generic miner hints
 		if r.Vendor == "" {
@@ -417,219 +428,64 @@ func (s *Scanner) tryAntminerAuthHint(ctx context.Context, r *Result, base strin
 }
 
 func (s *Scanner) fingerprintCGMiner(ctx context.Context, r *Result, host string, port int) {
-	// CGMiner API protocol: JSON text over TCP, response is JSON-ish with sections.
-	// We keep it best-effort and tolerant.
-	type summaryResp struct {
-		SUMMARY []map[string]any `json:"SUMMARY"`
-		STATUS  []map[string]any `json:"STATUS"`
-	}
-	type poolsResp struct {
-		POOLS  []map[string]any `json:"POOLS"`
-		STATUS []map[string]any `json:"STATUS"`
-	}
-	type devsResp struct {
-		DEVS   []map[string]any `json:"DEVS"`
-		STATUS []map[string]any `json:"STATUS"`
-	}
-	type statsResp struct {
-		STATS  []map[string]any `json:"STATS"`
-		STATUS []map[string]any `json:"STATUS"`
-	}
-
-	summaryRaw, ok := cgminerCommand(ctx, host, port, "summary")
-	if ok {
-		var sr summaryResp
-		if json.Unmarshal([]byte(summaryRaw), &sr) == nil && len(sr.SUMMARY) > 0 {
-			m := sr.SUMMARY[0]
-			// Uptime
-			if v, ok := m["Elapsed"]; ok {
-				r.UptimeS = toU64(v)
-			}
-			// Hashrate: try multiple keys
-			switch {
-			case m["GHS 5s"] != nil:
-				r.HashrateTHS = toF64(m["GHS 5s"]) / 1e3 // GHS -> THS
-			case m["GHS av"] != nil:
-				r.HashrateTHS = toF64(m["GHS av"]) / 1e3
-			case m["MHS 5s"] != nil:
-				r.HashrateTHS = toF64(m["MHS 5s"]) / 1e6 // MHS -> THS
-			case m["MHS av"] != nil:
-				r.HashrateTHS = toF64(m["MHS av"]) / 1e6
-			}
-		}
+	// Best-effort: every command is optional, short timeouts (scan path).
+	c := &cgminer.Client{Host: host, Port: port, DialTimeout: 800 * time.Millisecond, Timeout: 900 * time.Millisecond}
+	var snap cgminer.Snapshot
+	if sum, err := c.Summary(ctx); err == nil {
+		snap.Summary = sum
+		r.UptimeS = uint64(sum.Elapsed.Int())
+		r.HashrateTHS = sum.HashrateTHS()
 	}
-
-	poolsRaw, ok := cgminerCommand(ctx, host, port, "pools")
-	if ok {
-		var pr poolsResp
-		if json.Unmarshal([]byte(poolsRaw), &pr) == nil && len(pr.POOLS) > 0 {
-			// take first enabled pool
-			for _, p := range pr.POOLS {
-				if user, ok := p["User"].(string); ok && strings.TrimSpace(user) != "" {
-					r.Worker = user
-					break
-				}
-			}
+	if ps, err := c.Pools(ctx); err == nil {
+		snap.Pools = ps
+		if w := snap.Worker(); w != "" {
+			r.Worker = w
 		}
 	}
-
 	// Try to get model/type (varies by firmware).
-	devsRaw, ok := cgminerCommand(ctx, host, port, "devs")
-	if ok {
-		var dr devsResp
-		if json.Unmarshal([]byte(devsRaw), &dr) == nil && len(dr.DEVS) > 0 {
-			m := dr.DEVS[0]
-			if r.Model == "" {
-				if v, ok := m["Model"].(string); ok && strings.TrimSpace(v) != "" {
-					r.Model = v
-				} else if v, ok := m["Name"].(string); ok && strings.TrimSpace(v) != "" {
-					r.Model = v
-				} else if v, ok := m["Description"].(string); ok && strings.TrimSpace(v) != "" {
-					r.Model = v
-				}
-			}
-		}
+	if ds, err := c.Devs(ctx); err == nil {
+		snap.Devs = ds
 	}
-
-	statsRaw, ok := cgminerCommand(ctx, host, port, "stats")
-	if ok {
-		var sr statsResp
-		if json.Unmarshal([]byte(statsRaw), &sr) == nil && len(sr.STATS) > 0 {
-			fans := map[int]int{}
-			temps := map[int]float64{}
-			chip := ""
-			for _, m := range sr.STATS {
-				// firmware / version keys differ a lot
-				if r.Firmware == "" {
-					for _, k := range []string{"Firmware Version", "firmware", "version", "Miner Version", "BMMiner Version"} {
-						if v, ok := m[k]; ok {
-							if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
-								r.Firmware = s
-								break
-							}
-						}
-					}
-				}
-				if r.Model == "" {
-					for _, k := range []string{"Type", "Model", "Product", "Miner Type", "miner_type", "Device Model"} {
-						if v, ok := m[k]; ok {
-							if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
-								r.Model = s
-								break
-							}
-						}
-					}
-				}
-				if chip == "" {
-					for _, k := range []string{"Chip Type", "ChipType", "ASIC", "asic"} {
-						if v, ok := m[k]; ok {
-							if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
-								chip = strings.ToUpper(strings.TrimSpace(s))
-								break
-							}
-						}
-					}
-				}
-
-				// Antminer/bmminer commonly exposes fan1..fan4 and temp1..temp3 in STATS
-				for k, v := range m {
-					kl := strings.ToLower(strings.TrimSpace(k))
-					if strings.HasPrefix(kl, "fan") && len(kl) >= 4 {
-						n, ok := parseSuffixInt(kl, "fan")
-						if ok {
-							rpm := int(toU64(v))
-							if rpm > 0 {
-								fans[n] = rpm
-							} else if _, exists := fans[n]; !exists {
-								// keep zeros so UI can show stopped fans
-								fans[n] = 0
-							}
-						}
-					}
-					if strings.HasPrefix(kl, "temp") && len(kl) >= 5 {
-						n, ok := parseSuffixInt(kl, "temp")
-						if ok {
-							t := toF64(v)
-							if t != 0 {
-								temps[n] = t
-							}
-						}
-					}
-				}
-			}
-
-			// chip-type fallback mapping (best-effort)
-			if (strings.TrimSpace(strings.ToUpper(r.Model)) == "SOC" || strings.Contains(strings.ToUpper(r.Model), " SOC")) && chip != "" {
-				switch {
-				case strings.Contains(chip, "BM1370"):
-					// S21 family. Rough differentiation by observed hashrate.
-					if r.HashrateTHS >= 215 {
-						r.Model = "S21 Pro"
-					} else {
-						r.Model = "S21"
-					}
-				case strings.Contains(chip, "BM1397"):
-					r.Model = "S19"
-				}
-			}
-
-			if len(fans) > 0 {
-				r.FansRPM = denseInts(fans, 1, 8)
-			}
-			if len(temps) > 0 {
-				r.TempsC = denseFloats(temps, 1, 8)
-			}
-		}
+	if st, err := c.Stats(ctx); err == nil {
+		snap.Stats = st
 	}
-}
-
-func parseSuffixInt(s, prefix string) (int, bool) {
-	s = strings.TrimSpace(strings.ToLower(s))
-	prefix = strings.ToLower(prefix)
-	if !strings.HasPrefix(s, prefix) {
-		return 0, false
-	}
-	rest := strings.TrimPrefix(s, prefix)
-	// only accept pure numeric suffix
-	n := 0
-	if rest == "" {
-		return 0, false
-	}
-	for i := 0; i < len(rest); i++ {
-		ch := rest[i]
-		if ch < '0' || ch > '9' {
-			return 0, false
+	if r.Model == "" {
+		r.Model = snap.Model()
+	}
+	// aftermarket firmware announces itself in VERSION (stats keep the stock fields)
+	if v, err := c.Version(ctx); err == nil {
+		if fw := v.Firmware(); fw != "" {
+			r.Firmware = fw
+			if r.Vendor == "" || r.Vendor == "asic" {
+				r.Vendor = "antminer"
+			}
 		}
-		n = n*10 + int(ch-'0')
 	}
-	if n <= 0 {
-		return 0, false
+	if r.Firmware == "" {
+		r.Firmware = snap.Firmware()
 	}
-	return n, true
-}
 
-func denseInts(m map[int]int, from, to int) []int {
-	out := make([]int, 0, to-from+1)
-	for i := from; i <= to; i++ {
-		v, ok := m[i]
-		if !ok {
-			continue
+	// chip-type fallback mapping (best-effort)
+	if chip := snap.Chip(); (strings.TrimSpace(strings.ToUpper(r.Model)) == "SOC" || strings.Contains(strings.ToUpper(r.Model), " SOC")) && chip != "" {
+		switch {
+		case strings.Contains(chip, "BM1370"):
+			// S21 family. Rough differentiation by observed hashrate.
+			if r.HashrateTHS >= 215 {
+				r.Model = "S21 Pro"
+			} else {
+				r.Model = "S21"
+			}
+		case strings.Contains(chip, "BM1397"):
+			r.Model = "S19"
 		}
-		out = append(out, v)
 	}
-	return out
-}
 
-func denseFloats(m map[int]float64, from, to int) []float64 {
-	out := make([]float64, 0, to-from+1)
-	for i := from; i <= to; i++ {
-		v, ok := m[i]
-		if !ok {
-			continue
-		}
-		out = append(out, v)
+	if fans := snap.Fans(); len(fans) > 0 {
+		r.FansRPM = fans
+	}
+	if temps := snap.Temps(); len(temps) > 0 {
+		r.TempsC = temps
 	}
-	return out
 }
 
 func (s *Scanner) score(r *Result) int {
@@ -670,58 +526,6 @@ func (s *Scanner) score(r *Result) int {
 	return score
 }
 
-func cgminerCommand(ctx context.Context, host string, port int, cmd string) (string, bool) {
-	d := net.Dialer{Timeout: 800 * time.Millisecond}
-	conn, err := d.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", host, port))
-	if err != nil {
-		return "", false
-	}
-	defer conn.Close()
-	_ = conn.SetDeadline(time.Now().Add(900 * time.Millisecond))
-
-	req := fmt.Sprintf(`{"command":"%s"}`, cmd)
-	if _, err := conn.Write([]byte(req)); err != nil {
-		return "", false
-	}
-	b, _ := io.ReadAll(conn)
-	if len(b) == 0 {
-		return "", false
-	}
-	// Some cgminer responses use NUL separators; replace with commas to improve JSON parse success.
-	s := strings.ReplaceAll(string(b), "\x00", "")
-	s = strings.TrimSpace(s)
-	return s, true
-}
-
-func toU64(v any) uint64 {
-	switch x := v.(type) {
-	case float64:
-		if x < 0 {
-			return 0
-		}
-		return uint64(x)
-	case string:
-		var out uint64
-		_, _ = fmt.Sscanf(strings.TrimSpace(x), "%d", &out)
-		return out
-	default:
-		return 0
-	}
-}
-
-func toF64(v any) float64 {
-	switch x := v.(type) {
-	case float64:
-		return x
-	case string:
-		var out float64
-		_, _ = fmt.Sscanf(strings.TrimSpace(x), "%f", &out)
-		return out
-	default:
-		return 0
-	}
-}
-
 func splitSpec(spec string) []string {
 	spec = strings.ReplaceAll(spec, "\n", ",")
 	spec = strings.ReplaceAll(spec, "\r", ",")
@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"context"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver reads the Bitmain-style CGI of Elphapex boards (digest auth).
+type driver struct{}
+
+func (driver) Name() string { return "elphapex" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "elphapex" {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	res := Probe(ctx, t.IP, creds, t.Schemes())
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error,
+		Responses: res.Responses, Raw: res.Raw, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	res, ok := pr.Reply.(ProbeResult)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := ExtractFacts(res)
+	return sdk.Facts{
+		Vendor:      "elphapex",
+		Model:       f.Model,
+		Firmware:    f.Firmware,
+		Worker:      f.Worker,
+		MAC:         f.MAC,
+		UptimeS:     f.UptimeS,
+		HashrateTHS: f.HashrateTHS,
+		FansRPM:     f.FansRPM,
+		TempsC:      f.TempsC,
+		Chains:      f.Chains,
+		Pools:       f.Pools,
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, t.IP, creds, t.Schemes()))
+		},
+		SetPools: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, pools []sdk.Pool) sdk.CommandResult {
+			return commandResult(SetPools(ctx, t.IP, creds, t.Schemes(), pools))
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, UsedCred: r.UsedCred, Error: r.Error}
+}
@@ -0,0 +1,74 @@
+// Package httpapi drives Elphapex (DG1/DG1+/DG Home) miners. Their control board serves a
+// Bitmain-style lighttpd CGI set (get_system_info/summary/stats, reboot, set_miner_conf)
+// behind digest auth (stock root/root), so transport and parsing reuse the antminer
+// package; this package only adds what differs (vendor/model naming, MH/s hashrate).
+package httpapi
+
+import (
+	"context"
+	"strings"
+
+	amhttp "asic-control/internal/antminer/httpapi"
+	"asic-control/internal/collectors/sdk"
+)
+
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
+
+// ProbeResult is the antminer CGI probe result (responses keyed by endpoint).
+type ProbeResult = amhttp.ProbeResult
+
+// CommandResult is the outcome of a write/control call.
+type CommandResult = amhttp.CommandResult
+
+// Pool is one pool slot.
+type Pool = sdk.Pool
+
+type Facts struct {
+	Model       string
+	Firmware    string
+	MAC         string
+	Worker      string
+	UptimeS     uint64
+	HashrateTHS float64
+	FansRPM     []int
+	TempsC      []float64
+	Chains      []sdk.Chain
+	Pools       []sdk.PoolStatus
+}
+
+// Probe reads the CGI status endpoints with the first accepted credential.
+func Probe(ctx context.Context, host string, creds []Cred, schemes []string) ProbeResult {
+	return amhttp.ProbeAntminerSchemes(ctx, host, creds, schemes)
+}
+
+// ExtractFacts maps the CGI responses onto device facts (hashrate arrives in MH/s).
+func ExtractFacts(res ProbeResult) Facts {
+	af := amhttp.ExtractFacts(res)
+	f := Facts{
+		Model:       strings.TrimSpace(strings.TrimPrefix(af.Model, "Antminer ")),
+		Firmware:    af.Firmware,
+		MAC:         af.MAC,
+		Worker:      af.Worker,
+		UptimeS:     af.UptimeS,
+		HashrateTHS: af.HashrateTHS,
+		FansRPM:     af.FansRPM,
+		TempsC:      af.TempsC,
+		Chains:      af.Chains,
+		Pools:       af.Pools,
+	}
+	if f.Model != "" && !strings.HasPrefix(strings.ToUpper(f.Model), "ELPHAPEX") {
+		f.Model = "Elphapex " + f.Model
+	}
+	return f
+}
+
+// Reboot reboots the control board through /cgi-bin/reboot.cgi.
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	return amhttp.Reboot(ctx, host, creds, schemes)
+}
+
+// SetPools writes pools 1..3 through /cgi-bin/set_miner_conf.cgi (same document as Bitmain).
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	return amhttp.SetPools(ctx, host, creds, schemes, pools)
+}
@@ -26,6 +26,9 @@ type Schema struct {
 	PollResult      *desc.MessageDescriptor
 	DeviceStateUpdated *desc.MessageDescriptor
 	AlertRaised     *desc.MessageDescriptor
+	CommandRequest  *desc.MessageDescriptor
+	CommandResult   *desc.MessageDescriptor
+	DeviceLog       *desc.MessageDescriptor
 }
 
 var (
@@ -58,6 +61,9 @@ func LoadSchema() (*Schema, error) {
 			PollResult:         fd.FindMessage("mona.events.v1.PollResult"),
 			DeviceStateUpdated: fd.FindMessage("mona.events.v1.DeviceStateUpdated"),
 			AlertRaised:        fd.FindMessage("mona.events.v1.AlertRaised"),
+			CommandRequest:     fd.FindMessage("mona.events.v1.CommandRequest"),
+			CommandResult:      fd.FindMessage("mona.events.v1.CommandResult"),
+			DeviceLog:          fd.FindMessage("mona.events.v1.DeviceLog"),
 		}
 		if schemaInst.Envelope == nil {
 			schemaErr = fmt.Errorf("schema: missing Envelope descriptor")
@@ -8,6 +8,7 @@ const (
 	DomainPoll    = "poll"
 	DomainDevice  = "device"
 	DomainAlert   = "alert"
+	DomainCommand = "command"
 )
 
 const (
@@ -18,7 +19,11 @@ const (
 	PollResult  = DomainPoll + ".result"
 
 	DeviceStateUpdated = DomainDevice + ".state_updated"
+	DeviceLog          = DomainDevice + ".log"
 
 	AlertRaised = DomainAlert + ".raised"
+
+	CommandRequest = DomainCommand + ".request"
+	CommandResult  = DomainCommand + ".result"
 )
 
@@ -0,0 +1,19 @@
+package httpapi
+
+import "context"
+
+// Reboot reboots the control board. Older firmware only knows the cgminer restart.
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	r := s.Write(ctx, "PUT", "/mcb/reboot", map[string]any{})
+	if r.OK {
+		return r
+	}
+	if r2 := s.Write(ctx, "PUT", "/mcb/restart", map[string]any{}); r2.OK {
+		return r2
+	}
+	return r
+}
@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"context"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver logs into the Goldshell web API (JWT) and reads status/devs/pools.
+type driver struct{}
+
+func (driver) Name() string { return "goldshell" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "goldshell" {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	res := Probe(ctx, t.IP, creds, t.Schemes())
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	res, ok := pr.Reply.(ProbeResult)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := ExtractFacts(res)
+	return sdk.Facts{
+		Vendor:      "goldshell",
+		Model:       f.Model,
+		Firmware:    f.Firmware,
+		Worker:      f.Worker,
+		MAC:         f.MAC,
+		UptimeS:     f.UptimeS,
+		HashrateTHS: f.HashrateTHS,
+		FansRPM:     f.FansRPM,
+		TempsC:      f.TempsC,
+		Pools:       poolStatuses(res.Telemetry),
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, t.IP, creds, t.Schemes()))
+		},
+		SetPools: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, pools []sdk.Pool) sdk.CommandResult {
+			return commandResult(SetPools(ctx, t.IP, creds, t.Schemes(), pools))
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, UsedCred: r.UsedCred, Error: r.Error}
+}
+
+// poolStatuses maps /mcb/pools; the firmware only flags the active pool, so the rest have
+// no status.
+func poolStatuses(t *Telemetry) []sdk.PoolStatus {
+	if t == nil {
+		return nil
+	}
+	var out []sdk.PoolStatus
+	for _, p := range t.Pools {
+		st := sdk.PoolStatus{
+			URL:      p.URL,
+			User:     p.User,
+			Priority: p.Priority,
+			Active:   p.Active,
+			Accepted: uint64(max(p.Accepted, 0)),
+			Rejected: uint64(max(p.Rejected, 0)),
+			Stale:    uint64(max(p.Stale, 0)),
+		}
+		if p.Active {
+			st.Status = "alive"
+		}
+		out = append(out, st)
+	}
+	return out
+}
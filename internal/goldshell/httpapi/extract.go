@@ -0,0 +1,44 @@
+package httpapi
+
+type Facts struct {
+	Model       string
+	Firmware    string
+	MAC         string
+	Worker      string
+	UptimeS     uint64
+	HashrateTHS float64
+	FansRPM     []int
+	TempsC      []float64
+}
+
+// ExtractFacts maps Goldshell telemetry onto device facts.
+func ExtractFacts(res ProbeResult) Facts {
+	var f Facts
+	t := res.Telemetry
+	if t == nil {
+		return f
+	}
+	f.Model = t.Model
+	f.Firmware = t.Firmware
+	f.MAC = t.MAC
+	for _, c := range t.Chains {
+		f.HashrateTHS += c.HashrateTHS
+		if c.FanRPM > 0 {
+			f.FansRPM = append(f.FansRPM, c.FanRPM)
+		}
+		if tc := max(c.ChipTempC, c.TempC); tc > 0 {
+			f.TempsC = append(f.TempsC, tc)
+		}
+		f.UptimeS = max(f.UptimeS, c.UptimeS)
+	}
+	for _, p := range t.Pools {
+		if p.Active && p.User != "" {
+			f.Worker = p.User
+			break
+		}
+	}
+	if f.Worker == "" && len(t.Pools) > 0 {
+		f.Worker = t.Pools[0].User
+	}
+	return f
+}
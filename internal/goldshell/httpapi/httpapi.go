@@ -0,0 +1,189 @@
+// Package httpapi talks to the Goldshell control board web API (KD/CK/LT/HS/AL-BOX families).
+//
+//	GET /user/login?username=&password=&cipher=false  {"JWT Token": "..."}
+//	GET /mcb/status                                   model, firmware, MAC
+//	GET /mcb/cgminer?cgicmd=devs                      {"data":[{per-chain hashrate/temps/fans}]}
+//	GET /mcb/pools                                    [{"url","user","active","accepted",...}]
+//	PUT /mcb/delpool, /mcb/newpool                    pool edits
+//
+// Field names drift between firmware releases, so documents are read through a
+// case-insensitive lookup instead of fixed structs.
+package httpapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Chain is one hashboard from the devs document.
+type Chain struct {
+	ID          int     `json:"id"`
+	HashrateTHS float64 `json:"hashrate_ths,omitempty"`
+	TempC       float64 `json:"temp_c,omitempty"`
+	ChipTempC   float64 `json:"chip_temp_c,omitempty"`
+	FanRPM      int     `json:"fan_rpm,omitempty"`
+	UptimeS     uint64  `json:"uptime_s,omitempty"`
+}
+
+// PoolStat is one configured pool.
+type PoolStat struct {
+	URL      string `json:"url"`
+	User     string `json:"user"`
+	Active   bool   `json:"active"`
+	Priority int    `json:"priority"`
+	Accepted int64  `json:"accepted,omitempty"`
+	Rejected int64  `json:"rejected,omitempty"`
+	Stale    int64  `json:"stale,omitempty"`
+}
+
+// Telemetry is everything read in one probe.
+type Telemetry struct {
+	Model    string     `json:"model,omitempty"`
+	Firmware string     `json:"firmware,omitempty"`
+	MAC      string     `json:"mac,omitempty"`
+	Chains   []Chain    `json:"chains,omitempty"`
+	Pools    []PoolStat `json:"pools,omitempty"`
+}
+
+type ProbeResult struct {
+	OK        bool       `json:"ok"`
+	Scheme    string     `json:"scheme,omitempty"`
+	UsedCred  string     `json:"used_cred,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"`
+}
+
+// Probe logs in and reads status, devs and pools. Status is required.
+func Probe(ctx context.Context, host string, creds []Cred, schemes []string) ProbeResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return ProbeResult{OK: false, Error: err.Error()}
+	}
+	out := ProbeResult{Scheme: s.Scheme, UsedCred: s.UsedCred}
+	t, err := s.Read(ctx)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.OK = true
+	out.Telemetry = t
+	return out
+}
+
+// Read collects the telemetry documents over an open session.
+func (s *Session) Read(ctx context.Context) (*Telemetry, error) {
+	st, err := s.GetJSON(ctx, "/mcb/status")
+	if err != nil {
+		return nil, err
+	}
+	t := &Telemetry{
+		Model:    firstStr(st, "model", "minertype", "miner_type"),
+		Firmware: firstStr(st, "firmware", "firmware_version", "version"),
+		MAC:      strings.ToLower(firstStr(st, "macaddress", "mac", "mac_address")),
+	}
+	if devs, err := s.GetJSON(ctx, "/mcb/cgminer?cgicmd=devs"); err == nil {
+		for i, d := range list(get(devs, "data")) {
+			c := Chain{
+				ID: i,
+				// Goldshell reports MH/s
+				HashrateTHS: num(get(d, "hashrate")) / 1e6,
+				TempC:       num(get(d, "temp")),
+				ChipTempC:   num(get(d, "tstemp-1")),
+				FanRPM:      int(num(get(d, "fanspeed"))),
+				UptimeS:     uint64(num(get(d, "time"))),
+			}
+			if v := get(d, "chain"); v != nil {
+				c.ID = int(num(v))
+			}
+			t.Chains = append(t.Chains, c)
+		}
+	}
+	if ps, err := s.GetJSON(ctx, "/mcb/pools"); err == nil {
+		items := list(ps)
+		if items == nil {
+			items = list(get(ps, "data"))
+		}
+		for _, p := range items {
+			t.Pools = append(t.Pools, PoolStat{
+				URL:      str(get(p, "url")),
+				User:     str(get(p, "user")),
+				Active:   truthy(get(p, "active")),
+				Priority: int(num(get(p, "pool-priority"))),
+				Accepted: int64(num(get(p, "accepted"))),
+				Rejected: int64(num(get(p, "rejected"))),
+				Stale:    int64(num(get(p, "stale"))),
+			})
+		}
+	}
+	return t, nil
+}
+
+// get looks a key up case-insensitively; missing keys (or non-objects) give nil.
+func get(v any, key string) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if x, ok := m[key]; ok {
+		return x
+	}
+	for k, x := range m {
+		if strings.EqualFold(k, key) {
+			return x
+		}
+	}
+	return nil
+}
+
+func firstStr(v any, keys ...string) string {
+	for _, k := range keys {
+		if s := str(get(v, k)); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func str(v any) string {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return ""
+}
+
+// num accepts numbers and numeric strings with a unit suffix ("5960 rpm", "63.5 C").
+func num(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case string:
+		f := strings.Fields(x)
+		if len(f) == 0 {
+			return 0
+		}
+		n, _ := strconv.ParseFloat(strings.TrimRight(f[0], "CcRPMrpm°"), 64)
+		return n
+	}
+	return 0
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case float64:
+		return x != 0
+	case string:
+		return strings.EqualFold(x, "true") || x == "1"
+	}
+	return false
+}
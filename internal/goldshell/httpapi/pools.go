@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"context"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// Pool is one pool slot.
+type Pool = sdk.Pool
+
+// SetPools replaces the pool list: every configured pool is deleted, then the new ones are
+// added in order (the firmware has no bulk update). Empty slots are dropped.
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	var want []Pool
+	for _, p := range pools {
+		if strings.TrimSpace(p.URL) != "" {
+			want = append(want, p)
+		}
+	}
+	if len(want) == 0 {
+		return CommandResult{OK: false, Error: "no pools"}
+	}
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	cur, err := s.GetJSON(ctx, "/mcb/pools")
+	if err != nil {
+		return CommandResult{OK: false, Scheme: s.Scheme, UsedCred: s.UsedCred, Error: "read pools: " + err.Error()}
+	}
+	items := list(cur)
+	if items == nil {
+		items = list(get(cur, "data"))
+	}
+	for _, p := range items {
+		r := s.Write(ctx, "PUT", "/mcb/delpool", map[string]any{
+			"url":    str(get(p, "url")),
+			"user":   str(get(p, "user")),
+			"pass":   str(get(p, "pass")),
+			"dragid": int(num(get(p, "dragid"))),
+		})
+		if !r.OK {
+			r.Error = "delete pool: " + r.Error
+			return r
+		}
+	}
+	var out CommandResult
+	for _, p := range want {
+		out = s.Write(ctx, "PUT", "/mcb/newpool", map[string]any{"url": p.URL, "user": p.User, "pass": p.Pass})
+		if !out.OK {
+			out.Error = "add pool: " + out.Error
+			return out
+		}
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Session is a logged-in Goldshell web session. GET /user/login returns a JWT that is sent
+// as a bearer token; the control board only keeps one login, so we log out first.
+type Session struct {
+	Host     string
+	Scheme   string
+	UsedCred string
+
+	client *http.Client
+	token  string
+}
+
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
+
+// CommandResult is the outcome of a write/control call against a Goldshell.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	Scheme   string `json:"scheme,omitempty"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+var errLogin = errors.New("goldshell login failed")
+
+// Login opens a session with the given credential (stock: admin / 123456789).
+func Login(ctx context.Context, host, scheme string, cred Cred) (*Session, error) {
+	s := &Session{Host: host, Scheme: scheme, UsedCred: cred.Name, client: sessionutil.NewClient(false)}
+	user := cred.Username
+	if user == "" {
+		user = "admin"
+	}
+	_, _, _ = s.Do(ctx, "GET", "/user/logout", nil)
+	q := url.Values{"username": {user}, "password": {cred.Password}, "cipher": {"false"}}
+	code, b, err := s.Do(ctx, "GET", "/user/login?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if code < 200 || code > 299 {
+		return nil, errLogin
+	}
+	var m map[string]any
+	if json.Unmarshal(b, &m) != nil {
+		return nil, errLogin
+	}
+	if t := str(get(m, "JWT Token")); t != "" {
+		s.token = t
+		return s, nil
+	}
+	return nil, errLogin
+}
+
+// LoginAny tries all creds/schemes and returns the first accepted session.
+func LoginAny(ctx context.Context, host string, creds []Cred, schemes []string) (*Session, error) {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var lastErr error = errLogin
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if scheme != "http" && scheme != "https" {
+			continue
+		}
+		for _, c := range creds {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s, err := Login(ctx, host, scheme, c)
+			if err == nil {
+				return s, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// Do sends a JSON request (body may be nil) and returns status + raw body.
+func (s *Session) Do(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.Scheme+"://"+s.Host+path, rd)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Close = true
+	req.Header.Set("Connection", "close")
+	req.Header.Set("User-Agent", "MonA/asic-control")
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	_ = resp.Body.Close()
+	return resp.StatusCode, b, nil
+}
+
+// GetJSON fetches path and decodes it (object or array).
+func (s *Session) GetJSON(ctx context.Context, path string) (any, error) {
+	code, b, err := s.Do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if code == 401 || code == 403 {
+		return nil, errors.New("unauthorized")
+	}
+	if code < 200 || code > 299 {
+		return nil, errors.New("http " + http.StatusText(code))
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Write sends a control request; a dropped connection after sending counts as success.
+func (s *Session) Write(ctx context.Context, method, path string, body any) CommandResult {
+	out := CommandResult{Scheme: s.Scheme, UsedCred: s.UsedCred}
+	code, b, err := s.Do(ctx, method, path, body)
+	if err != nil {
+		if sessionutil.IsDropAfterSend(err) {
+			out.OK = true
+			return out
+		}
+		out.Error = err.Error()
+		return out
+	}
+	out.Body = sessionutil.TruncBody(b)
+	switch {
+	case code == 401 || code == 403:
+		out.Error = "unauthorized"
+	case code < 200 || code > 299:
+		out.Error = "http " + http.StatusText(code)
+	default:
+		out.OK = true
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package httpapi
+
+import (
+	"context"
+	"net/url"
+)
+
+// Reboot reboots the control board (whole miner).
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	return s.Write(ctx, "reboot", url.Values{"post": {"1"}})
+}
+
+// SetLocate toggles the locate (fault) LED.
+func SetLocate(ctx context.Context, host string, creds []Cred, schemes []string, on bool) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	v := "0"
+	if on {
+		v = "1"
+	}
+	return s.Write(ctx, "userpanel", url.Values{"post": {"5"}, "locate": {v}})
+}
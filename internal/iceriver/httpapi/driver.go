@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver logs into the IceRiver web UI and reads the userpanel document.
+type driver struct{}
+
+func (driver) Name() string { return "iceriver" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "iceriver" {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	res := Probe(ctx, t.IP, creds, t.Schemes())
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	res, ok := pr.Reply.(ProbeResult)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := ExtractFacts(res)
+	return sdk.Facts{
+		Vendor:      "iceriver",
+		Model:       f.Model,
+		Firmware:    f.Firmware,
+		Worker:      f.Worker,
+		MAC:         f.MAC,
+		UptimeS:     f.UptimeS,
+		HashrateTHS: f.HashrateTHS,
+		FansRPM:     f.FansRPM,
+		TempsC:      f.TempsC,
+		Pools:       poolStatuses(f.Pools),
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, t.IP, creds, t.Schemes()))
+		},
+		SetPools: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, pools []sdk.Pool) sdk.CommandResult {
+			return commandResult(SetPools(ctx, t.IP, creds, t.Schemes(), pools))
+		},
+		Control: map[string]sdk.ControlFunc{
+			sdk.KindSetLED: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, args map[string]string) sdk.CommandResult {
+				return commandResult(SetLocate(ctx, t.IP, creds, t.Schemes(), sdk.LEDOn(args)))
+			},
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, UsedCred: r.UsedCred, Error: r.Error}
+}
+
+// poolStatuses maps userpanel pools: connect is the live link, lstime the last share time.
+func poolStatuses(ps []PoolStat) []sdk.PoolStatus {
+	var out []sdk.PoolStatus
+	now := time.Now()
+	for _, p := range ps {
+		url := strings.TrimSpace(string(p.Addr))
+		if url == "" {
+			continue
+		}
+		st := sdk.PoolStatus{
+			URL:        url,
+			User:       strings.TrimSpace(string(p.User)),
+			Status:     "dead",
+			Priority:   int(p.No.Int()),
+			Accepted:   uint64(max(p.Accepted.Int(), 0)),
+			Rejected:   uint64(max(p.Rejected.Int(), 0)),
+			LastShare:  cgminer.ShareTime(string(p.LastTime), now),
+			Difficulty: cgminer.ParseDiff(string(p.Diff)),
+		}
+		if p.Connect {
+			st.Status = "alive"
+		}
+		out = append(out, st)
+	}
+	sdk.MarkActive(out)
+	return out
+}
@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"strconv"
+	"strings"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+type Facts struct {
+	Model       string
+	Firmware    string
+	MAC         string
+	Worker      string
+	UptimeS     uint64
+	HashrateTHS float64
+	FansRPM     []int
+	TempsC      []float64
+	Pools       []PoolStat
+}
+
+// ExtractFacts maps the userpanel document onto device facts.
+func ExtractFacts(res ProbeResult) Facts {
+	var f Facts
+	u := res.Userpanel
+	if u == nil {
+		return f
+	}
+	f.Model = strings.TrimSpace(string(u.Model))
+	if f.Model == "" || strings.EqualFold(f.Model, "none") {
+		f.Model = strings.TrimSpace(string(u.Type))
+	}
+	f.Firmware = sessionutil.FirstNonEmpty(string(u.FirmVer), string(u.SoftVer))
+	f.MAC = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(string(u.MAC)), "-", ":"))
+	f.UptimeS = parseRuntime(string(u.Runtime))
+	f.HashrateTHS = hashrateTHS(string(u.RTPow), string(u.Unit))
+	if f.HashrateTHS == 0 {
+		f.HashrateTHS = hashrateTHS(string(u.AvgPow), string(u.Unit))
+	}
+	for _, rpm := range u.Fans {
+		f.FansRPM = append(f.FansRPM, int(rpm.Int()))
+	}
+	for _, b := range u.Boards {
+		// outtmp is the hottest (exhaust side) chip sensor
+		if t := max(b.OutTemp.Float(), b.InTemp.Float()); t > 0 {
+			f.TempsC = append(f.TempsC, t)
+		}
+	}
+	f.Pools = u.Pools
+	for _, p := range u.Pools {
+		if p.Connect && strings.TrimSpace(string(p.User)) != "" {
+			f.Worker = string(p.User)
+			break
+		}
+	}
+	if f.Worker == "" {
+		for _, p := range u.Pools {
+			if strings.TrimSpace(string(p.User)) != "" {
+				f.Worker = string(p.User)
+				break
+			}
+		}
+	}
+	return f
+}
+
+// parseRuntime turns "DD:HH:MM:SS" (or "HH:MM:SS") into seconds.
+func parseRuntime(s string) uint64 {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return 0
+	}
+	mul := []uint64{1, 60, 3600, 86400}
+	var total uint64
+	for i := range parts {
+		n, err := strconv.ParseUint(strings.TrimSpace(parts[len(parts)-1-i]), 10, 64)
+		if err != nil {
+			return 0
+		}
+		total += n * mul[i]
+	}
+	return total
+}
+
+func parseF64(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f
+}
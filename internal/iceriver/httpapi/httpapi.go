@@ -0,0 +1,130 @@
+// Package httpapi talks to the stock IceRiver (KS*/AL*/KA*) web API.
+//
+// Everything the UI shows comes from one call, POST /user/userpanel?post=4:
+//
+//	{"error":0,"data":{"model":"KS3M","softver1":"BOOT_1.1","firmver1":"...","mac":"...",
+//	 "runtime":"01:02:03:04","unit":"G","rtpow":"6120.5G","avgpow":"6050.1G",
+//	 "fans":[3960,4020,3960,4020],"locate":false,
+//	 "boards":[{"no":1,"chipnum":...,"rtpow":"2040.1G","intmp":41,"outtmp":63,...}],
+//	 "pools":[{"no":1,"addr":"stratum+tcp://...","user":"acc.w1","connect":true,"accepted":...}]}}
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"asic-control/internal/cgminer"
+)
+
+// Userpanel is the subset of the userpanel document MonA uses. Field types are the tolerant
+// cgminer ones: firmware builds disagree on quoting numbers.
+type Userpanel struct {
+	Model    cgminer.Text     `json:"model"`
+	Type     cgminer.Text     `json:"type,omitempty"`
+	Host     cgminer.Text     `json:"host,omitempty"`
+	MAC      cgminer.Text     `json:"mac,omitempty"`
+	SoftVer  cgminer.Text     `json:"softver1,omitempty"`
+	FirmVer  cgminer.Text     `json:"firmver1,omitempty"`
+	Runtime  cgminer.Text     `json:"runtime,omitempty"` // DD:HH:MM:SS
+	Unit     cgminer.Text     `json:"unit,omitempty"`    // hashrate unit suffix: M/G/T
+	RTPow    cgminer.Text     `json:"rtpow,omitempty"`
+	AvgPow   cgminer.Text     `json:"avgpow,omitempty"`
+	Fans     []cgminer.Number `json:"fans,omitempty"`
+	Locate   cgminer.Bool     `json:"locate,omitempty"`
+	PowState cgminer.Bool     `json:"powstate,omitempty"`
+	Boards   []Board          `json:"boards,omitempty"`
+	Pools    []PoolStat       `json:"pools,omitempty"`
+}
+
+// Board is one hashboard entry.
+type Board struct {
+	No      cgminer.Number `json:"no"`
+	ChipNum cgminer.Number `json:"chipnum"`
+	ChipSuc cgminer.Number `json:"chipsuc"`
+	Error   cgminer.Number `json:"error"`
+	Freq    cgminer.Number `json:"freq"`
+	RTPow   cgminer.Text   `json:"rtpow"`
+	AvgPow  cgminer.Text   `json:"avgpow"`
+	InTemp  cgminer.Number `json:"intmp"`
+	OutTemp cgminer.Number `json:"outtmp"`
+}
+
+// PoolStat is one configured pool with its live counters.
+type PoolStat struct {
+	No       cgminer.Number `json:"no"`
+	Addr     cgminer.Text   `json:"addr"`
+	User     cgminer.Text   `json:"user"`
+	Connect  cgminer.Bool   `json:"connect"`
+	State    cgminer.Number `json:"state"`
+	Diff     cgminer.Text   `json:"diff,omitempty"`
+	Accepted cgminer.Number `json:"accepted"`
+	Rejected cgminer.Number `json:"rejected"`
+	LastTime cgminer.Text   `json:"lstime,omitempty"`
+}
+
+type ProbeResult struct {
+	OK        bool       `json:"ok"`
+	Scheme    string     `json:"scheme,omitempty"`
+	UsedCred  string     `json:"used_cred,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Userpanel *Userpanel `json:"userpanel,omitempty"`
+}
+
+// ReadUserpanel fetches the status document over an open session.
+func (s *Session) ReadUserpanel(ctx context.Context) (*Userpanel, error) {
+	data, err := s.Call(ctx, "userpanel", url.Values{"post": {"4"}})
+	if err != nil {
+		return nil, err
+	}
+	var u Userpanel
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Probe logs in (first accepted credential wins) and reads the userpanel.
+func Probe(ctx context.Context, host string, creds []Cred, schemes []string) ProbeResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return ProbeResult{OK: false, Error: err.Error()}
+	}
+	out := ProbeResult{Scheme: s.Scheme, UsedCred: s.UsedCred}
+	u, err := s.ReadUserpanel(ctx)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.OK = true
+	out.Userpanel = u
+	return out
+}
+
+// hashrateTHS converts "6120.5G" style values (unit from the document when there is no suffix).
+func hashrateTHS(v, unit string) float64 {
+	v = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(v), "/s"), "H")
+	if v == "" {
+		return 0
+	}
+	u := strings.ToUpper(strings.TrimSpace(unit))
+	if n := len(v); n > 0 && strings.ContainsRune("KMGTP", rune(v[n-1]&^0x20)) {
+		u = strings.ToUpper(v[n-1:])
+		v = v[:n-1]
+	}
+	f := parseF64(v)
+	switch u {
+	case "P":
+		return f * 1e3
+	case "T":
+		return f
+	case "G", "":
+		return f / 1e3
+	case "M":
+		return f / 1e6
+	case "K":
+		return f / 1e9
+	}
+	return f / 1e3
+}
@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// Pool is one pool slot (IceRiver has exactly 3).
+type Pool = sdk.Pool
+
+// SetPools writes pools 1..3 through the pool settings form (/user/pools). The miner
+// restarts its mining process to apply them.
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	if len(pools) == 0 {
+		return CommandResult{OK: false, Error: "no pools"}
+	}
+	for len(pools) < 3 {
+		pools = append(pools, Pool{})
+	}
+	pools = pools[:3]
+
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	form := url.Values{"post": {"1"}}
+	for i, p := range pools {
+		n := strconv.Itoa(i + 1)
+		form.Set("pool"+n+"address", strings.TrimSpace(p.URL))
+		form.Set("pool"+n+"miner", strings.TrimSpace(p.User))
+		form.Set("pool"+n+"pwd", p.Pass)
+	}
+	return s.Write(ctx, "pools", form)
+}
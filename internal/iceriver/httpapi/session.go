@@ -0,0 +1,180 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Session is a logged-in IceRiver web session. The stock web UI keeps auth in a cookie set by
+// /user/loginpost; every API call is a POST /user/<command> with a numeric "post" selector.
+type Session struct {
+	Host     string
+	Scheme   string
+	UsedCred string
+
+	client   *http.Client
+	location string // Location header of the last response
+}
+
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
+
+// CommandResult is the outcome of a write/control call against an IceRiver.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	Scheme   string `json:"scheme,omitempty"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+var errLogin = errors.New("iceriver login failed")
+
+// Login opens a session with the given credential (stock: admin / 12345678).
+func Login(ctx context.Context, host, scheme string, cred Cred) (*Session, error) {
+	s := &Session{Host: host, Scheme: scheme, UsedCred: cred.Name, client: sessionutil.NewClient(true)}
+	// a successful login redirects to the panel; we only need the cookie
+	s.client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	user := cred.Username
+	if user == "" {
+		user = "admin"
+	}
+	code, b, err := s.post(ctx, "loginpost", url.Values{"post": {"6"}, "user": {user}, "pwd": {cred.Password}})
+	if err != nil {
+		return nil, err
+	}
+	if code == 401 || code == 403 {
+		return nil, errLogin
+	}
+	// Newer builds answer {"error":0,...}; older ones redirect to /index with the cookie set.
+	var r reply
+	if json.Unmarshal(sessionutil.SanitizeJSON(b), &r) == nil && r.Error != nil {
+		if *r.Error != 0 {
+			return nil, errLogin
+		}
+		return s, nil
+	}
+	if (code == 302 || code == 303) && !strings.Contains(strings.ToLower(s.location), "login") {
+		return s, nil
+	}
+	return nil, errLogin
+}
+
+// LoginAny tries all creds/schemes and returns the first accepted session.
+func LoginAny(ctx context.Context, host string, creds []Cred, schemes []string) (*Session, error) {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var lastErr error = errLogin
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if scheme != "http" && scheme != "https" {
+			continue
+		}
+		for _, c := range creds {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s, err := Login(ctx, host, scheme, c)
+			if err == nil {
+				return s, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// reply is the common envelope: {"error":0,"data":{...}} (non-zero error = failure).
+type reply struct {
+	Error   *int            `json:"error"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Call posts params to /user/<command> and returns the decoded envelope.
+func (s *Session) Call(ctx context.Context, command string, params url.Values) (json.RawMessage, error) {
+	code, b, err := s.post(ctx, command, params)
+	if err != nil {
+		return nil, err
+	}
+	if code == 401 || code == 403 {
+		return nil, errors.New("unauthorized")
+	}
+	if code < 200 || code > 299 {
+		return nil, errors.New("http " + http.StatusText(code))
+	}
+	var r reply
+	if err := json.Unmarshal(sessionutil.SanitizeJSON(b), &r); err != nil {
+		return nil, err
+	}
+	if r.Error != nil && *r.Error != 0 {
+		msg := strings.TrimSpace(r.Message)
+		if msg == "" {
+			msg = "error " + string(sessionutil.SanitizeJSON(b))
+		}
+		return nil, errors.New("iceriver: " + msg)
+	}
+	return r.Data, nil
+}
+
+// Write posts a control command. A dropped connection after sending counts as success
+// (reboot/apply take the web server down with them).
+func (s *Session) Write(ctx context.Context, command string, params url.Values) CommandResult {
+	out := CommandResult{Scheme: s.Scheme, UsedCred: s.UsedCred}
+	code, b, err := s.post(ctx, command, params)
+	if err != nil {
+		if sessionutil.IsDropAfterSend(err) {
+			out.OK = true
+			return out
+		}
+		out.Error = err.Error()
+		return out
+	}
+	out.Body = sessionutil.TruncBody(b)
+	if code < 200 || code > 299 {
+		out.Error = "http " + http.StatusText(code)
+		return out
+	}
+	var r reply
+	if json.Unmarshal(sessionutil.SanitizeJSON(b), &r) == nil && r.Error != nil && *r.Error != 0 {
+		out.Error = "iceriver: " + sessionutil.FirstNonEmpty(r.Message, out.Body)
+		return out
+	}
+	out.OK = true
+	return out
+}
+
+func (s *Session) post(ctx context.Context, command string, params url.Values) (int, []byte, error) {
+	u := s.Scheme + "://" + s.Host + "/user/" + command
+	if len(params) > 0 {
+		// the web UI sends parameters both ways depending on the build; the query is always read
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", u, strings.NewReader(params.Encode()))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Close = true
+	req.Header.Set("Connection", "close")
+	req.Header.Set("User-Agent", "MonA/asic-control")
+	req.Header.Set("Accept", "application/json,text/plain;q=0.9,*/*;q=0.8")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	_ = resp.Body.Close()
+	s.location = resp.Header.Get("Location")
+	return resp.StatusCode, b, nil
+}
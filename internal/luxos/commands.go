@@ -0,0 +1,111 @@
+package luxos
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"asic-control/internal/cgminer"
+)
+
+// CommandResult is the outcome of a session-guarded LuxOS write.
+type CommandResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+type session struct {
+	SessionID cgminer.Text `json:"SessionID"`
+}
+
+// Logon opens a write session. LuxOS allows a single session: when one is already open
+// (another tool, or a previous run that did not log off) its id is reused.
+func Logon(ctx context.Context, c *cgminer.Client) (string, error) {
+	resp, err := c.Call(ctx, "logon", "")
+	if err == nil {
+		var s []session
+		if resp.Decode("SESSION", &s) == nil && len(s) > 0 && strings.TrimSpace(string(s[0].SessionID)) != "" {
+			return strings.TrimSpace(string(s[0].SessionID)), nil
+		}
+	}
+	if resp, err2 := c.Call(ctx, "session", ""); err2 == nil {
+		var s []session
+		if resp.Decode("SESSION", &s) == nil && len(s) > 0 && strings.TrimSpace(string(s[0].SessionID)) != "" {
+			return strings.TrimSpace(string(s[0].SessionID)), nil
+		}
+	}
+	if err == nil {
+		err = errors.New("luxos: logon returned no session id")
+	}
+	return "", err
+}
+
+// Logoff closes a session opened by Logon.
+func Logoff(ctx context.Context, c *cgminer.Client, sid string) error {
+	_, err := c.Call(ctx, "logoff", sid)
+	return err
+}
+
+// write runs one session-guarded command: "<command> <sid>[,<args>]".
+func write(ctx context.Context, c *cgminer.Client, command string, args ...string) CommandResult {
+	sid, err := Logon(ctx, c)
+	if err != nil {
+		return CommandResult{Error: err.Error()}
+	}
+	param := strings.Join(append([]string{sid}, args...), ",")
+	resp, err := c.Call(ctx, command, param)
+	out := CommandResult{}
+	if resp != nil && len(resp.Status) > 0 {
+		out.Body = resp.Status[0].Msg
+	}
+	if err != nil {
+		out.Error = err.Error()
+		if command == "rebootdevice" && isDrop(err) {
+			// the controller may go down before answering
+			out.OK, out.Error = true, ""
+			return out
+		}
+	} else {
+		out.OK = true
+	}
+	if command != "rebootdevice" {
+		_ = Logoff(ctx, c, sid)
+	}
+	return out
+}
+
+// Reboot restarts the control board.
+func Reboot(ctx context.Context, c *cgminer.Client) CommandResult {
+	return write(ctx, c, "rebootdevice")
+}
+
+// Curtail puts the miner to sleep (hashboards off, fans low) or wakes it up.
+func Curtail(ctx context.Context, c *cgminer.Client, sleep bool) CommandResult {
+	if sleep {
+		return write(ctx, c, "curtail", "sleep")
+	}
+	return write(ctx, c, "curtail", "wakeup")
+}
+
+// SetProfile switches every board to a profile listed by "profiles" (e.g. "default", "+1", "190MHz").
+func SetProfile(ctx context.Context, c *cgminer.Client, profile string) CommandResult {
+	profile = strings.TrimSpace(profile)
+	if profile == "" || strings.ContainsAny(profile, ",|") {
+		return CommandResult{Error: "bad profile name"}
+	}
+	return write(ctx, c, "profileset", profile)
+}
+
+// SetLED blinks the red LED (on) or turns it off.
+func SetLED(ctx context.Context, c *cgminer.Client, on bool) CommandResult {
+	if on {
+		return write(ctx, c, "ledset", "red", "blink")
+	}
+	return write(ctx, c, "ledset", "red", "off")
+}
+
+func isDrop(err error) bool {
+	e := strings.ToLower(err.Error())
+	return strings.Contains(e, "eof") || strings.Contains(e, "empty response") || strings.Contains(e, "connection reset")
+}
@@ -0,0 +1,81 @@
+package luxos
+
+import (
+	"context"
+	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver reads LuxOS over its cgminer API (no credentials); writes use a logon session.
+type driver struct{}
+
+func (driver) Name() string { return "luxos" }
+
+func (driver) Detect(t sdk.Target) int {
+	v := t.VendorKey()
+	if (v == "" || v == "antminer") && t.FirmwareHas("luxos") && t.HasPort(cgminer.DefaultPort) {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, _ []sdk.Cred) sdk.ProbeResult {
+	tel, err := Read(ctx, cgminer.New(t.IP))
+	if err != nil {
+		return sdk.ProbeResult{Error: err.Error()}
+	}
+	return sdk.ProbeResult{OK: true, Reply: tel}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	tel, ok := pr.Reply.(Telemetry)
+	if !ok {
+		return sdk.Facts{}
+	}
+	return sdk.Facts{
+		Vendor:      "antminer",
+		Model:       tel.Model(),
+		Firmware:    tel.Firmware(),
+		Worker:      tel.Worker(),
+		UptimeS:     tel.UptimeS(),
+		HashrateTHS: tel.HashrateTHS(),
+		FansRPM:     tel.FansRPM(),
+		TempsC:      tel.TempsC(),
+		Chains:      cgminer.Chains(tel.Stats),
+		Pools:       cgminer.PoolStatuses(tel.Pools),
+		PowerW:      tel.PowerW,
+		WorkMode:    tel.WorkMode(),
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, _ []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, cgminer.New(t.IP)))
+		},
+		Control: map[string]sdk.ControlFunc{
+			sdk.KindPowerOff: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, _ map[string]string) sdk.CommandResult {
+				return commandResult(Curtail(ctx, cgminer.New(t.IP), true))
+			},
+			sdk.KindPowerOn: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, _ map[string]string) sdk.CommandResult {
+				return commandResult(Curtail(ctx, cgminer.New(t.IP), false))
+			},
+			sdk.KindSetLED: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, args map[string]string) sdk.CommandResult {
+				return commandResult(SetLED(ctx, cgminer.New(t.IP), sdk.LEDOn(args)))
+			},
+			sdk.KindSetProfile: func(ctx context.Context, t sdk.Target, _ []sdk.Cred, args map[string]string) sdk.CommandResult {
+				r := commandResult(SetProfile(ctx, cgminer.New(t.IP), args["profile"]))
+				r.Detail = strings.TrimSpace(args["profile"])
+				return r
+			},
+		},
+	}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, Error: r.Error}
+}
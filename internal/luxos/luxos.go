@@ -0,0 +1,135 @@
+// Package luxos reads and controls Antminers running LuxOS over its extended cgminer API (4028).
+//
+// LuxOS answers the stock commands plus its own: profiles/atm/power for reads and
+// session-guarded writes (logon → SessionID, then "<sid>,..." parameters for profileset,
+// curtail, ledset, rebootdevice). Reads need no session; writes need none of the web
+// credentials either, only a session slot (LuxOS allows one at a time).
+package luxos
+
+import (
+	"context"
+	"strings"
+
+	"asic-control/internal/cgminer"
+)
+
+// IsLuxOS reports whether a VERSION reply came from LUXminer.
+func IsLuxOS(v cgminer.Version) bool {
+	return strings.TrimSpace(v.All["LUXminer"]) != ""
+}
+
+// Profile is one entry of the "profiles" command (frequency/voltage presets).
+type Profile struct {
+	Name      cgminer.Text   `json:"Profile Name"`
+	Frequency cgminer.Number `json:"Frequency"`
+	Voltage   cgminer.Number `json:"Voltage"`
+	Hashrate  cgminer.Number `json:"Hashrate"` // TH/s
+	Watts     cgminer.Number `json:"Watts"`
+	Step      cgminer.Text   `json:"Step"`
+	IsTuned   cgminer.Bool   `json:"IsTuned"`
+}
+
+// ATM is the Advanced Thermal Management state ("atm" command).
+type ATM struct {
+	Enabled         cgminer.Bool   `json:"Enabled"`
+	MaxProfile      cgminer.Text   `json:"MaxProfile"`
+	MinProfile      cgminer.Text   `json:"MinProfile"`
+	PostRampMinutes cgminer.Number `json:"PostRampMinutes"`
+	StartupMinutes  cgminer.Number `json:"StartupMinutes"`
+	TempWindow      cgminer.Number `json:"TempWindow"`
+}
+
+// Telemetry is the credential-less read set used by polling.
+type Telemetry struct {
+	Summary  cgminer.Summary      `json:"summary"`
+	Version  cgminer.Version      `json:"version"`
+	Pools    []cgminer.Pool       `json:"pools,omitempty"`
+	Stats    []cgminer.StatsEntry `json:"stats,omitempty"`
+	Profile  string               `json:"profile,omitempty"` // active profile name
+	Profiles []Profile            `json:"profiles,omitempty"`
+	ATM      *ATM                 `json:"atm,omitempty"`
+	PowerW   float64              `json:"power_w,omitempty"`
+}
+
+// Read collects summary, version, pools, stats and the LuxOS specific state. Only summary is required.
+func Read(ctx context.Context, c *cgminer.Client) (Telemetry, error) {
+	var t Telemetry
+	sum, err := c.Summary(ctx)
+	if err != nil {
+		return t, err
+	}
+	t.Summary = sum
+	if v, err := c.Version(ctx); err == nil {
+		t.Version = v
+	}
+	if ps, err := c.Pools(ctx); err == nil {
+		t.Pools = ps
+	}
+	if st, err := c.Stats(ctx); err == nil {
+		t.Stats = st
+	}
+	if resp, err := c.Call(ctx, "config", ""); err == nil {
+		var cfg []map[string]cgminer.Text
+		if resp.Decode("CONFIG", &cfg) == nil && len(cfg) > 0 {
+			t.Profile = strings.TrimSpace(string(cfg[0]["Profile"]))
+		}
+	}
+	if resp, err := c.Call(ctx, "profiles", ""); err == nil {
+		_ = resp.Decode("PROFILES", &t.Profiles)
+	}
+	if resp, err := c.Call(ctx, "atm", ""); err == nil {
+		var atm []ATM
+		if resp.Decode("ATM", &atm) == nil && len(atm) > 0 {
+			t.ATM = &atm[0]
+		}
+	}
+	if resp, err := c.Call(ctx, "power", ""); err == nil {
+		var pw []struct {
+			Watts cgminer.Number `json:"Watts"`
+		}
+		if resp.Decode("POWER", &pw) == nil && len(pw) > 0 {
+			t.PowerW = pw[0].Watts.Float()
+		}
+	}
+	return t, nil
+}
+
+func (t Telemetry) Model() string {
+	if m := strings.TrimSpace(t.Version.Type); m != "" {
+		return m
+	}
+	return cgminer.Snapshot{Stats: t.Stats}.Model()
+}
+
+func (t Telemetry) Firmware() string {
+	if v := strings.TrimSpace(t.Version.All["LUXminer"]); v != "" {
+		return "LuxOS " + v
+	}
+	return "LuxOS"
+}
+
+func (t Telemetry) Worker() string {
+	return cgminer.Snapshot{Pools: t.Pools}.Worker()
+}
+
+func (t Telemetry) UptimeS() uint64 {
+	if up := t.Summary.Elapsed.Int(); up > 0 {
+		return uint64(up)
+	}
+	return 0
+}
+
+func (t Telemetry) FansRPM() []int       { return cgminer.Fans(t.Stats) }
+func (t Telemetry) TempsC() []float64    { return cgminer.Temps(t.Stats) }
+func (t Telemetry) HashrateTHS() float64 { return t.Summary.HashrateTHS() }
+
+// WorkMode is the active profile, marked when ATM may move it.
+func (t Telemetry) WorkMode() string {
+	if t.Profile == "" {
+		return ""
+	}
+	if t.ATM != nil && bool(t.ATM.Enabled) {
+		return t.Profile + " (atm)"
+	}
+	return t.Profile
+}
@@ -0,0 +1,196 @@
+// Package minerlog classifies miner logs (kernel, cgminer/bmminer, btminer, Vnish) against
+// known failure signatures, so an underperforming miner shows "chain 2 missing" instead of
+// needing a shell on the box.
+package minerlog
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Finding codes.
+const (
+	CodeMissingChain   = "missing_chain"
+	CodePICReadFail    = "pic_read_fail"
+	CodeFanLost        = "fan_lost"
+	CodeTempHigh       = "temp_high"
+	CodeEEPROMError    = "eeprom_error"
+	CodePoolDisconnect = "pool_disconnect"
+)
+
+// Rule is one failure signature. A line matching any of Patterns counts once.
+type Rule struct {
+	Code     string
+	Severity string // info / warn / crit (as in alert.raised)
+	Title    string
+	Patterns []*regexp.Regexp
+}
+
+// Finding is one rule that matched, with the last matching line as a sample.
+type Finding struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+	Count    int    `json:"count"`
+	Chains   []int  `json:"chains,omitempty"` // chain/slot indexes named in the matching lines
+	LastLine int    `json:"last_line"`        // 1-based line number of Sample
+	Sample   string `json:"sample"`
+}
+
+func res(ps ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(ps))
+	for i, p := range ps {
+		out[i] = regexp.MustCompile(`(?i)` + p)
+	}
+	return out
+}
+
+// Rules are the built-in signatures, collected from stock Bitmain, Vnish and Whatsminer logs.
+var Rules = []Rule{
+	{
+		Code: CodeMissingChain, Severity: "crit", Title: "Hashboard missing or without ASICs",
+		Patterns: res(
+			`chain\s*\[?\s*\d+\s*\]?\s*:?\s*(?:find|found|has|get)\s+0\s+asic`,
+			`chain\s*\[?\s*\d+\s*\]?\s*asic\s*num(?:ber)?\s*(?:is|=|:)?\s*0\b`,
+			`chain\s*\[?\s*\d+\s*\]?\s*(?:not found|not detected|is missing|lost)`,
+			`not enough chain|no chain (?:found|detected)|(?:find|found) 0 chain|chain num(?:ber)? is 0`,
+			`slot\s*\d+\s*:?\s*(?:chip num(?:ber)?\s*(?:is|=|:)?\s*0\b|not (?:found|detected))`,
+			`hash\s*board.*(?:not found|not detected|missing|lost)`,
+		),
+	},
+	{
+		Code: CodePICReadFail, Severity: "crit", Title: "PIC read failure",
+		Patterns: res(
+			`\b(?:ds)?pic\b.*(?:fail|error|timeout)`,
+			`(?:read|get|check)\w*\s+(?:the\s+)?(?:ds)?pic\w*.*(?:fail|error)`,
+		),
+	},
+	{
+		Code: CodeFanLost, Severity: "crit", Title: "Fan lost or stalled",
+		Patterns: res(
+			`fan\w*\s*\d*\s*(?:is\s+)?(?:lost|fail\w*|err\w*|stop\w*|abnormal|not detected)`,
+			`(?:lost|no|missing)\s+fan`,
+			`fan\s*\d*\s*speed\s*(?:is\s*)?(?:too low|0\b|error|abnormal)`,
+		),
+	},
+	{
+		Code: CodeTempHigh, Severity: "crit", Title: "Temperature too high",
+		Patterns: res(
+			`(?:temp(?:erature)?|tmp)\w*.*(?:too high|over\s*limit|exceed|protect)`,
+			`over[\s_-]*temp(?:erature)?\b|overheat`,
+			`reach(?:ed)?\s+(?:the\s+)?max(?:imum)?\s+temp`,
+		),
+	},
+	{
+		Code: CodeEEPROMError, Severity: "warn", Title: "EEPROM error",
+		Patterns: res(
+			`eeprom.*(?:error|fail|crc|invalid|bad|wrong|mismatch)`,
+			`bad chain id|(?:read|check)\w*\s+eeprom.*fail`,
+		),
+	},
+	{
+		Code: CodePoolDisconnect, Severity: "warn", Title: "Pool or network disconnect",
+		Patterns: res(
+			`pool\s*\d*.*(?:not responding|disconnect|is dead|is down|dead|failed)`,
+			`stratum.*(?:fail|error|disconnect|timeout|timed out|closed|lost)`,
+			`(?:lost|no) (?:network|connection)|network (?:is )?(?:down|unreachable)`,
+			`no route to host|connection (?:refused|timed out|reset)`,
+			`(?:dns|resolve|getaddrinfo).*fail|failed to resolve|link is down`,
+		),
+	},
+}
+
+// chainRe picks the chain/slot index out of a matching line.
+var chainRe = regexp.MustCompile(`(?i)(?:chain|slot|board)\s*\[?\s*#?\s*(\d+)`)
+
+// maxSample keeps samples readable in the UI (some logs dump hex on one line).
+const maxSample = 300
+
+// Classify runs Rules over text and returns one finding per matching rule,
+// crit first, then by count.
+func Classify(text string) []Finding {
+	return ClassifyWith(Rules, text)
+}
+
+// ClassifyWith is Classify with a custom rule set.
+func ClassifyWith(rules []Rule, text string) []Finding {
+	byCode := map[string]*Finding{}
+	chains := map[string]map[int]struct{}{}
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "==> ") {
+			continue
+		}
+		for _, r := range rules {
+			if !anyMatch(r.Patterns, line) {
+				continue
+			}
+			f := byCode[r.Code]
+			if f == nil {
+				f = &Finding{Code: r.Code, Severity: r.Severity, Title: r.Title}
+				byCode[r.Code] = f
+				chains[r.Code] = map[int]struct{}{}
+			}
+			f.Count++
+			f.LastLine = n + 1
+			f.Sample = line
+			if len(f.Sample) > maxSample {
+				f.Sample = f.Sample[:maxSample] + "…"
+			}
+			if m := chainRe.FindStringSubmatch(line); len(m) == 2 {
+				if i, err := strconv.Atoi(m[1]); err == nil {
+					chains[r.Code][i] = struct{}{}
+				}
+			}
+		}
+	}
+	out := make([]Finding, 0, len(byCode))
+	for code, f := range byCode {
+		for i := range chains[code] {
+			f.Chains = append(f.Chains, i)
+		}
+		sort.Ints(f.Chains)
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ri, rj := rank(out[i].Severity), rank(out[j].Severity); ri != rj {
+			return ri > rj
+		}
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// Match returns the first rule that matches a single line (e.g. one syslog message).
+func Match(line string) (Rule, bool) {
+	for _, r := range Rules {
+		if anyMatch(r.Patterns, line) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+func anyMatch(ps []*regexp.Regexp, s string) bool {
+	for _, p := range ps {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func rank(sev string) int {
+	switch sev {
+	case "crit":
+		return 2
+	case "warn":
+		return 1
+	}
+	return 0
+}
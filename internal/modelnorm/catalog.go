@@ -0,0 +1,104 @@
+package modelnorm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Spec is the stock (factory clock) rating of a model.
+type Spec struct {
+	NominalTHS float64 `json:"nominal_ths"`
+	PowerW     float64 `json:"power_w"`
+}
+
+// catalog lists SHA-256 models by display name (as produced by Normalize). Figures are the
+// vendor's stock ratings for the most common bin; good enough for estimates, not billing.
+var catalog = map[string]Spec{
+	"Antminer S9":           {13.5, 1323},
+	"Antminer S9i":          {14, 1320},
+	"Antminer S9j":          {14.5, 1350},
+	"Antminer T9+":          {10.5, 1432},
+	"Antminer S17":          {56, 2520},
+	"Antminer S17 Pro":      {53, 2094},
+	"Antminer S17+":         {73, 2920},
+	"Antminer T17":          {40, 2200},
+	"Antminer T17+":         {64, 3200},
+	"Antminer S19":          {95, 3250},
+	"Antminer S19 Pro":      {110, 3250},
+	"Antminer S19j":         {90, 3100},
+	"Antminer S19j Pro":     {104, 3068},
+	"Antminer S19j Pro+":    {122, 3355},
+	"Antminer S19k Pro":     {120, 2760},
+	"Antminer S19 XP":       {140, 3010},
+	"Antminer S19a":         {96, 3312},
+	"Antminer S19a Pro":     {110, 3250},
+	"Antminer S19 Hydro":    {158, 5451},
+	"Antminer S19 Pro+ Hyd": {198, 5445},
+	"Antminer T19":          {84, 3150},
+	"Antminer S21":          {200, 3500},
+	"Antminer S21 Pro":      {234, 3510},
+	"Antminer S21 XP":       {270, 3645},
+	"Antminer S21 Hydro":    {335, 5360},
+	"Antminer T21":          {190, 3610},
+	"Whatsminer M30S":       {88, 3344},
+	"Whatsminer M30S+":      {100, 3400},
+	"Whatsminer M30S++":     {112, 3472},
+	"Whatsminer M31S":       {70, 3220},
+	"Whatsminer M31S+":      {80, 3360},
+	"Whatsminer M50":        {114, 3306},
+	"Whatsminer M50S":       {126, 3276},
+	"Whatsminer M60":        {172, 3422},
+	"Whatsminer M60S":       {186, 3441},
+	"Avalon A1246":          {90, 3420},
+	"Avalon A1346":          {110, 3300},
+	"Avalon A1366":          {130, 3250},
+}
+
+var byKey = func() map[string]Spec {
+	m := make(map[string]Spec, len(catalog))
+	for name, s := range catalog {
+		m[Normalize(name).Key] = s
+	}
+	return m
+}()
+
+// "S19j Pro 104T", "S19 (95Th)": a trailing hashrate bin.
+var binRe = regexp.MustCompile(`(?i)\s*\(?(\d+(?:\.\d+)?)\s*T(?:H)?(?:/S)?\)?$`)
+
+// Lookup returns the catalog spec of a raw or normalized model. A trailing hashrate bin
+// ("104T") is stripped for the lookup and overrides the nominal hashrate.
+func Lookup(model string) (Spec, bool) {
+	key := Normalize(model).Key
+	if s, ok := byKey[key]; ok {
+		return s, true
+	}
+	m := binRe.FindStringSubmatch(key)
+	if m == nil {
+		return Spec{}, false
+	}
+	s, ok := byKey[strings.TrimSpace(key[:len(key)-len(m[0])])]
+	if !ok {
+		return Spec{}, false
+	}
+	if ths, err := strconv.ParseFloat(m[1], 64); err == nil && ths > 0 {
+		s.PowerW *= ths / s.NominalTHS // same J/TH across bins
+		s.NominalTHS = ths
+	}
+	return s, true
+}
+
+// EstimatePowerW estimates wall power for a model at stock clocks. Draw is scaled down with
+// the hashrate (a dead board stops drawing) but never above the rating; a miner not hashing
+// gets no estimate.
+func EstimatePowerW(model string, hashrateTHS float64) (float64, bool) {
+	s, ok := Lookup(model)
+	if !ok || hashrateTHS <= 0 || s.NominalTHS <= 0 {
+		return 0, false
+	}
+	ratio := hashrateTHS / s.NominalTHS
+	if ratio > 1 {
+		ratio = 1
+	}
+	return s.PowerW * ratio, true
+}
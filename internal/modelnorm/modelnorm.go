@@ -6,7 +6,7 @@ import (
 )
 
 type Normalized struct {
-	Vendor string // antminer/whatsminer/avalonminer/iceriver/elphapex/unknown
+	Vendor string // antminer/whatsminer/avalonminer/iceriver/elphapex/goldshell/unknown
 	Model  string // display
 	Key    string // stable key for filtering/grouping
 }
@@ -34,12 +34,33 @@ func Normalize(raw string) Normalized {
 		up = strings.TrimSpace(strings.TrimPrefix(up, "ANTMINER "))
 	}
 
+	sawGoldshell, sawElphapex := false, false
+	switch {
+	case strings.HasPrefix(up, "GOLDSHELL "):
+		sawGoldshell = true
+		up = strings.TrimSpace(strings.TrimPrefix(up, "GOLDSHELL "))
+	case strings.HasPrefix(up, "GS-"):
+		sawGoldshell = true
+		up = strings.TrimSpace(strings.TrimPrefix(up, "GS-"))
+	case strings.HasPrefix(up, "ELPHAPEX "):
+		sawElphapex = true
+		up = strings.TrimSpace(strings.TrimPrefix(up, "ELPHAPEX "))
+	}
+
 	n := Normalized{Vendor: "unknown"}
 
 	// vendor inference
 	switch {
 	case sawAntminer:
 		n.Vendor = "antminer"
+	case sawGoldshell:
+		n.Vendor = "goldshell"
+	case sawElphapex || strings.HasPrefix(up, "DG1") || strings.HasPrefix(up, "DG HOME"):
+		n.Vendor = "elphapex"
+	case strings.HasPrefix(up, "KD") || strings.HasPrefix(up, "CK") || strings.HasPrefix(up, "LT") ||
+		strings.HasPrefix(up, "HS") || strings.HasPrefix(up, "AL-BOX") || strings.HasPrefix(up, "AL BOX"):
+		// Goldshell families (checked before IceRiver AL* and Bitmain L*)
+		n.Vendor = "goldshell"
 	case strings.HasPrefix(up, "M") && len(up) >= 3 && up[1] >= '0' && up[1] <= '9':
 		n.Vendor = "whatsminer"
 	case strings.HasPrefix(up, "A") && len(up) >= 3 && up[1] >= '0' && up[1] <= '9':
@@ -89,6 +110,12 @@ func Normalize(raw string) Normalized {
 	if n.Vendor == "iceriver" {
 		model = "IceRiver " + strings.TrimSpace(up)
 	}
+	if n.Vendor == "goldshell" {
+		model = "Goldshell " + strings.TrimSpace(up)
+	}
+	if n.Vendor == "elphapex" {
+		model = "Elphapex " + strings.TrimSpace(up)
+	}
 
 	n.Model = model
 	n.Key = strings.ToUpper(ws.ReplaceAllString(strings.ReplaceAll(model, "_", " "), " "))
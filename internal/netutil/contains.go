@@ -0,0 +1,38 @@
+package netutil
+
+import (
+	"net"
+	"strings"
+)
+
+// SpecContains reports whether ip is inside spec (CIDR, A-B range, or a comma/newline separated mix).
+func SpecContains(spec, ip string) bool {
+	addr := net.ParseIP(strings.TrimSpace(ip)).To4()
+	if addr == nil {
+		return false
+	}
+	for _, p := range splitSpec(spec) {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if strings.Contains(p, "/") {
+			if _, n, err := net.ParseCIDR(p); err == nil && n.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if strings.Contains(p, "-") {
+			r, err := parseRange(p)
+			// bytesLE is strict (a < b): inside means !(addr < first) && !(last < addr)
+			if err == nil && !bytesLE(addr, r.first) && !bytesLE(r.last, addr) {
+				return true
+			}
+			continue
+		}
+		if one := net.ParseIP(p).To4(); one != nil && one.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
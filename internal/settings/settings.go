@@ -22,6 +22,38 @@ type EmbeddedNATS struct {
 	StoreDir string `json:"store_dir"`
 }
 
+// Polling controls periodic telemetry collection.
+type Polling struct {
+	// Remote publishes poll.request for cmd/collector workers instead of polling in core.
+	// Falls back to in-process polling while NATS is down.
+	Remote bool `json:"remote"`
+
+	// Interval is the base poll period for online ASICs; polls are spread evenly over it.
+	Interval time.Duration `json:"interval"`
+	// AlertInterval is used for devices in an alert state (overheating, zero hashrate).
+	AlertInterval time.Duration `json:"alert_interval"`
+	// MaxBackoff caps the exponential backoff for unreachable devices.
+	MaxBackoff time.Duration `json:"max_backoff"`
+}
+
+// Syslog is the optional listener miners send their logs to (RFC 3164/5424).
+type Syslog struct {
+	Enabled bool   `json:"enabled"`
+	UDPAddr string `json:"udp_addr"` // e.g. ":514"; empty disables UDP
+	TCPAddr string `json:"tcp_addr"` // empty disables TCP
+	// MaxLines is the per-device buffer (0 = syslogd.DefaultMaxLines).
+	MaxLines int `json:"max_lines"`
+}
+
+// StateEvents sets what counts as a material change for device.state_updated (zero = default).
+type StateEvents struct {
+	HashratePct float64 `json:"hashrate_pct"` // relative hashrate change, % (default 10)
+	TempC       float64 `json:"temp_c"`       // hottest board change, °C (default 3)
+	FanRPM      int     `json:"fan_rpm"`      // slowest/fastest fan change, RPM (default 500)
+	// Heartbeat republishes unchanged devices this often (default 5m, negative = never).
+	Heartbeat time.Duration `json:"heartbeat"`
+}
+
 type Settings struct {
 	Version int `json:"version"`
 
@@ -38,8 +70,127 @@ type Settings struct {
 	// Scanner probes
 	TryDefaultCreds bool `json:"try_default_creds"`
 
+	Polling Polling `json:"polling"`
+
+	Syslog Syslog `json:"syslog"`
+
+	StateEvents StateEvents `json:"state_events"`
+
 	// Encrypted credentials (stored in settings.json, secrets encrypted with data/secret.key)
 	Credentials []Credential `json:"credentials,omitempty"`
+
+	// Pool allow-lists (edited through /api/pool-policies, not the settings form)
+	PoolPolicies []PoolPolicy `json:"pool_policies,omitempty"`
+
+	// Automation rules (edited through /api/rules, evaluated by cmd/automation)
+	Rules []Rule `json:"rules,omitempty"`
+
+	Remediation Remediation `json:"remediation"`
+}
+
+// Remediation guards the reboots automation rules request (command "reboot"); zero = default.
+type Remediation struct {
+	// MaxRebootsPerHour per device, any cause (reboot_count_1h); past it the rule escalates
+	// to a remediation_exhausted alert instead (default 2).
+	MaxRebootsPerHour int `json:"max_reboots_per_hour"`
+	// MaxConcurrent reboots in progress per address pool, to protect power circuits (default 3).
+	MaxConcurrent int `json:"max_concurrent"`
+	// QuietHours "HH:MM-HH:MM" (automation host local time, may wrap midnight): reboots wait.
+	QuietHours string `json:"quiet_hours,omitempty"`
+}
+
+// Rule raises an alert (and optionally requests a command) when a device matches a
+// condition. When is parsed by internal/automation: "temp_max_c > 85",
+// "hashrate_ths < 0.8 * nominal for 15m", "offline for 10m".
+type Rule struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	When    string `json:"when"`
+
+	// Scope; each empty = any device.
+	Subnets []string `json:"subnets,omitempty"` // address pool specs (CIDR/range)
+	Vendors []string `json:"vendors,omitempty"`
+	Models  []string `json:"models,omitempty"` // case-insensitive globs ("*S19*")
+
+	Severity string `json:"severity,omitempty"` // info/warn/crit (default warn)
+	Code     string `json:"code,omitempty"`     // alert code (default rule_<id>)
+
+	// Command is requested once each time the rule fires (e.g. "reboot"); empty = alert only.
+	Command     string            `json:"command,omitempty"`
+	CommandArgs map[string]string `json:"command_args,omitempty"`
+}
+
+// PoolPolicy is an allow-list of mining pools for the devices of some address pools.
+// Patterns are case-insensitive globs ("*" matches anything); URL patterns ignore the
+// stratum scheme, so "*.f2pool.com:*" matches "stratum+tcp://btc.f2pool.com:1314".
+type PoolPolicy struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Enabled bool     `json:"enabled"`
+	Subnets []string `json:"subnets,omitempty"` // address pool specs (CIDR/range); empty = every device
+	URLs    []string `json:"urls"`              // allowed pool URL patterns
+	Users   []string `json:"users,omitempty"`   // allowed user/worker patterns; empty = any
+
+	// AutoRestore pushes Restore (user templates as in /api/pools/apply) to a deviating device.
+	AutoRestore bool       `json:"auto_restore,omitempty"`
+	Restore     []PoolSlot `json:"restore,omitempty"`
+}
+
+// PoolSlot is one approved pool slot (same shape as commands.Pool).
+type PoolSlot struct {
+	URL  string `json:"url"`
+	User string `json:"user"`
+	Pass string `json:"pass"`
+}
+
+// RedactedPass stands in for a stored restore pool password in API replies. A PUT that sends
+// it back keeps the stored value (see KeepRedactedPasses).
+const RedactedPass = "********"
+
+// Redacted returns a copy of s for API replies, restore pool passwords masked.
+func (s Settings) Redacted() Settings {
+	s.PoolPolicies = RedactPoolPolicies(s.PoolPolicies)
+	return s
+}
+
+// RedactPoolPolicies returns a copy of ps with every non-empty restore password masked.
+func RedactPoolPolicies(ps []PoolPolicy) []PoolPolicy {
+	if ps == nil {
+		return nil
+	}
+	out := make([]PoolPolicy, len(ps))
+	for i, p := range ps {
+		p.Restore = append([]PoolSlot(nil), p.Restore...)
+		for j := range p.Restore {
+			if p.Restore[j].Pass != "" {
+				p.Restore[j].Pass = RedactedPass
+			}
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// KeepRedactedPasses puts the stored password back into every restore slot of ps that still
+// carries RedactedPass. Policies match by ID and slots by position; unmatched slots get "".
+func KeepRedactedPasses(ps, prev []PoolPolicy) {
+	byID := make(map[string]PoolPolicy, len(prev))
+	for _, p := range prev {
+		byID[p.ID] = p
+	}
+	for i := range ps {
+		old := byID[ps[i].ID]
+		for j := range ps[i].Restore {
+			if ps[i].Restore[j].Pass != RedactedPass {
+				continue
+			}
+			ps[i].Restore[j].Pass = ""
+			if j < len(old.Restore) {
+				ps[i].Restore[j].Pass = old.Restore[j].Pass
+			}
+		}
+	}
 }
 
 type Credential struct {
@@ -77,6 +228,25 @@ func Defaults() Settings {
 			DialTimeout: 1200 * time.Millisecond,
 			HTTPTimeout: 1200 * time.Millisecond,
 		},
+		Polling: Polling{
+			Interval:      60 * time.Second,
+			AlertInterval: 15 * time.Second,
+			MaxBackoff:    15 * time.Minute,
+		},
+		StateEvents: StateEvents{
+			HashratePct: 10,
+			TempC:       3,
+			FanRPM:      500,
+			Heartbeat:   5 * time.Minute,
+		},
+		Syslog: Syslog{
+			UDPAddr:  ":514",
+			MaxLines: 1000,
+		},
+		Remediation: Remediation{
+			MaxRebootsPerHour: 2,
+			MaxConcurrent:     3,
+		},
 		Subnets: nil,
 
 		TryDefaultCreds: false,
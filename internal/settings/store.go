@@ -51,6 +51,26 @@ func (s *Store) Patch(fn func(*Settings)) error {
 	return s.save()
 }
 
+// Reload re-reads settings.json without rewriting it. Secondary processes sharing the data
+// directory with core (cmd/collector) use it to pick up credential changes.
+func (s *Store) Reload() error {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var cfg Settings
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return err
+	}
+	if cfg.Version == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	s.cur = cfg
+	s.mu.Unlock()
+	return nil
+}
+
 func (s *Store) load() error {
 	b, err := os.ReadFile(s.path)
 	if err != nil {
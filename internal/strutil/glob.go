@@ -0,0 +1,26 @@
+// Package strutil holds small string helpers shared across packages.
+package strutil
+
+import "strings"
+
+// MatchGlob reports whether s matches the case-insensitive glob pattern ("*" = any run).
+func MatchGlob(pattern, s string) bool {
+	pattern, s = strings.ToLower(strings.TrimSpace(pattern)), strings.ToLower(strings.TrimSpace(s))
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+	return strings.HasSuffix(s, last)
+}
@@ -0,0 +1,133 @@
+package syslogd
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxLines is the per-device buffer size when none is configured.
+const DefaultMaxLines = 1000
+
+// Buffer keeps the last lines of every device in a ring; memory is bounded by the number of
+// devices the caller admits (core only stores senders that are in the registry).
+type Buffer struct {
+	mu    sync.RWMutex
+	max   int
+	byIP  map[string]*ring
+	total uint64
+}
+
+type ring struct {
+	lines []Message
+	head  int // oldest line once the ring is full
+}
+
+func NewBuffer(maxLines int) *Buffer {
+	if maxLines <= 0 {
+		maxLines = DefaultMaxLines
+	}
+	return &Buffer{max: maxLines, byIP: map[string]*ring{}}
+}
+
+// Add appends m to the ring of m.IP, dropping the oldest line when full.
+func (b *Buffer) Add(m Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total++
+	r := b.byIP[m.IP]
+	if r == nil {
+		r = &ring{}
+		b.byIP[m.IP] = r
+	}
+	if len(r.lines) < b.max {
+		r.lines = append(r.lines, m)
+		return
+	}
+	r.lines[r.head] = m
+	r.head = (r.head + 1) % len(r.lines)
+}
+
+// SetMax resizes every ring, keeping the newest lines.
+func (b *Buffer) SetMax(n int) {
+	if n <= 0 {
+		n = DefaultMaxLines
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n == b.max {
+		return
+	}
+	b.max = n
+	for _, r := range b.byIP {
+		ls := r.ordered()
+		if len(ls) > n {
+			ls = ls[len(ls)-n:]
+		}
+		r.lines, r.head = append([]Message(nil), ls...), 0
+	}
+}
+
+// ordered returns the lines oldest first.
+func (r *ring) ordered() []Message {
+	out := make([]Message, 0, len(r.lines))
+	out = append(out, r.lines[r.head:]...)
+	return append(out, r.lines[:r.head]...)
+}
+
+// Query narrows a device's lines. Text terms must all occur (case-insensitive) in the
+// app or text; MaxSeverity < 0 disables the severity filter.
+type Query struct {
+	Text        string
+	MaxSeverity int
+	Since       time.Time
+	Limit       int
+}
+
+// Search returns the matching lines of ip, newest first, and how many lines are buffered.
+func (b *Buffer) Search(ip string, q Query) ([]Message, int) {
+	b.mu.RLock()
+	r := b.byIP[ip]
+	var ls []Message
+	if r != nil {
+		ls = r.ordered()
+	}
+	b.mu.RUnlock()
+
+	terms := strings.Fields(strings.ToLower(q.Text))
+	out := []Message{}
+	for i := len(ls) - 1; i >= 0; i-- {
+		m := ls[i]
+		if q.MaxSeverity >= 0 && m.Severity > q.MaxSeverity {
+			continue
+		}
+		if !q.Since.IsZero() && m.Received.Before(q.Since) {
+			continue
+		}
+		if len(terms) > 0 {
+			hay := strings.ToLower(m.App + " " + m.Text)
+			ok := true
+			for _, t := range terms {
+				if !strings.Contains(hay, t) {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+		}
+		out = append(out, m)
+		if q.Limit > 0 && len(out) >= q.Limit {
+			break
+		}
+	}
+	return out, len(ls)
+}
+
+// Stats reports buffered devices and lines received since start.
+func (b *Buffer) Stats() (devices int, received uint64) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.byIP), b.total
+}
@@ -0,0 +1,191 @@
+// Package syslogd receives syslog from miners (RFC 3164 and RFC 5424 over UDP or TCP) and
+// keeps a bounded per-device buffer of the lines for search.
+package syslogd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is one received syslog line.
+type Message struct {
+	Received time.Time `json:"received"`
+	Time     time.Time `json:"time,omitempty"` // sender's timestamp when it parsed
+	IP       string    `json:"ip"`             // sender address (the device)
+	Facility int       `json:"facility"`
+	Severity int       `json:"severity"` // 0 emerg .. 7 debug
+	Host     string    `json:"host,omitempty"`
+	App      string    `json:"app,omitempty"`
+	Text     string    `json:"text"`
+}
+
+var severityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// SeverityName returns the keyword of a syslog severity ("err", "warning"...).
+func SeverityName(sev int) string {
+	if sev < 0 || sev >= len(severityNames) {
+		return strconv.Itoa(sev)
+	}
+	return severityNames[sev]
+}
+
+// ParseSeverity accepts a keyword (also "error", "warn") or a number 0..7.
+func ParseSeverity(s string) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "error":
+		return 3, true
+	case "warn":
+		return 4, true
+	}
+	for i, n := range severityNames {
+		if s == n {
+			return i, true
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(severityNames) {
+		return n, true
+	}
+	return 0, false
+}
+
+// Parse decodes one syslog message. RFC 5424 is recognized by its version field; anything
+// else is read as RFC 3164, including the busybox variant without a hostname that most
+// miner control boards send. Unparsable input is kept whole as Text (user.notice).
+func Parse(b []byte, ip string, now time.Time) Message {
+	s := strings.TrimRight(string(b), "\r\n\x00")
+	m := Message{Received: now, IP: ip, Facility: 1, Severity: 5}
+	if pri, rest, ok := cutPRI(s); ok {
+		m.Facility, m.Severity = pri/8, pri%8
+		s = rest
+	}
+	if strings.HasPrefix(s, "1 ") {
+		parse5424(&m, s[2:])
+	} else {
+		parse3164(&m, s, now)
+	}
+	m.Text = strings.TrimSpace(m.Text)
+	return m
+}
+
+func cutPRI(s string) (int, string, bool) {
+	if !strings.HasPrefix(s, "<") {
+		return 0, s, false
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return 0, s, false
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, s, false
+	}
+	return pri, s[end+1:], true
+}
+
+// parse5424: TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]; "-" is nil.
+func parse5424(m *Message, s string) {
+	fields := make([]string, 0, 5)
+	for len(fields) < 5 {
+		i := strings.IndexByte(s, ' ')
+		if i < 0 {
+			fields = append(fields, s)
+			s = ""
+			break
+		}
+		fields = append(fields, s[:i])
+		s = s[i+1:]
+	}
+	nilv := func(i int) string {
+		if i >= len(fields) || fields[i] == "-" {
+			return ""
+		}
+		return fields[i]
+	}
+	if ts := nilv(0); ts != "" {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			m.Time = t
+		}
+	}
+	m.Host = nilv(1)
+	m.App = nilv(2)
+	m.Text = strings.TrimPrefix(skipSD(s), "\xef\xbb\xbf")
+}
+
+// skipSD drops the structured data ("-" or one or more [id k="v"] elements).
+func skipSD(s string) string {
+	if strings.HasPrefix(s, "-") {
+		return strings.TrimPrefix(s[1:], " ")
+	}
+	for strings.HasPrefix(s, "[") {
+		end := sdEnd(s)
+		if end < 0 {
+			return ""
+		}
+		s = s[end+1:]
+	}
+	return strings.TrimPrefix(s, " ")
+}
+
+// sdEnd returns the index of the "]" closing the element s starts with (quoted values may
+// contain escaped quotes and brackets), or -1.
+func sdEnd(s string) int {
+	inQuote, esc := false, false
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case esc:
+			esc = false
+		case c == '\\':
+			esc = true
+		case c == '"':
+			inQuote = !inQuote
+		case c == ']' && !inQuote:
+			return i
+		}
+	}
+	return -1
+}
+
+// parse3164: "Mmm dd hh:mm:ss [HOST ]TAG[pid]: MSG". The header is optional.
+func parse3164(m *Message, s string, now time.Time) {
+	if len(s) >= 16 && s[15] == ' ' {
+		if t, err := time.ParseInLocation(time.Stamp, s[:15], now.Location()); err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+			// a December line received in January belongs to last year
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0)
+			}
+			m.Time = t
+			s = s[16:]
+			if host, rest, ok := strings.Cut(s, " "); ok && !isTag(host) {
+				m.Host = host
+				s = rest
+			}
+		}
+	}
+	if tag, rest, ok := strings.Cut(s, " "); ok && isTag(tag) {
+		m.App = strings.TrimSuffix(tag, ":")
+		if i := strings.IndexByte(m.App, '['); i > 0 {
+			m.App = m.App[:i]
+		}
+		s = rest
+	}
+	m.Text = s
+}
+
+// isTag reports an RFC 3164 TAG token: "name:" or "name[pid]:".
+func isTag(tok string) bool {
+	if !strings.HasSuffix(tok, ":") || len(tok) < 2 {
+		return false
+	}
+	for _, c := range strings.TrimSuffix(tok, ":") {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-_./[]", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
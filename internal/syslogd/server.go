@@ -0,0 +1,201 @@
+package syslogd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxMessage  = 16 * 1024
+	maxTCPConns = 512
+	tcpIdle     = 10 * time.Minute
+)
+
+// Config selects the listeners; an empty address disables that transport.
+type Config struct {
+	UDPAddr string
+	TCPAddr string
+}
+
+// Server is a running syslog listener. Handler is called from the receiving goroutines.
+type Server struct {
+	handler func(Message)
+
+	udp net.PacketConn
+	tcp net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// Listen binds the configured transports and starts receiving.
+func Listen(cfg Config, handler func(Message)) (*Server, error) {
+	if strings.TrimSpace(cfg.UDPAddr) == "" && strings.TrimSpace(cfg.TCPAddr) == "" {
+		return nil, errors.New("syslog: no listen address")
+	}
+	s := &Server{handler: handler, conns: map[net.Conn]struct{}{}}
+	if cfg.UDPAddr != "" {
+		pc, err := net.ListenPacket("udp", cfg.UDPAddr)
+		if err != nil {
+			return nil, err
+		}
+		s.udp = pc
+	}
+	if cfg.TCPAddr != "" {
+		ln, err := net.Listen("tcp", cfg.TCPAddr)
+		if err != nil {
+			if s.udp != nil {
+				_ = s.udp.Close()
+			}
+			return nil, err
+		}
+		s.tcp = ln
+	}
+	if s.udp != nil {
+		s.wg.Add(1)
+		go s.serveUDP()
+	}
+	if s.tcp != nil {
+		s.wg.Add(1)
+		go s.serveTCP()
+	}
+	return s, nil
+}
+
+// Addrs returns the bound addresses (useful with ":0").
+func (s *Server) Addrs() (udp, tcp string) {
+	if s.udp != nil {
+		udp = s.udp.LocalAddr().String()
+	}
+	if s.tcp != nil {
+		tcp = s.tcp.Addr().String()
+	}
+	return udp, tcp
+}
+
+// Close stops the listeners, drops open TCP senders and waits for the receivers.
+func (s *Server) Close() {
+	if s.udp != nil {
+		_ = s.udp.Close()
+	}
+	if s.tcp != nil {
+		_ = s.tcp.Close()
+	}
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, 64*1024)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		s.handler(Parse(buf[:min(n, maxMessage)], hostOf(addr), time.Now().UTC()))
+	}
+}
+
+func (s *Server) serveTCP() {
+	defer s.wg.Done()
+	sem := make(chan struct{}, maxTCPConns)
+	for {
+		c, err := s.tcp.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		default:
+			_ = c.Close()
+			continue
+		}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, c)
+				s.mu.Unlock()
+				_ = c.Close()
+				<-sem
+				s.wg.Done()
+			}()
+			s.readTCP(c)
+		}()
+	}
+}
+
+// readTCP handles both RFC 6587 framings: octet counting ("<len> <msg>") and
+// newline-delimited. The framing is decided per message by its first byte.
+func (s *Server) readTCP(c net.Conn) {
+	ip := hostOf(c.RemoteAddr())
+	rd := bufio.NewReaderSize(c, maxMessage)
+	for {
+		_ = c.SetReadDeadline(time.Now().Add(tcpIdle))
+		first, err := rd.Peek(1)
+		if err != nil {
+			return
+		}
+		var msg []byte
+		if first[0] >= '1' && first[0] <= '9' {
+			head, err := rd.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(head))
+			if err != nil || n <= 0 || n > maxMessage {
+				return
+			}
+			msg = make([]byte, n)
+			if _, err := io.ReadFull(rd, msg); err != nil {
+				return
+			}
+		} else {
+			line, err := rd.ReadSlice('\n')
+			if err != nil && !(errors.Is(err, bufio.ErrBufferFull) || (errors.Is(err, io.EOF) && len(line) > 0)) {
+				return
+			}
+			msg = append([]byte(nil), line...)
+			if errors.Is(err, bufio.ErrBufferFull) {
+				// overlong line: keep the head, drop the rest
+				for errors.Is(err, bufio.ErrBufferFull) {
+					_, err = rd.ReadSlice('\n')
+				}
+			}
+		}
+		if len(strings.TrimSpace(string(msg))) == 0 {
+			continue
+		}
+		s.handler(Parse(msg, ip, time.Now().UTC()))
+	}
+}
+
+func hostOf(a net.Addr) string {
+	host, _, err := net.SplitHostPort(a.String())
+	if err != nil {
+		return a.String()
+	}
+	return host
+}
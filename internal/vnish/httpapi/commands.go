@@ -0,0 +1,13 @@
+package httpapi
+
+import "context"
+
+// Reboot reboots the control board (whole miner), not just the mining process.
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	_, res := s.PostFirst(ctx, []string{"/api/v1/system/reboot", "/api/v1/reboot", "/api/reboot", "/api/system/reboot"}, nil)
+	return res
+}
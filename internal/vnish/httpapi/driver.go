@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"context"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver is Vnish/Anthill. Stock-looking Antminers get it as a fallback too: their CGI
+// answering with SPA HTML usually means Vnish was installed after discovery.
+type driver struct{}
+
+func (driver) Name() string { return "vnish" }
+
+func (driver) Detect(t sdk.Target) int {
+	switch v := t.VendorKey(); {
+	case v != "" && v != "antminer":
+		return sdk.ConfidenceNone
+	case sdk.IsVnishFirmware(t.Firmware):
+		return sdk.ConfidenceExact
+	case t.FirmwareHas("braiins"), t.FirmwareHas("luxos"):
+		return sdk.ConfidenceNone
+	}
+	// ahead of the Braiins fallback: an unknown SPA is far more often Vnish
+	return 2 * sdk.ConfidenceFallback
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	res := Probe(ctx, t.IP, creds, t.Schemes())
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error,
+		Responses: res.Responses, Raw: res.Raw, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	res, ok := pr.Reply.(ProbeResult)
+	if !ok {
+		return sdk.Facts{}
+	}
+	f := ExtractFacts(res)
+	return sdk.Facts{
+		Vendor:      "antminer",
+		Model:       f.Model,
+		Firmware:    f.Firmware,
+		Worker:      f.Worker,
+		UptimeS:     f.UptimeS,
+		HashrateTHS: f.HashrateTHS,
+		FansRPM:     f.FansRPM,
+		TempsC:      f.TempsC,
+		PowerW:      f.PowerW,
+		Chains:      f.Chains,
+		Pools:       f.Pools,
+	}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return commandResult(Reboot(ctx, t.IP, creds, t.Schemes()))
+		},
+		SetPools: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, pools []sdk.Pool) sdk.CommandResult {
+			return commandResult(SetPools(ctx, t.IP, creds, t.Schemes(), pools))
+		},
+	}
+}
+
+// ReadLog implements sdk.LogReader.
+func (driver) ReadLog(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.LogResult {
+	r := ReadLogs(ctx, t.IP, creds, t.Schemes())
+	return sdk.LogResult{OK: r.OK, UsedCred: r.UsedCred, Sources: r.Sources, Error: r.Error, Text: r.Text}
+}
+
+func commandResult(r CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, UsedCred: r.UsedCred, Error: r.Error}
+}
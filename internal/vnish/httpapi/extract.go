@@ -2,7 +2,13 @@ package httpapi
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
 )
 
 type Facts struct {
@@ -13,6 +19,9 @@ type Facts struct {
 	HashrateTHS float64
 	FansRPM     []int
 	TempsC      []float64
+	PowerW      float64
+	Chains      []sdk.Chain
+	Pools       []sdk.PoolStatus
 }
 
 // ExtractFacts tries to pull common fields from a variety of Vnish/Anthill-like JSONs.
@@ -92,6 +101,13 @@ func ExtractFacts(res ProbeResult) Facts {
 						f.TempsC = out
 					}
 				}
+				// Power: miner.power_usage (W, wall), older builds power_consumption
+				if f.PowerW == 0 {
+					f.PowerW = firstF64(miner, "power_usage", "power_consumption", "power")
+				}
+				if pools, ok := miner["pools"].([]any); ok {
+					f.Pools = parsePools(pools)
+				}
 				// Worker: pools[0].user (may be masked)
 				if f.Worker == "" {
 					if pools, ok := miner["pools"].([]any); ok {
@@ -110,6 +126,28 @@ func ExtractFacts(res ProbeResult) Facts {
 			}
 		}
 	}
+	// Chains: miner.chains[] in summary (hashrates in GH/s), else any "chains" array.
+	for _, ep := range []string{"/api/v1/summary", "/api/v1/chains", "/api/v1/stats"} {
+		if len(f.Chains) > 0 {
+			break
+		}
+		if arr := findArrayDeep(res.Responses[ep], "chains"); arr != nil {
+			f.Chains = parseChains(arr)
+		}
+	}
+	// no miner-level power: add up the chains (power_consumption per board)
+	if f.PowerW == 0 {
+		for _, ep := range []string{"/api/v1/summary", "/api/v1/chains"} {
+			for _, x := range findArrayDeep(res.Responses[ep], "chains") {
+				if m, ok := x.(map[string]any); ok {
+					f.PowerW += toF64(m["power_consumption"])
+				}
+			}
+			if f.PowerW > 0 {
+				break
+			}
+		}
+	}
 	if v, ok := res.Responses["/api/v1/status"]; ok && f.UptimeS == 0 {
 		if m, ok := v.(map[string]any); ok {
 			// miner_state_time is seconds in your sample.
@@ -119,8 +157,8 @@ func ExtractFacts(res ProbeResult) Facts {
 
 	// scan all json maps for some common keys (fallback)
 	for _, v := range res.Responses {
-		f.Model = firstNonEmpty(f.Model, findStringDeep(v, set("model", "type", "miner_type", "device", "product")))
-		f.Worker = firstNonEmpty(f.Worker, findStringDeep(v, set("worker", "user", "pooluser", "username")))
+		f.Model = sessionutil.FirstNonEmpty(f.Model, findStringDeep(v, set("model", "type", "miner_type", "device", "product")))
+		f.Worker = sessionutil.FirstNonEmpty(f.Worker, findStringDeep(v, set("worker", "user", "pooluser", "username")))
 		if f.UptimeS == 0 {
 			f.UptimeS = findU64Deep(v, set("uptime", "elapsed", "elapsed_s", "uptime_s"))
 		}
@@ -154,11 +192,131 @@ func ExtractFacts(res ProbeResult) Facts {
 	return f
 }
 
-func firstNonEmpty(a, b string) string {
-	if strings.TrimSpace(a) != "" {
-		return a
+func parseChains(arr []any) []sdk.Chain {
+	var out []sdk.Chain
+	for i, x := range arr {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		c := sdk.Chain{
+			Index:       i,
+			HashrateTHS: firstF64(m, "hashrate_rt", "rate_real", "hashrate") / 1000.0,
+			IdealTHS:    firstF64(m, "hashrate_ideal", "rate_ideal") / 1000.0,
+			FreqMHz:     firstF64(m, "frequency", "freq", "freq_avg"),
+			VoltageV:    firstF64(m, "voltage"),
+			HWErrors:    uint64(firstF64(m, "hw_errors", "hw")),
+			Chips:       int(firstF64(m, "chips", "asic_num", "chip_count")),
+		}
+		if _, ok := m["id"]; ok {
+			c.Index = int(toF64(m["id"]))
+		}
+		if c.VoltageV > 100 { // mV
+			c.VoltageV /= 1000
+		}
+		c.ChipTempsC = tempRange(m["chip_temp"])
+		c.PCBTempsC = tempRange(m["pcb_temp"])
+		out = append(out, c)
+	}
+	return out
+}
+
+// parsePools reads miner.pools[]: status is "active" (hashing), "working", "offline" or
+// "disabled"; ls_time is the last share as unix seconds or "h:mm:ss" ago.
+func parsePools(arr []any) []sdk.PoolStatus {
+	var out []sdk.PoolStatus
+	now := time.Now()
+	for i, x := range arr {
+		m, ok := x.(map[string]any)
+		if !ok {
+			continue
+		}
+		url, _ := m["url"].(string)
+		if strings.TrimSpace(url) == "" {
+			continue
+		}
+		st := sdk.PoolStatus{
+			URL:        strings.TrimSpace(url),
+			Priority:   i,
+			Status:     strings.ToLower(strings.TrimSpace(fmt.Sprint(m["status"]))),
+			Accepted:   toU64(m["accepted"]),
+			Rejected:   toU64(m["rejected"]),
+			Stale:      toU64(m["stale"]),
+			Difficulty: cgminer.ParseDiff(fmt.Sprint(m["diff"])),
+		}
+		if u, ok := m["user"].(string); ok && u != "*****" {
+			st.User = strings.TrimSpace(u)
+		}
+		if _, ok := m["id"]; ok {
+			st.Priority = int(toF64(m["id"]))
+		}
+		if st.Status == "<nil>" {
+			st.Status = ""
+		}
+		st.Active = st.Status == "active"
+		switch v := m["ls_time"].(type) {
+		case string:
+			st.LastShare = cgminer.ShareTime(v, now)
+		case float64:
+			st.LastShare = cgminer.ShareTime(strconv.FormatInt(int64(v), 10), now)
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+func firstF64(m map[string]any, keys ...string) float64 {
+	for _, k := range keys {
+		if v := toF64(m[k]); v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// tempRange reads {"min":..,"max":..} objects, bare numbers and arrays.
+func tempRange(v any) []float64 {
+	var out []float64
+	switch x := v.(type) {
+	case map[string]any:
+		for _, k := range []string{"min", "max"} {
+			if t := toF64(x[k]); t > 0 {
+				out = append(out, t)
+			}
+		}
+	case []any:
+		for _, e := range x {
+			if t := toF64(e); t > 0 {
+				out = append(out, t)
+			}
+		}
+	default:
+		if t := toF64(x); t > 0 {
+			out = append(out, t)
+		}
 	}
-	return strings.TrimSpace(b)
+	return out
+}
+
+func findArrayDeep(v any, key string) []any {
+	switch x := v.(type) {
+	case map[string]any:
+		if arr, ok := x[key].([]any); ok && len(arr) > 0 {
+			return arr
+		}
+		for _, vv := range x {
+			if arr := findArrayDeep(vv, key); arr != nil {
+				return arr
+			}
+		}
+	case []any:
+		for _, vv := range x {
+			if arr := findArrayDeep(vv, key); arr != nil {
+				return arr
+			}
+		}
+	}
+	return nil
 }
 
 func set(keys ...string) map[string]struct{} {
@@ -352,4 +510,3 @@ func toF64(v any) float64 {
 		return 0
 	}
 }
-
@@ -12,6 +12,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"asic-control/internal/collectors/sdk"
 )
 
 type ProbeResult struct {
@@ -25,11 +27,8 @@ type ProbeResult struct {
 	Raw       map[string]string `json:"raw,omitempty"`
 }
 
-type Cred struct {
-	Name     string
-	Username string
-	Password string
-}
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
 
 // Probe tries to access Vnish/Anthill-style JSON APIs.
 // Many builds are SPAs; the data is usually exposed via /api/* endpoints with cookie session.
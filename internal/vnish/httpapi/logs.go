@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// LogResult is the text of the Vnish logs, each source introduced by "==> path <==".
+type LogResult struct {
+	OK       bool     `json:"ok"`
+	Scheme   string   `json:"scheme,omitempty"`
+	UsedCred string   `json:"used_cred,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Text     string   `json:"-"`
+}
+
+// logPaths: miner (cgminer + chain init), system (kernel/syslog) and the status log that
+// carries the firmware's own fault messages.
+var logPaths = []string{"/api/v1/logs/miner", "/api/v1/logs/system", "/api/v1/logs/status"}
+
+// ReadLogs fetches the miner, system and status logs (plain text on current builds).
+func ReadLogs(ctx context.Context, host string, creds []Cred, schemes []string) LogResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return LogResult{Error: err.Error()}
+	}
+	out := LogResult{Scheme: s.Scheme, UsedCred: s.UsedCred, Error: "no log endpoint answered"}
+	var sb strings.Builder
+	for _, p := range logPaths {
+		code, b, err := s.Do(ctx, "GET", p, nil)
+		switch {
+		case err != nil:
+			out.Error = err.Error()
+			continue
+		case code < 200 || code > 299:
+			out.Error = "http " + http.StatusText(code)
+			continue
+		}
+		out.Sources = append(out.Sources, p)
+		sb.WriteString("==> " + p + " <==\n")
+		sb.WriteString(strings.TrimRight(string(b), "\n") + "\n")
+	}
+	if len(out.Sources) > 0 {
+		out.OK, out.Error, out.Text = true, "", sb.String()
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package httpapi
+
+import (
+	"context"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+)
+
+// Pool is one pool slot.
+type Pool = sdk.Pool
+
+// SetPools writes the pool list through the Vnish/Anthill settings API and restarts mining
+// so the new pools are picked up. Empty slots are dropped.
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	s, err := LoginAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	list := make([]map[string]any, 0, len(pools))
+	for i, p := range pools {
+		if strings.TrimSpace(p.URL) == "" {
+			continue
+		}
+		list = append(list, map[string]any{"url": p.URL, "user": p.User, "pass": p.Pass, "order": i})
+	}
+	if len(list) == 0 {
+		return CommandResult{OK: false, Scheme: s.Scheme, UsedCred: s.UsedCred, Error: "no pools"}
+	}
+
+	// Vnish 1.2+: settings document; Anthill/older builds: dedicated pools endpoints.
+	_, res := s.PostFirst(ctx, []string{"/api/v1/settings"}, map[string]any{"miner": map[string]any{"pools": list}})
+	if !res.OK {
+		_, res = s.PostFirst(ctx, []string{"/api/v1/pools", "/api/pools", "/api/v1/miner/pools"}, map[string]any{"pools": list})
+	}
+	if !res.OK {
+		return res
+	}
+	// best-effort: some builds apply on save, others need a cgminer restart
+	_, _ = s.PostFirst(ctx, []string{"/api/v1/mining/restart", "/api/v1/miner/restart", "/api/mining/restart"}, nil)
+	return res
+}
@@ -0,0 +1,212 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Session is an authenticated Vnish/Anthill web API session.
+// Depending on the build, auth is a bearer token (/api/v1/unlock) or a cookie (/api/login).
+type Session struct {
+	Host     string
+	Scheme   string
+	UsedCred string
+
+	client *http.Client
+	token  string
+}
+
+// CommandResult is the outcome of a write/control call against the Vnish/Anthill API.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	Scheme   string `json:"scheme,omitempty"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+var errNoSession = errors.New("login failed (no token/cookie accepted)")
+
+// Login opens a session with the given credential.
+func Login(ctx context.Context, host, scheme string, cred Cred) (*Session, error) {
+	s := &Session{Host: host, Scheme: scheme, UsedCred: cred.Name, client: sessionutil.NewClient(true)}
+
+	// Vnish >= 1.2: POST /api/v1/unlock {"pw": "..."} -> {"token": "..."}
+	if code, b, err := s.Do(ctx, "POST", "/api/v1/unlock", map[string]string{"pw": cred.Password}); err == nil && code >= 200 && code <= 299 {
+		var m map[string]any
+		if json.Unmarshal(sessionutil.SanitizeJSON(b), &m) == nil {
+			if t, ok := m["token"].(string); ok && strings.TrimSpace(t) != "" {
+				s.token = strings.TrimSpace(t)
+				return s, nil
+			}
+		}
+	}
+
+	// Anthill / older builds: cookie session (same attempts as Probe). A success status alone
+	// proves nothing (SPAs answer 200 to anything), so a token or a new cookie is required.
+	for _, p := range []string{"/api/login", "/api/v1/login", "/auth/login"} {
+		before := s.cookies()
+		code, b, err := s.Do(ctx, "POST", p, map[string]string{"username": cred.Username, "password": cred.Password})
+		if err != nil {
+			continue
+		}
+		if (code == 200 || code == 204 || code == 302 || code == 303) && s.accepted(b, before) {
+			return s, nil
+		}
+	}
+	form := url.Values{}
+	form.Set("username", cred.Username)
+	form.Set("password", cred.Password)
+	before := s.cookies()
+	if code, b, err := s.doRaw(ctx, "POST", "/login", []byte(form.Encode()), "application/x-www-form-urlencoded"); err == nil {
+		if (code == 200 || code == 204 || code == 302 || code == 303) && s.accepted(b, before) {
+			return s, nil
+		}
+	}
+	return nil, errNoSession
+}
+
+// accepted reports whether a login answer opened a session: a token in the body (kept for
+// later calls) or a cookie the jar did not hold before the request.
+func (s *Session) accepted(body []byte, before string) bool {
+	var m map[string]any
+	if json.Unmarshal(sessionutil.SanitizeJSON(body), &m) == nil {
+		if t, ok := m["token"].(string); ok && strings.TrimSpace(t) != "" {
+			s.token = strings.TrimSpace(t)
+			return true
+		}
+	}
+	after := s.cookies()
+	return after != "" && after != before
+}
+
+// cookies renders the jar's cookies for the device (name=value pairs, in jar order).
+func (s *Session) cookies() string {
+	if s.client.Jar == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range s.client.Jar.Cookies(&url.URL{Scheme: s.Scheme, Host: s.Host, Path: "/"}) {
+		b.WriteString(c.Name + "=" + c.Value + ";")
+	}
+	return b.String()
+}
+
+// LoginAny tries all creds/schemes and returns the first accepted session.
+func LoginAny(ctx context.Context, host string, creds []Cred, schemes []string) (*Session, error) {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var lastErr error = errNoSession
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if scheme != "http" && scheme != "https" {
+			continue
+		}
+		for _, c := range creds {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			s, err := Login(ctx, host, scheme, c)
+			if err == nil {
+				return s, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// Do sends a JSON request (body may be nil) and returns status + raw body.
+func (s *Session) Do(ctx context.Context, method, path string, body any) (int, []byte, error) {
+	var b []byte
+	if body != nil {
+		var err error
+		b, err = json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return s.doRaw(ctx, method, path, b, "application/json")
+}
+
+func (s *Session) doRaw(ctx context.Context, method, path string, body []byte, contentType string) (int, []byte, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.Scheme+"://"+s.Host+path, rd)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Close = true
+	req.Header.Set("Connection", "close")
+	req.Header.Set("User-Agent", "MonA/asic-control")
+	req.Header.Set("Accept", "application/json,text/plain;q=0.9,*/*;q=0.8")
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	rb, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	_ = resp.Body.Close()
+	return resp.StatusCode, rb, nil
+}
+
+// GetJSON fetches path and decodes JSON (tolerating junk before the payload).
+func (s *Session) GetJSON(ctx context.Context, path string) (any, error) {
+	code, b, err := s.Do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if code < 200 || code > 299 {
+		return nil, errors.New("http " + http.StatusText(code))
+	}
+	var v any
+	if err := json.Unmarshal(sessionutil.SanitizeJSON(b), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// PostFirst posts body to the first path that answers 2xx. Returns the path used.
+func (s *Session) PostFirst(ctx context.Context, paths []string, body any) (string, CommandResult) {
+	out := CommandResult{Scheme: s.Scheme, UsedCred: s.UsedCred, Error: "no endpoint accepted the request"}
+	for _, p := range paths {
+		code, b, err := s.Do(ctx, "POST", p, body)
+		if err != nil {
+			if sessionutil.IsDropAfterSend(err) {
+				out.OK = true
+				out.Error = ""
+				return p, out
+			}
+			out.Error = err.Error()
+			continue
+		}
+		out.Body = sessionutil.TruncBody(b)
+		if code == 401 || code == 403 {
+			out.Error = "unauthorized"
+			continue
+		}
+		if code >= 200 && code <= 299 {
+			out.OK = true
+			out.Error = ""
+			return p, out
+		}
+		out.Error = "http " + http.StatusText(code)
+	}
+	return "", out
+}
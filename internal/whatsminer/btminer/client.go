@@ -0,0 +1,308 @@
+// Package btminer is a client for the Whatsminer btminer API v2 on TCP 4028.
+//
+// Read commands are plain JSON ({"cmd":"summary"}). Write commands need the admin (web UI)
+// password: get_token returns time/salt/newsalt, the AES key is sha256 of md5crypt(password,
+// salt) and every write travels as {"enc":1,"data":base64(AES-256-ECB(json))} carrying a
+// sign derived from the token. The API is disabled on some firmwares until enabled in the
+// Whatsminer tool; the connection is then refused or every command answers with an error.
+package btminer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+const DefaultPort = 4028
+
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
+
+// Client talks to one miner. Tokens are cached per password (valid 30 minutes on the miner).
+type Client struct {
+	Host string
+	Port int // default 4028
+
+	DialTimeout time.Duration // default 1.5s
+	Timeout     time.Duration // whole exchange after connect (default 8s; writes are slow)
+
+	// Dial overrides the dialer. Optional.
+	Dial func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu       sync.Mutex
+	tok      token
+	tokPass  string
+	tokUntil time.Time
+}
+
+func New(host string) *Client {
+	return &Client{Host: host, Port: DefaultPort, DialTimeout: 1500 * time.Millisecond, Timeout: 8 * time.Second}
+}
+
+// Reply is a btminer v2 style answer (get_* helpers and write commands): STATUS is a
+// single letter and Msg carries the payload (object or string).
+type Reply struct {
+	Status      string          `json:"STATUS"`
+	When        cgminer.Number  `json:"When"`
+	Code        cgminer.Number  `json:"Code"`
+	Msg         json.RawMessage `json:"Msg"`
+	Description string          `json:"Description"`
+}
+
+// MsgString returns Msg when it is a plain string.
+func (r Reply) MsgString() string {
+	var s string
+	if json.Unmarshal(r.Msg, &s) == nil {
+		return s
+	}
+	return strings.TrimSpace(string(r.Msg))
+}
+
+// APIError is a STATUS=E answer.
+type APIError struct {
+	Command string
+	Code    int
+	Msg     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("btminer %s: code %d: %s", e.Command, e.Code, e.Msg)
+}
+
+// Error codes from the btminer API v2 documentation.
+const (
+	CodeInvalidCommand = 14
+	CodeInvalidJSON    = 23
+	CodeAccessDenied   = 45
+	CodeTokenError     = 135 // wrong admin password or expired token
+	CodeTokenOverMax   = 136 // too many tokens requested
+)
+
+// errNoReply: the miner closed the connection after a write without answering
+// (reboot/power_off on some builds).
+var errNoReply = errors.New("connection closed without reply")
+
+// IsAuthError reports whether err means the admin password was not accepted.
+func IsAuthError(err error) bool {
+	var ae *APIError
+	return errors.As(err, &ae) && (ae.Code == CodeTokenError || ae.Code == CodeAccessDenied)
+}
+
+// Read runs a read-only command and returns the repaired JSON reply.
+func (c *Client) Read(ctx context.Context, cmd string, params map[string]any) ([]byte, error) {
+	req := map[string]any{"cmd": cmd}
+	for k, v := range params {
+		req[k] = v
+	}
+	b, _ := json.Marshal(req)
+	raw, err := c.roundTrip(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	return cgminer.Repair(raw), nil
+}
+
+// ReadReply runs a read-only get_* command answering in the v2 Reply format.
+func (c *Client) ReadReply(ctx context.Context, cmd string) (Reply, error) {
+	b, err := c.Read(ctx, cmd, nil)
+	if err != nil {
+		return Reply{}, err
+	}
+	return parseReply(cmd, b)
+}
+
+func parseReply(cmd string, b []byte) (Reply, error) {
+	var r Reply
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("btminer %s: %w", cmd, err)
+	}
+	if strings.EqualFold(strings.TrimSpace(r.Status), "E") {
+		return r, &APIError{Command: cmd, Code: int(r.Code), Msg: r.MsgString()}
+	}
+	return r, nil
+}
+
+// ReadCGMiner runs a read command answering in cgminer format (summary, devs, devdetails, pools).
+func (c *Client) ReadCGMiner(ctx context.Context, cmd string) (*cgminer.Response, error) {
+	b, err := c.Read(ctx, cmd, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cgminer.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("btminer %s: %w", cmd, err)
+	}
+	for _, st := range resp.Status {
+		if strings.EqualFold(strings.TrimSpace(st.Status), "E") {
+			return resp, &APIError{Command: cmd, Code: int(st.Code), Msg: st.Msg}
+		}
+	}
+	// Errors may come back in v2 shape ("STATUS":"E" with a string Msg).
+	if len(resp.Status) == 0 {
+		if _, err := parseReply(cmd, b); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// getToken asks for a fresh time/salt/newsalt triple and derives the write token.
+func (c *Client) getToken(ctx context.Context, password string) (token, error) {
+	c.mu.Lock()
+	if c.tokPass == password && time.Now().Before(c.tokUntil) {
+		t := c.tok
+		c.mu.Unlock()
+		return t, nil
+	}
+	c.mu.Unlock()
+
+	r, err := c.ReadReply(ctx, "get_token")
+	if err != nil {
+		return token{}, err
+	}
+	var m struct {
+		Time    string `json:"time"`
+		Salt    string `json:"salt"`
+		NewSalt string `json:"newsalt"`
+	}
+	if err := json.Unmarshal(r.Msg, &m); err != nil || m.Salt == "" || m.NewSalt == "" {
+		return token{}, errors.New("btminer get_token: unexpected reply: " + sessionutil.TruncBody([]byte(r.MsgString())))
+	}
+	t := deriveToken(password, m.Salt, m.NewSalt, m.Time)
+	c.mu.Lock()
+	// the miner keeps tokens for 30 minutes; renew a bit earlier
+	c.tok, c.tokPass, c.tokUntil = t, password, time.Now().Add(25*time.Minute)
+	c.mu.Unlock()
+	return t, nil
+}
+
+func (c *Client) dropToken() {
+	c.mu.Lock()
+	c.tokPass, c.tokUntil = "", time.Time{}
+	c.mu.Unlock()
+}
+
+// Write runs a privileged command with the admin password.
+func (c *Client) Write(ctx context.Context, password, cmd string, params map[string]any) (Reply, error) {
+	tok, err := c.getToken(ctx, password)
+	if err != nil {
+		return Reply{}, err
+	}
+	req := map[string]any{"cmd": cmd, "token": tok.sign}
+	for k, v := range params {
+		req[k] = v
+	}
+	plain, _ := json.Marshal(req)
+	enc, err := tok.encrypt(plain)
+	if err != nil {
+		return Reply{}, err
+	}
+	payload, _ := json.Marshal(map[string]any{"enc": 1, "data": enc})
+	raw, err := c.roundTrip(ctx, payload)
+	if err != nil {
+		if sessionutil.IsDropAfterSend(err) {
+			return Reply{}, fmt.Errorf("btminer %s: %w", cmd, errNoReply)
+		}
+		return Reply{}, err
+	}
+	b := cgminer.Repair(raw)
+
+	// Success (and most errors) come back encrypted; token errors come back in clear.
+	var wrapped struct {
+		Enc string `json:"enc"`
+	}
+	if json.Unmarshal(b, &wrapped) == nil && wrapped.Enc != "" {
+		if b, err = tok.decrypt(wrapped.Enc); err != nil {
+			return Reply{}, fmt.Errorf("btminer %s: decrypt: %w", cmd, err)
+		}
+	}
+	r, err := parseReply(cmd, b)
+	if IsAuthError(err) {
+		c.dropToken()
+	}
+	return r, err
+}
+
+// WriteAny tries the credentials in order (btminer only looks at the password) and returns
+// the reply plus the name of the accepted credential.
+func (c *Client) WriteAny(ctx context.Context, creds []Cred, cmd string, params map[string]any) (Reply, string, error) {
+	var lastErr error = errors.New("btminer: no credentials")
+	used := ""
+	for _, cr := range creds {
+		if cr.Password == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			return Reply{}, used, ctx.Err()
+		}
+		used = cr.Name
+		r, err := c.Write(ctx, cr.Password, cmd, params)
+		if err == nil {
+			return r, cr.Name, nil
+		}
+		lastErr = err
+		if !IsAuthError(err) {
+			// transport or command error: another password will not help
+			return r, cr.Name, err
+		}
+	}
+	return Reply{}, used, lastErr
+}
+
+func (c *Client) roundTrip(ctx context.Context, payload []byte) ([]byte, error) {
+	port := c.Port
+	if port <= 0 {
+		port = DefaultPort
+	}
+	dialTimeout := c.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 1500 * time.Millisecond
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 8 * time.Second
+	}
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(port))
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	if c.Dial != nil {
+		conn, err = c.Dial(dctx, "tcp", addr)
+	} else {
+		d := net.Dialer{Timeout: dialTimeout}
+		conn, err = d.DialContext(dctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = conn.SetDeadline(deadline)
+	if _, err := conn.Write(payload); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	if len(b) == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return bytes.TrimSpace(b), nil
+}
@@ -0,0 +1,432 @@
+package btminer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Summary is SUMMARY[0] as reported by btminer (cgminer fields plus Whatsminer extras).
+type Summary struct {
+	Elapsed           cgminer.Number `json:"Elapsed"`
+	MHSav             cgminer.Number `json:"MHS av"`
+	MHS5s             cgminer.Number `json:"MHS 5s"`
+	MHS1m             cgminer.Number `json:"MHS 1m"`
+	MHS15m            cgminer.Number `json:"MHS 15m"`
+	HSRT              cgminer.Number `json:"HS RT"`
+	Accepted          cgminer.Number `json:"Accepted"`
+	Rejected          cgminer.Number `json:"Rejected"`
+	TotalMH           cgminer.Number `json:"Total MH"`
+	Temperature       cgminer.Number `json:"Temperature"`
+	EnvTemp           cgminer.Number `json:"Env Temp"`
+	ChipTempMin       cgminer.Number `json:"Chip Temp Min"`
+	ChipTempMax       cgminer.Number `json:"Chip Temp Max"`
+	ChipTempAvg       cgminer.Number `json:"Chip Temp Avg"`
+	FreqAvg           cgminer.Number `json:"freq_avg"`
+	FanSpeedIn        cgminer.Number `json:"Fan Speed In"`
+	FanSpeedOut       cgminer.Number `json:"Fan Speed Out"`
+	Voltage           cgminer.Number `json:"Voltage"`
+	Power             cgminer.Number `json:"Power"`
+	PowerRT           cgminer.Number `json:"Power_RT"`
+	PowerLimit        cgminer.Number `json:"Power Limit"`
+	PowerMode         string         `json:"Power Mode"`
+	FactoryGHS        cgminer.Number `json:"Factory GHS"`
+	TargetMHS         cgminer.Number `json:"Target MHS"`
+	TargetFreq        cgminer.Number `json:"Target Freq"`
+	DeviceHardwarePct cgminer.Number `json:"Device Hardware%"`
+	DeviceRejectedPct cgminer.Number `json:"Device Rejected%"`
+	PoolRejectedPct   cgminer.Number `json:"Pool Rejected%"`
+	PoolStalePct      cgminer.Number `json:"Pool Stale%"`
+	Uptime            cgminer.Number `json:"Uptime"`
+	ErrorCodeCount    cgminer.Number `json:"Error Code Count"`
+	SecurityMode      cgminer.Number `json:"Security Mode"`
+	LiquidCooling     cgminer.Bool   `json:"Liquid Cooling"`
+	HashStable        cgminer.Bool   `json:"Hash Stable"`
+	MAC               string         `json:"MAC"`
+	FirmwareVersion   string         `json:"Firmware Version"`
+}
+
+// PowerW is the PSU-reported input power (Power, else the realtime estimate Power_RT).
+func (s Summary) PowerW() float64 {
+	if s.Power > 0 {
+		return s.Power.Float()
+	}
+	return s.PowerRT.Float()
+}
+
+// HashrateTHS prefers the realtime value, then 1m/5s/av.
+func (s Summary) HashrateTHS() float64 {
+	for _, v := range []cgminer.Number{s.HSRT, s.MHS1m, s.MHS5s, s.MHSav} {
+		if v > 0 {
+			return v.Float() / 1e6
+		}
+	}
+	return 0
+}
+
+// DevDetail is one DEVDETAILS entry (one per hashboard).
+type DevDetail struct {
+	Index     cgminer.Number `json:"DEVDETAILS"`
+	Name      string         `json:"Name"`
+	ID        cgminer.Number `json:"ID"`
+	Driver    string         `json:"Driver"`
+	Kernel    string         `json:"Kernel"`
+	Model     string         `json:"Model"`
+	Chips     cgminer.Number `json:"Chips"`
+	Frequency cgminer.Number `json:"Frequency"`
+}
+
+// PSU is get_psu Msg. Values are strings on the wire; numbers are parsed best-effort.
+type PSU struct {
+	Name      string         `json:"name"`
+	Model     string         `json:"model"`
+	Vendor    string         `json:"vendor"`
+	SerialNo  string         `json:"serial_no"`
+	HWVersion string         `json:"hw_version"`
+	SWVersion string         `json:"sw_version"`
+	Version   string         `json:"version"`
+	Iin       cgminer.Number `json:"iin"`
+	Vin       cgminer.Number `json:"vin"`
+	Pin       cgminer.Number `json:"pin"`
+	FanSpeed  cgminer.Number `json:"fan_speed"`
+	Temp      cgminer.Number `json:"temp0"`
+}
+
+// ErrorCode is one active error (get_error_code). Time is as reported ("2024-01-02 03:04:05").
+type ErrorCode struct {
+	Code string `json:"code"`
+	Time string `json:"time,omitempty"`
+}
+
+func (c *Client) Summary(ctx context.Context) (Summary, error) {
+	resp, err := c.ReadCGMiner(ctx, "summary")
+	if err != nil {
+		return Summary{}, err
+	}
+	var list []Summary
+	if err := resp.Decode("SUMMARY", &list); err != nil {
+		return Summary{}, fmt.Errorf("btminer summary: %w", err)
+	}
+	if len(list) == 0 {
+		return Summary{}, errors.New("btminer summary: empty")
+	}
+	return list[0], nil
+}
+
+func (c *Client) DevDetails(ctx context.Context) ([]DevDetail, error) {
+	resp, err := c.ReadCGMiner(ctx, "devdetails")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Section("DEVDETAILS") == nil {
+		return nil, nil
+	}
+	var out []DevDetail
+	if err := resp.Decode("DEVDETAILS", &out); err != nil {
+		return nil, fmt.Errorf("btminer devdetails: %w", err)
+	}
+	return out, nil
+}
+
+func (c *Client) Pools(ctx context.Context) ([]cgminer.Pool, error) {
+	resp, err := c.ReadCGMiner(ctx, "pools")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Section("POOLS") == nil {
+		return nil, nil
+	}
+	var out []cgminer.Pool
+	if err := resp.Decode("POOLS", &out); err != nil {
+		return nil, fmt.Errorf("btminer pools: %w", err)
+	}
+	return out, nil
+}
+
+func (c *Client) PSU(ctx context.Context) (PSU, error) {
+	r, err := c.ReadReply(ctx, "get_psu")
+	if err != nil {
+		return PSU{}, err
+	}
+	var p PSU
+	if err := json.Unmarshal(r.Msg, &p); err != nil {
+		return PSU{}, fmt.Errorf("btminer get_psu: %w", err)
+	}
+	return p, nil
+}
+
+// ErrorCodes returns the active error codes, oldest first when times are known.
+// Firmwares answer either [{"code":"time"},...] or ["code",...].
+func (c *Client) ErrorCodes(ctx context.Context) ([]ErrorCode, error) {
+	r, err := c.ReadReply(ctx, "get_error_code")
+	if err != nil {
+		return nil, err
+	}
+	var m struct {
+		ErrorCode []json.RawMessage `json:"error_code"`
+	}
+	if err := json.Unmarshal(r.Msg, &m); err != nil {
+		return nil, fmt.Errorf("btminer get_error_code: %w", err)
+	}
+	var out []ErrorCode
+	for _, raw := range m.ErrorCode {
+		var obj map[string]string
+		if json.Unmarshal(raw, &obj) == nil {
+			for code, tm := range obj {
+				out = append(out, ErrorCode{Code: code, Time: tm})
+			}
+			continue
+		}
+		var code cgminer.Text
+		if json.Unmarshal(raw, &code) == nil && code != "" {
+			out = append(out, ErrorCode{Code: string(code)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	return out, nil
+}
+
+// CommandResult is the outcome of a write command.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+func (c *Client) write(ctx context.Context, creds []Cred, cmd string, params map[string]any, dropOK bool) CommandResult {
+	r, used, err := c.WriteAny(ctx, creds, cmd, params)
+	out := CommandResult{UsedCred: used, Body: sessionutil.TruncBody([]byte(r.MsgString()))}
+	switch {
+	case err == nil:
+		out.OK = true
+	case dropOK && errors.Is(err, errNoReply):
+		// reboot/power_off may close the socket before answering
+		out.OK = true
+	default:
+		out.Error = err.Error()
+	}
+	return out
+}
+
+func (c *Client) Reboot(ctx context.Context, creds []Cred) CommandResult {
+	return c.write(ctx, creds, "reboot", nil, true)
+}
+
+// PowerOff stops hashing (the control board stays up). respbefore makes the miner answer first.
+func (c *Client) PowerOff(ctx context.Context, creds []Cred) CommandResult {
+	return c.write(ctx, creds, "power_off", map[string]any{"respbefore": "true"}, true)
+}
+
+func (c *Client) PowerOn(ctx context.Context, creds []Cred) CommandResult {
+	return c.write(ctx, creds, "power_on", nil, false)
+}
+
+// Pool is one pool slot for UpdatePools (btminer takes exactly three).
+type Pool = sdk.Pool
+
+// UpdatePools replaces pools 1..3; btminer restarts mining by itself.
+func (c *Client) UpdatePools(ctx context.Context, creds []Cred, pools []Pool) CommandResult {
+	if len(pools) == 0 {
+		return CommandResult{Error: "no pools"}
+	}
+	params := map[string]any{}
+	for i := 0; i < 3; i++ {
+		var p Pool
+		if i < len(pools) {
+			p = pools[i]
+		}
+		n := strconv.Itoa(i + 1)
+		params["pool"+n] = p.URL
+		params["worker"+n] = p.User
+		params["passwd"+n] = p.Pass
+	}
+	return c.write(ctx, creds, "update_pools", params, true)
+}
+
+// LED is a set_led request: Auto returns control to the firmware, otherwise the LED of
+// Color ("red"/"green") blinks Duration ms on every Period ms, starting at Start ms.
+type LED struct {
+	Auto     bool   `json:"auto"`
+	Color    string `json:"color,omitempty"`
+	Period   int    `json:"period,omitempty"`
+	Duration int    `json:"duration,omitempty"`
+	Start    int    `json:"start,omitempty"`
+}
+
+func (c *Client) SetLED(ctx context.Context, creds []Cred, led LED) CommandResult {
+	if led.Auto {
+		return c.write(ctx, creds, "set_led", map[string]any{"param": "auto"}, false)
+	}
+	color := strings.ToLower(strings.TrimSpace(led.Color))
+	if color != "red" && color != "green" {
+		color = "red"
+	}
+	if led.Period <= 0 {
+		led.Period = 1000
+	}
+	if led.Duration <= 0 || led.Duration > led.Period {
+		led.Duration = led.Period / 2
+	}
+	return c.write(ctx, creds, "set_led", map[string]any{
+		"color":    color,
+		"period":   led.Period,
+		"duration": led.Duration,
+		"start":    led.Start,
+	}, false)
+}
+
+// SetPowerPct limits power to pct percent of nominal (0..100). Takes effect without reboot.
+func (c *Client) SetPowerPct(ctx context.Context, creds []Cred, pct int) CommandResult {
+	if pct < 0 || pct > 100 {
+		return CommandResult{Error: "percent must be 0..100"}
+	}
+	return c.write(ctx, creds, "set_power_pct", map[string]any{"percent": strconv.Itoa(pct)}, false)
+}
+
+// Dev is one DEVS entry (one per hashboard slot).
+type Dev struct {
+	ASC            cgminer.Number `json:"ASC"`
+	Slot           cgminer.Number `json:"Slot"`
+	Enabled        cgminer.Bool   `json:"Enabled"`
+	Status         string         `json:"Status"`
+	Temperature    cgminer.Number `json:"Temperature"`
+	ChipFrequency  cgminer.Number `json:"Chip Frequency"`
+	MHSav          cgminer.Number `json:"MHS av"`
+	MHS5s          cgminer.Number `json:"MHS 5s"`
+	MHS1m          cgminer.Number `json:"MHS 1m"`
+	Accepted       cgminer.Number `json:"Accepted"`
+	Rejected       cgminer.Number `json:"Rejected"`
+	HardwareErrors cgminer.Number `json:"Hardware Errors"`
+	EffectiveChips cgminer.Number `json:"Effective Chips"`
+	ChipTempMin    cgminer.Number `json:"Chip Temp Min"`
+	ChipTempMax    cgminer.Number `json:"Chip Temp Max"`
+	ChipTempAvg    cgminer.Number `json:"Chip Temp Avg"`
+	FactoryGHS     cgminer.Number `json:"Factory GHS"`
+	PCBSN          string         `json:"PCB SN"`
+}
+
+func (c *Client) Devs(ctx context.Context) ([]Dev, error) {
+	resp, err := c.ReadCGMiner(ctx, "devs")
+	if err != nil {
+		return nil, err
+	}
+	if resp.Section("DEVS") == nil {
+		return nil, nil
+	}
+	var out []Dev
+	if err := resp.Decode("DEVS", &out); err != nil {
+		return nil, fmt.Errorf("btminer devs: %w", err)
+	}
+	return out, nil
+}
+
+// Telemetry is the credential-less read set used by polling. Only summary is required.
+type Telemetry struct {
+	Summary Summary        `json:"summary"`
+	Devs    []Dev          `json:"devs,omitempty"`
+	Details []DevDetail    `json:"devdetails,omitempty"`
+	Pools   []cgminer.Pool `json:"pools,omitempty"`
+}
+
+func (c *Client) Telemetry(ctx context.Context) (Telemetry, error) {
+	var t Telemetry
+	s, err := c.Summary(ctx)
+	if err != nil {
+		return t, err
+	}
+	t.Summary = s
+	if ds, err := c.Devs(ctx); err == nil {
+		t.Devs = ds
+	}
+	if dd, err := c.DevDetails(ctx); err == nil {
+		t.Details = dd
+	}
+	if ps, err := c.Pools(ctx); err == nil {
+		t.Pools = ps
+	}
+	return t, nil
+}
+
+// Model is the raw model string from devdetails (e.g. "M30S+.VE40"), variant suffix dropped.
+func (t Telemetry) Model() string {
+	for _, d := range t.Details {
+		if m := strings.TrimSpace(d.Model); m != "" {
+			if i := strings.Index(m, "."); i > 0 {
+				m = m[:i]
+			}
+			return m
+		}
+	}
+	return ""
+}
+
+// Fans returns intake/outtake fan speeds (some builds report them negative).
+func (t Telemetry) Fans() []int {
+	var out []int
+	for _, v := range []cgminer.Number{t.Summary.FanSpeedIn, t.Summary.FanSpeedOut} {
+		rpm := int(v)
+		if rpm < 0 {
+			rpm = -rpm
+		}
+		if rpm > 0 {
+			out = append(out, rpm)
+		}
+	}
+	return out
+}
+
+// Temps returns per-board temperatures, or the summary temperature when devs is missing.
+func (t Telemetry) Temps() []float64 {
+	var out []float64
+	for _, d := range t.Devs {
+		if d.Temperature > 0 {
+			out = append(out, d.Temperature.Float())
+		}
+	}
+	if len(out) == 0 && t.Summary.Temperature > 0 {
+		out = append(out, t.Summary.Temperature.Float())
+	}
+	return out
+}
+
+// Chains maps devs (one entry per hashboard) onto the shared per-chain model. Whatsminer
+// reports a single board temperature and the chip min/max/avg rather than every sensor.
+func (t Telemetry) Chains() []sdk.Chain {
+	var out []sdk.Chain
+	for i, d := range t.Devs {
+		idx := i
+		if d.Slot > 0 || d.ASC > 0 {
+			idx = int(d.Slot.Int())
+			if d.Slot == 0 {
+				idx = int(d.ASC.Int())
+			}
+		}
+		c := sdk.Chain{
+			Index:       idx,
+			HashrateTHS: d.MHSav.Float() / 1e6,
+			IdealTHS:    d.FactoryGHS.Float() / 1e3,
+			Chips:       int(d.EffectiveChips.Int()),
+			FreqMHz:     d.ChipFrequency.Float(),
+		}
+		if d.HardwareErrors > 0 {
+			c.HWErrors = uint64(d.HardwareErrors.Int())
+		}
+		if d.ChipTempMax > 0 {
+			c.ChipTempsC = []float64{d.ChipTempMax.Float()}
+		}
+		if d.Temperature > 0 {
+			c.PCBTempsC = []float64{d.Temperature.Float()}
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package btminer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/md5" //nolint:gosec // md5-crypt is what btminer uses for key derivation
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// md5Crypt is the FreeBSD "$1$" crypt(3) scheme. It returns the full "$1$salt$hash" string.
+func md5Crypt(password, salt string) string {
+	const magic = "$1$"
+	salt = strings.TrimPrefix(salt, magic)
+	if i := strings.IndexByte(salt, '$'); i >= 0 {
+		salt = salt[:i]
+	}
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw := []byte(password)
+	sl := []byte(salt)
+
+	alt := md5.New()
+	alt.Write(pw)
+	alt.Write(sl)
+	alt.Write(pw)
+	final := alt.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(pw)
+	ctx.Write([]byte(magic))
+	ctx.Write(sl)
+	for i := len(pw); i > 0; i -= 16 {
+		n := i
+		if n > 16 {
+			n = 16
+		}
+		ctx.Write(final[:n])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(pw[:1])
+		}
+	}
+	final = ctx.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		c := md5.New()
+		if i&1 != 0 {
+			c.Write(pw)
+		} else {
+			c.Write(final)
+		}
+		if i%3 != 0 {
+			c.Write(sl)
+		}
+		if i%7 != 0 {
+			c.Write(pw)
+		}
+		if i&1 != 0 {
+			c.Write(final)
+		} else {
+			c.Write(pw)
+		}
+		final = c.Sum(nil)
+	}
+
+	var out bytes.Buffer
+	to64 := func(v uint32, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	to64(uint32(final[0])<<16|uint32(final[6])<<8|uint32(final[12]), 4)
+	to64(uint32(final[1])<<16|uint32(final[7])<<8|uint32(final[13]), 4)
+	to64(uint32(final[2])<<16|uint32(final[8])<<8|uint32(final[14]), 4)
+	to64(uint32(final[3])<<16|uint32(final[9])<<8|uint32(final[15]), 4)
+	to64(uint32(final[4])<<16|uint32(final[10])<<8|uint32(final[5]), 4)
+	to64(uint32(final[11]), 2)
+	return magic + salt + "$" + out.String()
+}
+
+// cryptHash returns only the hash part of md5Crypt (what btminer calls the key / sign).
+func cryptHash(password, salt string) string {
+	parts := strings.Split(md5Crypt(password, salt), "$")
+	return parts[len(parts)-1]
+}
+
+// token is the per-session write credential derived from get_token.
+type token struct {
+	aesKey []byte // sha256(key)
+	sign   string // md5crypt(key+time, newsalt)
+}
+
+func deriveToken(password, salt, newsalt, tm string) token {
+	key := cryptHash(password, salt)
+	sum := sha256.Sum256([]byte(key))
+	return token{aesKey: sum[:], sign: cryptHash(key+tm, newsalt)}
+}
+
+// encrypt: zero-pad to the AES block size, AES-256-ECB, base64 (no newlines).
+func (t token) encrypt(plain []byte) (string, error) {
+	block, err := aes.NewCipher(t.aesKey)
+	if err != nil {
+		return "", err
+	}
+	bs := block.BlockSize()
+	if r := len(plain) % bs; r != 0 {
+		plain = append(plain, make([]byte, bs-r)...)
+	}
+	out := make([]byte, len(plain))
+	for i := 0; i < len(plain); i += bs {
+		block.Encrypt(out[i:i+bs], plain[i:i+bs])
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func (t token) decrypt(enc string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(enc))
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(t.aesKey)
+	if err != nil {
+		return nil, err
+	}
+	bs := block.BlockSize()
+	if len(raw)%bs != 0 {
+		return nil, errors.New("btminer: encrypted reply is not block aligned")
+	}
+	out := make([]byte, len(raw))
+	for i := 0; i < len(raw); i += bs {
+		block.Decrypt(out[i:i+bs], raw[i:i+bs])
+	}
+	return bytes.TrimRight(out, "\x00"), nil
+}
@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Reboot reboots a Whatsminer through the LuCI web UI.
+func Reboot(ctx context.Context, host string, creds []Cred, schemes []string) CommandResult {
+	l, err := LoginLuCIAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	out := CommandResult{Scheme: l.Scheme, UsedCred: l.UsedCred, Error: "no reboot endpoint accepted the request"}
+	// OpenWrt/LuCI variants seen on Whatsminer control boards.
+	attempts := []struct {
+		path string
+		form url.Values
+	}{
+		{path: "admin/system/reboot/call", form: url.Values{}},
+		{path: "admin/system/reboot", form: url.Values{"reboot": {"1"}}},
+		{path: "admin/status/reboot", form: url.Values{"reboot": {"1"}}},
+	}
+	for _, a := range attempts {
+		code, body, err := l.PostForm(ctx, a.path, a.form)
+		if err != nil {
+			if sessionutil.IsDropAfterSend(err) {
+				out.OK = true
+				out.Error = ""
+				return out
+			}
+			out.Error = err.Error()
+			continue
+		}
+		out.Body = sessionutil.TruncBody([]byte(body))
+		if code >= 200 && code <= 299 {
+			out.OK = true
+			out.Error = ""
+			return out
+		}
+		out.Error = "http " + http.StatusText(code)
+	}
+	return out
+}
@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"asic-control/internal/cgminer"
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/whatsminer/btminer"
+)
+
+func init() { sdk.Register(driver{}) }
+
+// driver is Whatsminer: the btminer API (4028) first, which needs no credentials for reads,
+// then the LuCI web UI for boxes with the API disabled. Power and LED writes exist only
+// on btminer.
+type driver struct{}
+
+func (driver) Name() string { return "whatsminer" }
+
+func (driver) Detect(t sdk.Target) int {
+	if t.VendorKey() == "whatsminer" {
+		return sdk.ConfidenceExact
+	}
+	return sdk.ConfidenceNone
+}
+
+func (driver) Probe(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.ProbeResult {
+	if t.HasPort(btminer.DefaultPort) {
+		if tel, err := btminer.New(t.IP).Telemetry(ctx); err == nil {
+			return sdk.ProbeResult{OK: true, Reply: tel}
+		}
+	}
+	res := Probe(ctx, t.IP, creds, []string{"http"})
+	return sdk.ProbeResult{OK: res.OK, Scheme: res.Scheme, UsedCred: res.UsedCred, Error: res.Error,
+		Responses: map[string]any{"whatsminer": res.Responses}, Reply: res}
+}
+
+func (driver) ExtractFacts(pr sdk.ProbeResult) sdk.Facts {
+	switch res := pr.Reply.(type) {
+	case btminer.Telemetry:
+		f := sdk.Facts{
+			Vendor:      "whatsminer",
+			Model:       res.Model(),
+			Firmware:    res.Summary.FirmwareVersion,
+			MAC:         res.Summary.MAC,
+			HashrateTHS: res.Summary.HashrateTHS(),
+			FansRPM:     res.Fans(),
+			TempsC:      res.Temps(),
+			PowerW:      res.Summary.PowerW(),
+			Chains:      res.Chains(),
+			Pools:       cgminer.PoolStatuses(res.Pools),
+		}
+		if up := res.Summary.Elapsed.Int(); up > 0 {
+			f.UptimeS = uint64(up)
+		}
+		return f
+	case ProbeResult:
+		f := ExtractFacts(res)
+		return sdk.Facts{
+			Vendor:      "whatsminer",
+			Model:       f.Model,
+			UptimeS:     f.UptimeS,
+			HashrateTHS: f.HashrateTHS,
+			FansRPM:     f.FansRPM,
+			TempsC:      f.TempsC,
+		}
+	}
+	return sdk.Facts{}
+}
+
+func (driver) Commands() sdk.Commands {
+	return sdk.Commands{
+		Reboot: func(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.CommandResult {
+			return withLuCI(t, func() btminer.CommandResult {
+				return btminer.New(t.IP).Reboot(ctx, creds)
+			}, func() CommandResult {
+				return Reboot(ctx, t.IP, creds, t.Schemes())
+			})
+		},
+		SetPools: func(ctx context.Context, t sdk.Target, creds []sdk.Cred, pools []sdk.Pool) sdk.CommandResult {
+			return withLuCI(t, func() btminer.CommandResult {
+				return btminer.New(t.IP).UpdatePools(ctx, creds, pools)
+			}, func() CommandResult {
+				return SetPools(ctx, t.IP, creds, t.Schemes(), pools)
+			})
+		},
+		Control: map[string]sdk.ControlFunc{
+			sdk.KindPowerOff: btminerControl(func(ctx context.Context, c *btminer.Client, creds []sdk.Cred, _ map[string]string) sdk.CommandResult {
+				return btminerResult(c.PowerOff(ctx, creds))
+			}),
+			sdk.KindPowerOn: btminerControl(func(ctx context.Context, c *btminer.Client, creds []sdk.Cred, _ map[string]string) sdk.CommandResult {
+				return btminerResult(c.PowerOn(ctx, creds))
+			}),
+			sdk.KindSetLED: btminerControl(func(ctx context.Context, c *btminer.Client, creds []sdk.Cred, args map[string]string) sdk.CommandResult {
+				return btminerResult(c.SetLED(ctx, creds, ledFromArgs(args)))
+			}),
+			sdk.KindSetPowerPct: btminerControl(func(ctx context.Context, c *btminer.Client, creds []sdk.Cred, args map[string]string) sdk.CommandResult {
+				n, err := strconv.Atoi(strings.TrimSpace(args["percent"]))
+				if err != nil || n < 0 || n > 100 {
+					return sdk.CommandResult{Error: "bad args: percent must be 0..100"}
+				}
+				r := btminerResult(c.SetPowerPct(ctx, creds, n))
+				r.Detail = strconv.Itoa(n) + "%"
+				return r
+			}),
+			sdk.KindDiagnostics: btminerControl(func(ctx context.Context, c *btminer.Client, _ []sdk.Cred, _ map[string]string) sdk.CommandResult {
+				return btminerDiagnostics(ctx, c)
+			}),
+		},
+	}
+}
+
+// ReadLog implements sdk.LogReader.
+func (driver) ReadLog(ctx context.Context, t sdk.Target, creds []sdk.Cred) sdk.LogResult {
+	r := ReadLogs(ctx, t.IP, creds, t.Schemes())
+	return sdk.LogResult{OK: r.OK, UsedCred: r.UsedCred, Sources: r.Sources, Error: r.Error, Text: r.Text}
+}
+
+// withLuCI runs the btminer write when 4028 is open and falls back to LuCI, keeping both errors.
+func withLuCI(t sdk.Target, api func() btminer.CommandResult, luci func() CommandResult) sdk.CommandResult {
+	var prev string
+	if t.HasPort(btminer.DefaultPort) {
+		br := api()
+		if br.OK {
+			return btminerResult(br)
+		}
+		prev = "btminer: " + br.Error
+	}
+	r := luci()
+	out := sdk.CommandResult{OK: r.OK, Driver: "whatsminer", UsedCred: r.UsedCred, Error: r.Error}
+	if !r.OK && prev != "" {
+		out.Error = prev + "; " + r.Error
+	}
+	return out
+}
+
+func btminerControl(fn func(ctx context.Context, c *btminer.Client, creds []sdk.Cred, args map[string]string) sdk.CommandResult) sdk.ControlFunc {
+	return func(ctx context.Context, t sdk.Target, creds []sdk.Cred, args map[string]string) sdk.CommandResult {
+		if !t.HasPort(btminer.DefaultPort) {
+			return sdk.CommandResult{Error: "unsupported vendor: whatsminer without btminer API (4028 closed)"}
+		}
+		return fn(ctx, btminer.New(t.IP), creds, args)
+	}
+}
+
+// btminerDiagnostics reads summary/devs/devdetails, PSU and active error codes (no
+// credentials needed); PSU and error codes are optional on older firmware.
+func btminerDiagnostics(ctx context.Context, c *btminer.Client) sdk.CommandResult {
+	tel, err := c.Telemetry(ctx)
+	if err != nil {
+		return sdk.CommandResult{Driver: "btminer", Error: err.Error()}
+	}
+	out := map[string]any{"telemetry": tel}
+	if psu, err := c.PSU(ctx); err == nil {
+		out["psu"] = psu
+	} else {
+		out["psu_error"] = err.Error()
+	}
+	if codes, err := c.ErrorCodes(ctx); err == nil {
+		out["error_codes"] = codes
+	} else {
+		out["error_codes_error"] = err.Error()
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return sdk.CommandResult{Driver: "btminer", Error: err.Error()}
+	}
+	return sdk.CommandResult{OK: true, Driver: "btminer", Detail: string(b)}
+}
+
+func btminerResult(r btminer.CommandResult) sdk.CommandResult {
+	return sdk.CommandResult{OK: r.OK, Driver: "btminer", UsedCred: r.UsedCred, Error: r.Error}
+}
+
+func ledFromArgs(args map[string]string) btminer.LED {
+	if strings.EqualFold(strings.TrimSpace(args["mode"]), "auto") {
+		return btminer.LED{Auto: true}
+	}
+	period, _ := strconv.Atoi(args["period_ms"])
+	duration, _ := strconv.Atoi(args["duration_ms"])
+	return btminer.LED{Color: args["color"], Period: period, Duration: duration}
+}
@@ -3,6 +3,8 @@ package httpapi
 import (
 	"fmt"
 	"strings"
+
+	"asic-control/internal/collectors/sessionutil"
 )
 
 type Facts struct {
@@ -17,7 +19,7 @@ type Facts struct {
 func ExtractFacts(res ProbeResult) Facts {
 	var f Facts
 	for _, v := range res.Responses {
-		f.Model = firstNonEmpty(f.Model, findStringDeep(v, set("model", "type", "miner_type", "product", "miner_model")))
+		f.Model = sessionutil.FirstNonEmpty(f.Model, findStringDeep(v, set("model", "type", "miner_type", "product", "miner_model")))
 		if f.UptimeS == 0 {
 			f.UptimeS = findU64Deep(v, set("uptime", "elapsed", "elapsed_s", "uptime_s"))
 		}
@@ -48,13 +50,6 @@ func ExtractFacts(res ProbeResult) Facts {
 	return f
 }
 
-func firstNonEmpty(a, b string) string {
-	if strings.TrimSpace(a) != "" {
-		return a
-	}
-	return strings.TrimSpace(b)
-}
-
 func set(keys ...string) map[string]struct{} {
 	m := map[string]struct{}{}
 	for _, k := range keys {
@@ -240,4 +235,3 @@ func toF64(v any) float64 {
 		return 0
 	}
 }
-
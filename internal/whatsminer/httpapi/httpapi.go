@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"asic-control/internal/collectors/sdk"
 )
 
 type ProbeResult struct {
@@ -19,11 +21,8 @@ type ProbeResult struct {
 	Raw      map[string]string `json:"raw,omitempty"`
 }
 
-type Cred struct {
-	Name     string
-	Username string
-	Password string
-}
+// Cred is the shared login candidate (see sdk.Cred).
+type Cred = sdk.Cred
 
 func Probe(ctx context.Context, host string, creds []Cred, schemes []string) ProbeResult {
 	if len(schemes) == 0 {
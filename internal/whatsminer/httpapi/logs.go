@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// LogResult is the text of the LuCI log pages, each source introduced by "==> page <==".
+type LogResult struct {
+	OK       bool     `json:"ok"`
+	Scheme   string   `json:"scheme,omitempty"`
+	UsedCred string   `json:"used_cred,omitempty"`
+	Sources  []string `json:"sources,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Text     string   `json:"-"`
+}
+
+// logPages: syslog carries btminer's messages on Whatsminer control boards, dmesg the
+// kernel side (hashboard/PSU drivers).
+var logPages = []string{"admin/status/syslog", "admin/status/dmesg"}
+
+var textareaRe = regexp.MustCompile(`(?is)<textarea[^>]*>(.*?)</textarea>`)
+
+// ReadLogs fetches the system and kernel logs through LuCI. The pages render the log in a
+// <textarea>; a page without one is taken as plain text.
+func ReadLogs(ctx context.Context, host string, creds []Cred, schemes []string) LogResult {
+	l, err := LoginLuCIAny(ctx, host, creds, schemes)
+	if err != nil {
+		return LogResult{Error: err.Error()}
+	}
+	out := LogResult{Scheme: l.Scheme, UsedCred: l.UsedCred, Error: "no log page answered"}
+	var sb strings.Builder
+	for _, p := range logPages {
+		code, body, err := l.Get(ctx, p)
+		switch {
+		case err != nil:
+			out.Error = err.Error()
+			continue
+		case code < 200 || code > 299:
+			out.Error = "http " + http.StatusText(code)
+			continue
+		}
+		text := body
+		if m := textareaRe.FindStringSubmatch(body); len(m) == 2 {
+			text = html.UnescapeString(m[1])
+		} else if strings.Contains(strings.ToLower(body), "<html") {
+			out.Error = p + ": no log on page"
+			continue
+		}
+		out.Sources = append(out.Sources, p)
+		sb.WriteString("==> " + p + " <==\n")
+		sb.WriteString(strings.TrimRight(text, "\n") + "\n")
+	}
+	if len(out.Sources) > 0 {
+		out.OK, out.Error, out.Text = true, "", sb.String()
+	}
+	return out
+}
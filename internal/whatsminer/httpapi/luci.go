@@ -0,0 +1,137 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// LuCI is a logged-in session to the Whatsminer OpenWrt web UI (/cgi-bin/luci).
+// Older firmwares (and boxes with the btminer API disabled) can only be controlled this way.
+type LuCI struct {
+	Host     string
+	Scheme   string
+	UsedCred string
+
+	client *http.Client
+	stok   string // ";stok=..." path token on newer LuCI builds
+}
+
+// CommandResult is the outcome of a write/control call against a Whatsminer.
+type CommandResult struct {
+	OK       bool   `json:"ok"`
+	Scheme   string `json:"scheme,omitempty"`
+	UsedCred string `json:"used_cred,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
+
+var stokRe = regexp.MustCompile(`;stok=([0-9a-fA-F]+)`)
+
+var errLuCILogin = errors.New("luci login failed")
+
+func LoginLuCI(ctx context.Context, host, scheme string, cred Cred) (*LuCI, error) {
+	l := &LuCI{Host: host, Scheme: scheme, UsedCred: cred.Name, client: sessionutil.NewClient(true)}
+	// keep the redirect Location: it carries the stok token
+	l.client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	form := url.Values{}
+	form.Set("luci_username", cred.Username)
+	form.Set("luci_password", cred.Password)
+	code, hdr, body, err := l.do(ctx, "POST", "/cgi-bin/luci", form)
+	if err != nil {
+		return nil, err
+	}
+	if m := stokRe.FindStringSubmatch(hdr.Get("Location")); len(m) == 2 {
+		l.stok = m[1]
+	} else if m := stokRe.FindStringSubmatch(body); len(m) == 2 {
+		l.stok = m[1]
+	}
+	hasCookie := false
+	if u, err := url.Parse(scheme + "://" + host + "/cgi-bin/luci"); err == nil {
+		for _, c := range l.client.Jar.Cookies(u) {
+			if strings.HasPrefix(strings.ToLower(c.Name), "sysauth") {
+				hasCookie = true
+			}
+		}
+	}
+	// LuCI answers 200 + login form again on bad password; success is a redirect and/or sysauth cookie.
+	if (code == 302 || code == 303) || hasCookie || l.stok != "" {
+		return l, nil
+	}
+	return nil, errLuCILogin
+}
+
+func LoginLuCIAny(ctx context.Context, host string, creds []Cred, schemes []string) (*LuCI, error) {
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	var lastErr error = errLuCILogin
+	for _, scheme := range schemes {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if scheme != "http" && scheme != "https" {
+			continue
+		}
+		for _, c := range creds {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			l, err := LoginLuCI(ctx, host, scheme, c)
+			if err == nil {
+				return l, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// Path builds an admin path honoring the stok token, e.g. Path("admin/system/reboot").
+func (l *LuCI) Path(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if l.stok != "" {
+		return "/cgi-bin/luci/;stok=" + l.stok + "/" + p
+	}
+	return "/cgi-bin/luci/" + p
+}
+
+// Get fetches an admin page/endpoint.
+func (l *LuCI) Get(ctx context.Context, p string) (int, string, error) {
+	code, _, body, err := l.do(ctx, "GET", l.Path(p), nil)
+	return code, body, err
+}
+
+// PostForm submits a form to an admin page/endpoint.
+func (l *LuCI) PostForm(ctx context.Context, p string, form url.Values) (int, string, error) {
+	code, _, body, err := l.do(ctx, "POST", l.Path(p), form)
+	return code, body, err
+}
+
+func (l *LuCI) do(ctx context.Context, method, path string, form url.Values) (int, http.Header, string, error) {
+	var rd io.Reader
+	if form != nil {
+		rd = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, l.Scheme+"://"+l.Host+path, rd)
+	if err != nil {
+		return 0, nil, "", err
+	}
+	req.Close = true
+	req.Header.Set("Connection", "close")
+	req.Header.Set("User-Agent", "MonA/asic-control")
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return 0, nil, "", err
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 512*1024))
+	_ = resp.Body.Close()
+	return resp.StatusCode, resp.Header, string(b), nil
+}
@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"asic-control/internal/collectors/sdk"
+	"asic-control/internal/collectors/sessionutil"
+)
+
+// Pool is one pool slot (Whatsminer has exactly 3).
+type Pool = sdk.Pool
+
+var luciTokenRe = regexp.MustCompile(`name="token"\s+value="([0-9a-fA-F]+)"`)
+
+// SetPools writes pools 1..3 through the LuCI cgminer configuration page (CBI form)
+// and applies it (btminer restarts with the new pools).
+func SetPools(ctx context.Context, host string, creds []Cred, schemes []string, pools []Pool) CommandResult {
+	if len(pools) == 0 {
+		return CommandResult{OK: false, Error: "no pools"}
+	}
+	for len(pools) < 3 {
+		pools = append(pools, Pool{})
+	}
+	pools = pools[:3]
+
+	l, err := LoginLuCIAny(ctx, host, creds, schemes)
+	if err != nil {
+		return CommandResult{OK: false, Error: err.Error()}
+	}
+	out := CommandResult{Scheme: l.Scheme, UsedCred: l.UsedCred, Error: "cgminer config page not found"}
+
+	for _, page := range []string{"admin/network/cgminer", "admin/network/btminer"} {
+		code, body, err := l.Get(ctx, page)
+		if err != nil || code != 200 || !strings.Contains(body, "cbid.pools.default") {
+			continue
+		}
+		form := url.Values{}
+		if m := luciTokenRe.FindStringSubmatch(body); len(m) == 2 {
+			form.Set("token", m[1])
+		}
+		form.Set("cbi.submit", "1")
+		form.Set("cbi.apply", "Save & Apply")
+		for i, p := range pools {
+			n := fmt.Sprintf("%d", i+1)
+			form.Set("cbid.pools.default.pool"+n+"url", p.URL)
+			form.Set("cbid.pools.default.pool"+n+"user", p.User)
+			form.Set("cbid.pools.default.pool"+n+"pw", p.Pass)
+		}
+		code, body, err = l.PostForm(ctx, page, form)
+		if err != nil {
+			if sessionutil.IsDropAfterSend(err) {
+				out.OK = true
+				out.Error = ""
+				return out
+			}
+			out.Error = err.Error()
+			return out
+		}
+		out.Body = sessionutil.TruncBody([]byte(body))
+		if code >= 200 && code <= 399 {
+			out.OK = true
+			out.Error = ""
+			return out
+		}
+		out.Error = "http " + http.StatusText(code)
+		return out
+	}
+	return out
+}